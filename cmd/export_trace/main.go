@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sig_graph_scp/pkg/model"
+	exporter_server "sig_graph_scp/pkg/server/exporter"
+	model_server "sig_graph_scp/pkg/server/model"
+	"sig_graph_scp/pkg/utility"
+)
+
+// trace as returned by GET /assets/trace
+type traceResponse struct {
+	RootId model_server.NodeId                     `json:"root_id"`
+	Nodes  map[model_server.NodeId]json.RawMessage `json:"nodes"`
+	Depths map[model_server.NodeId]int             `json:"depths"`
+	Edges  []model_server.TraceEdge                `json:"edges"`
+}
+
+func main() {
+	format := flag.String("format", "epcis", "output format: epcis, prov or dot")
+	inputPath := flag.String("in", "", "trace json of GET /assets/trace, stdin if empty")
+	outputPath := flag.String("out", "", "output file, stdout if empty")
+	flag.Parse()
+
+	exporters := map[string]exporter_server.TraceExporterI{}
+	for _, exporter := range []exporter_server.TraceExporterI{
+		exporter_server.NewTraceExporterEpcis(utility.NewClockWall()),
+		exporter_server.NewTraceExporterProv(),
+		exporter_server.NewTraceExporterDot(),
+	} {
+		exporters[exporter.Format()] = exporter
+	}
+	exporter, ok := exporters[*format]
+	if !ok {
+		panic(fmt.Sprintf("unsupported format %s", *format))
+	}
+
+	input := io.Reader(os.Stdin)
+	if *inputPath != "" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	response := traceResponse{}
+	err := json.NewDecoder(input).Decode(&response)
+	if err != nil {
+		panic(fmt.Sprintf("could not decode trace: %s", err))
+	}
+
+	trace := model_server.Trace{
+		RootId: response.RootId,
+		Nodes:  map[model_server.NodeId]model_server.Node{},
+		Depths: response.Depths,
+		Edges:  response.Edges,
+	}
+	for id, rawNode := range response.Nodes {
+		asset := model_server.Asset{}
+		err := json.Unmarshal(rawNode, &asset)
+		if err != nil {
+			panic(fmt.Sprintf("could not decode node %s: %s", id, err))
+		}
+
+		if asset.NodeType == model.ENodeTypeAsset {
+			assetCopy := asset
+			asset.Node.Extra = &assetCopy
+		}
+		trace.Nodes[id] = asset.Node
+	}
+
+	document, err := exporter.Export(context.Background(), &trace)
+	if err != nil {
+		panic(err)
+	}
+
+	if *outputPath == "" {
+		_, err = os.Stdout.Write(document)
+	} else {
+		err = os.WriteFile(*outputPath, document, 0644)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
@@ -1,27 +1,60 @@
 package main
 
 import (
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"flag"
+	"fmt"
 	"os"
 )
 
 func main() {
-	curve := elliptic.P521()
-	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	algorithm := flag.String("algorithm", "ecdsa", "key algorithm: ecdsa, ed25519 or rsa")
+	curveName := flag.String("curve", "P-521", "ecdsa curve: P-256, P-384 or P-521")
+	rsaBits := flag.Int("bits", 4096, "rsa key size in bits")
+	publicKeyPath := flag.String("public", "pub.pem", "output file of the public key")
+	privateKeyPath := flag.String("private", "key.pem", "output file of the private key")
+	flag.Parse()
+
+	var privateKey crypto.Signer
+	var err error
+	switch *algorithm {
+	case "ecdsa":
+		var curve elliptic.Curve
+		switch *curveName {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			panic(fmt.Sprintf("unsupported curve %s", *curveName))
+		}
+		privateKey, err = ecdsa.GenerateKey(curve, rand.Reader)
+	case "ed25519":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	case "rsa":
+		privateKey, err = rsa.GenerateKey(rand.Reader, *rsaBits)
+	default:
+		panic(fmt.Sprintf("unsupported algorithm %s", *algorithm))
+	}
 	if err != nil {
 		panic(err)
 	}
 
 	{
-		file, err := os.Create("pub.pem")
+		file, err := os.Create(*publicKeyPath)
 		if err != nil {
 			panic(err)
 		}
-		data, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		data, err := x509.MarshalPKIXPublicKey(privateKey.Public())
 		if err != nil {
 			panic(err)
 		}
@@ -36,11 +69,11 @@ func main() {
 	}
 
 	{
-		file, err := os.Create("key.pem")
+		file, err := os.Create(*privateKeyPath)
 		if err != nil {
 			panic(err)
 		}
-		data, err := x509.MarshalPKCS8PrivateKey(key)
+		data, err := x509.MarshalPKCS8PrivateKey(privateKey)
 		if err != nil {
 			panic(err)
 		}
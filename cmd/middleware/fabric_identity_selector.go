@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"sig_graph_scp/cmd/utility"
+	controller_server "sig_graph_scp/pkg/server/controller"
+	model_server "sig_graph_scp/pkg/server/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// names the identity of the user signing the chaincode calls of the request
+const fabricIdentityHeader = "X-Fabric-Identity"
+
+type fabricIdentitySelector struct {
+	controller controller_server.FabricIdentityControllerI
+}
+
+func NewFabricIdentitySelector(
+	controller controller_server.FabricIdentityControllerI,
+) *fabricIdentitySelector {
+	return &fabricIdentitySelector{
+		controller: controller,
+	}
+}
+
+// must run after Authenticate. Chaincode calls of the request are signed by
+// the identity named by the X-Fabric-Identity header, or by the oldest
+// identity of the user, or by the identity of the settings if it has none
+func (s *fabricIdentitySelector) Select(c *gin.Context) {
+	user := GetUser(c.Request.Context())
+	if user == nil {
+		c.Next()
+		return
+	}
+
+	fabricIdentity, err := s.controller.SelectFabricIdentity(c.Request.Context(), user, c.GetHeader(fabricIdentityHeader))
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	if fabricIdentity != nil {
+		sigGraphIdentity := model_server.ToSigGraphFabricIdentity(fabricIdentity)
+		ctx := api_sig_graph.WithFabricIdentity(c.Request.Context(), &sigGraphIdentity)
+		c.Request = c.Request.WithContext(ctx)
+	}
+	c.Next()
+}
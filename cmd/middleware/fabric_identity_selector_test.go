@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	controller_server "sig_graph_scp/pkg/server/controller"
+	model_server "sig_graph_scp/pkg/server/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// identities of the users, oldest first
+type fabricIdentitySelectorTestController struct {
+	controller_server.FabricIdentityControllerI
+	identities map[model_server.UserId][]model_server.FabricIdentity
+	calls      int
+}
+
+func (c *fabricIdentitySelectorTestController) SelectFabricIdentity(
+	ctx context.Context,
+	user *model_server.User,
+	name string,
+) (*model_server.FabricIdentity, error) {
+	c.calls++
+	identities := c.identities[user.ID]
+	if name == "" {
+		if len(identities) == 0 {
+			return nil, nil
+		}
+		return &identities[0], nil
+	}
+
+	for i := range identities {
+		if identities[i].Name == name {
+			return &identities[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: fabric identity %s", utility.ErrNotFound, name)
+}
+
+func newFabricIdentitySelectorTestIdentity(userId model_server.UserId, name string) model_server.FabricIdentity {
+	return model_server.FabricIdentity{
+		UserId:      userId,
+		Name:        name,
+		MspId:       "Org1MSP",
+		Certificate: "certificate of " + name,
+		PrivateKey:  "key of " + name,
+	}
+}
+
+// status of the request and the identity its handler was called with
+func serveFabricIdentitySelectorTest(
+	t *testing.T,
+	controller controller_server.FabricIdentityControllerI,
+	user *model_server.User,
+	header string,
+) (int, *model_sig_graph.FabricIdentity, bool) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	isHandled := false
+	var fabricIdentity *model_sig_graph.FabricIdentity
+	router.GET(
+		"/",
+		func(c *gin.Context) {
+			if user != nil {
+				c.Request = c.Request.WithContext(setUser(c.Request.Context(), *user))
+			}
+			c.Next()
+		},
+		NewFabricIdentitySelector(controller).Select,
+		func(c *gin.Context) {
+			isHandled = true
+			fabricIdentity = api_sig_graph.FabricIdentityFromContext(c.Request.Context())
+			c.Status(http.StatusOK)
+		},
+	)
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		request.Header.Set(fabricIdentityHeader, header)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder.Code, fabricIdentity, isHandled
+}
+
+func TestFabricIdentitySelector(t *testing.T) {
+	userWithIdentities := &model_server.User{ID: 1}
+	userWithoutIdentity := &model_server.User{ID: 2}
+	oldest := newFabricIdentitySelectorTestIdentity(userWithIdentities.ID, "oldest")
+	newest := newFabricIdentitySelectorTestIdentity(userWithIdentities.ID, "newest")
+
+	cases := []struct {
+		name     string
+		user     *model_server.User
+		header   string
+		status   int
+		expected *model_server.FabricIdentity
+	}{
+		{"no user", nil, "newest", http.StatusOK, nil},
+		{"user without identity", userWithoutIdentity, "", http.StatusOK, nil},
+		{"oldest identity by default", userWithIdentities, "", http.StatusOK, &oldest},
+		{"identity of the header", userWithIdentities, "newest", http.StatusOK, &newest},
+		{"unknown identity", userWithIdentities, "unknown", http.StatusNotFound, nil},
+		{"identity of another user", userWithoutIdentity, "oldest", http.StatusNotFound, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			controller := &fabricIdentitySelectorTestController{
+				identities: map[model_server.UserId][]model_server.FabricIdentity{
+					userWithIdentities.ID: {oldest, newest},
+				},
+			}
+
+			status, fabricIdentity, isHandled := serveFabricIdentitySelectorTest(t, controller, c.user, c.header)
+			if status != c.status {
+				t.Fatalf("expected status %d, got %d", c.status, status)
+			}
+			if isHandled != (c.status == http.StatusOK) {
+				t.Fatalf("expected the request to be handled: %t", c.status == http.StatusOK)
+			}
+			if c.user == nil && controller.calls != 0 {
+				t.Fatal("expected no identity lookup without user")
+			}
+
+			if c.expected == nil {
+				if fabricIdentity != nil {
+					t.Fatalf("expected the identity of the settings, got %s", fabricIdentity.Certificate)
+				}
+				return
+			}
+			if fabricIdentity == nil || *fabricIdentity != model_server.ToSigGraphFabricIdentity(c.expected) {
+				t.Fatalf("expected the identity %s, got %v", c.expected.Name, fabricIdentity)
+			}
+		})
+	}
+}
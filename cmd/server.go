@@ -2,18 +2,27 @@ package main
 
 import (
 	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"sig_graph_scp/cmd/middleware"
 	"sig_graph_scp/cmd/view"
 	api_asset_transfer "sig_graph_scp/pkg/asset_transfer/api"
-	"sig_graph_scp/pkg/model"
 	controller_server "sig_graph_scp/pkg/server/controller"
+	exporter_server "sig_graph_scp/pkg/server/exporter"
 	repository_server "sig_graph_scp/pkg/server/repository"
 	service_server "sig_graph_scp/pkg/server/service"
 	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
 	"sig_graph_scp/pkg/utility"
+	"strings"
+	"syscall"
+	"time"
 
 	EventBus "github.com/asaskevich/eventbus"
 	"github.com/gin-gonic/gin"
@@ -24,47 +33,110 @@ import (
 func main() {
 	// gin.SetMode(gin.ReleaseMode)
 
+	// done on SIGINT or SIGTERM, the background work of the server stops
+	serverCtx, stopServer := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopServer()
+
 	// event bus
 	eventBus := EventBus.New()
 
 	// sig graph api
-	sigGraphApi, err := api_sig_graph.NewAssetClientApi("sgp://hyper:[http://localhost:7051,http://localhost:9051]:public")
+	// SIG_GRAPH_LEDGER=memory runs against an in-process ledger, no Fabric network needed
+	graphName := "sgp://hyper:[http://localhost:7051,http://localhost:9051]:public"
+	// SIG_GRAPH_URIS lists space separated graph uris, whose peers and channels
+	// replace the ones of the settings. New assets are created on the first one
+	graphNames := strings.Fields(os.Getenv("SIG_GRAPH_URIS"))
+	isMemoryLedger := os.Getenv("SIG_GRAPH_LEDGER") == "memory"
+
+	// registries are shared by the graphs
+	sigGraphOptions := &api_sig_graph.Options{
+		VerifyNodeSignatures: true,
+		UnitRegistry:         api_sig_graph.NewUnitRegistry(),
+		// SIG_GRAPH_ALLOW_UNKNOWN_UNITS=true accepts assets whose unit is not in the unit registry
+		AllowUnknownUnits: os.Getenv("SIG_GRAPH_ALLOW_UNKNOWN_UNITS") == "true",
+		NodeTypes:         api_sig_graph.NewNodeTypeRegistry(),
+		NodeCache:         &api_sig_graph.NodeCacheOptions{},
+		Retry:             &api_sig_graph.RetryOptions{},
+		// SIG_GRAPH_ID_FORMAT is uuid4 (default), uuid7 or ulid
+		IdFormat: os.Getenv("SIG_GRAPH_ID_FORMAT"),
+	}
+	if !isMemoryLedger {
+		// SIG_GRAPH_CONFIG is a yaml or json settings file, otherwise settings are read from env vars
+		var settings api_sig_graph.SettingsI
+		var err error
+		if configPath := os.Getenv("SIG_GRAPH_CONFIG"); configPath != "" {
+			settings, err = api_sig_graph.NewSettingsFromFile(configPath)
+		} else {
+			settings, err = api_sig_graph.NewSettingsFromEnv()
+		}
+		if err != nil {
+			panic(fmt.Sprintf("could not load sig graph settings: %s", err))
+		}
+		sigGraphOptions.Settings = settings
+	}
+
+	graphs := []api_sig_graph.SigGraphClientApi{}
+	if isMemoryLedger {
+		if len(graphNames) == 0 {
+			graphNames = []string{graphName}
+		}
+		for _, graphName := range graphNames {
+			graph, err := api_sig_graph.NewAssetClientApiMemory(graphName, sigGraphOptions)
+			if err != nil {
+				panic(fmt.Sprintf("could not create asset client api: %s", err))
+			}
+			graphs = append(graphs, graph)
+		}
+	} else if len(graphNames) == 0 {
+		graph, err := api_sig_graph.NewAssetClientApi(graphName, sigGraphOptions)
+		if err != nil {
+			panic(fmt.Sprintf("could not create asset client api: %s", err))
+		}
+		graphs = append(graphs, graph)
+	} else {
+		for _, graphName := range graphNames {
+			graph, err := api_sig_graph.NewSigGraphClientApiFromUri(graphName, sigGraphOptions)
+			if err != nil {
+				panic(fmt.Sprintf("could not create asset client api for %s: %s", graphName, err))
+			}
+			graphs = append(graphs, graph)
+		}
+	}
+	sigGraphApi, err := api_sig_graph.NewMultiGraphClientApi(graphs)
 	if err != nil {
 		panic(fmt.Sprintf("could not create asset client api: %s", err))
 	}
+	// ids received from peers and users must be on our graphs
+	nodeIdValidator := api_sig_graph.NewNodeIdValidatorOfClient(sigGraphApi)
+
+	// how far the time of transfer requests may be from our clock
+	maxClockSkew := durationFromEnv("ASSET_TRANSFER_MAX_CLOCK_SKEW", api_asset_transfer.DefaultRequestClockSkewLimits.MaxFuture)
+	requestClockSkewLimits := utility.ClockSkewLimits{
+		MaxPast:   durationFromEnv("ASSET_TRANSFER_REQUEST_MAX_AGE", api_asset_transfer.DefaultRequestClockSkewLimits.MaxPast),
+		MaxFuture: maxClockSkew,
+	}
+	acceptClockSkewLimits := utility.ClockSkewLimits{
+		MaxPast:   durationFromEnv("ASSET_TRANSFER_ACCEPT_MAX_AGE", api_asset_transfer.DefaultAcceptClockSkewLimits.MaxPast),
+		MaxFuture: maxClockSkew,
+	}
+
+	// ASSET_TRANSFER_TLS_CA_PATH enables mutual TLS between participants
+	assetTransferTls := grpcTlsOptionsFromEnv()
 
 	// asset transfer api
 	assetTransferApi, err := api_asset_transfer.NewAssetTransferServiceApi(
 		sigGraphApi,
 		&api_asset_transfer.Options{
-			NumberOfCandidates: 6,
+			NumberOfCandidates:    6,
+			AcceptClockSkewLimits: &acceptClockSkewLimits,
+			IdFormat:              sigGraphOptions.IdFormat,
+			Tls:                   assetTransferTls,
 		},
 	)
 	if err != nil {
 		panic(fmt.Sprintf("could not create asset transfer api: %s", err))
 	}
 
-	// asset transfer server api
-	assetTransferServerGrpcAddress := os.Getenv("ASSET_TRANSFER_SERVER_GRPC_ADDRESS")
-	if assetTransferServerGrpcAddress == "" {
-		assetTransferServerGrpcAddress = "localhost:5000"
-	}
-	assetTransferServerApi, err := api_asset_transfer.NewAssetTransferServerApi(
-		assetTransferServerGrpcAddress,
-		api_asset_transfer.AssetTransferServerApiOptions{
-			EventBus: eventBus,
-		},
-	)
-	if err != nil {
-		panic(fmt.Sprintf("could not create asset transfer api: %s", err))
-	}
-	go func() {
-		err := assetTransferServerApi.Start()
-		if err != nil {
-			panic(fmt.Sprintf("could not start asset transfer grpc server: %s", err.Error()))
-		}
-	}()
-
 	router := gin.Default()
 
 	// api
@@ -99,7 +171,7 @@ func main() {
 	migrator := repository_server.NewMigratorGorm(&versionRepository, transactionManager)
 	{
 		ctx := context.Background()
-		err := migrator.Up(ctx, 3)
+		err := migrator.Up(ctx, 6)
 		if err != nil {
 			panic(fmt.Sprintf("could not migrate database: %s", err))
 		}
@@ -112,19 +184,32 @@ func main() {
 	peerRepository := repository_server.NewPeerRepositoryGorm(transactionManager)
 	assetTransferRepository := repository_server.NewAssetTransferRepositoryGorm(*transactionManager)
 	userRepository := repository_server.NewUserRepositoryGorm(transactionManager)
+	ledgerTransactionRepository := repository_server.NewLedgerTransactionRepositoryGorm(transactionManager)
+	fabricIdentityRepository := repository_server.NewFabricIdentityRepositoryGorm(transactionManager)
+
+	// node types cached by the server, also decoded by the sig graph api
+	nodeTypes := service_server.NewNodeTypeRegistry(sigGraphApi.GetNodeTypeRegistry())
+	err = nodeTypes.Register(service_server.NewAssetNodeType(assetRepository))
+	if err != nil {
+		panic(fmt.Sprintf("could not register asset node type: %s", err))
+	}
 
 	// service
 	nodeService := service_server.NewNodeService(
 		nodeRepository,
-		map[model.ENodeType]repository_server.GenericNodeRepositoryI{
-			model.ENodeTypeAsset: assetRepository,
-		},
+		nodeTypes,
+		userKeyPairRepository,
 		sigGraphApi,
 	)
 
 	// controller
 	nodeController := controller_server.NewNodeController(nodeService, transactionManager)
-	assetController := controller_server.NewAssetController(sigGraphApi, assetRepository, userKeyPairRepository, transactionManager, hashedIdGenerator)
+	ledgerTransactionController := controller_server.NewLedgerTransactionController(serverCtx, clock, ledgerTransactionRepository, transactionManager, sigGraphApi)
+	err = ledgerTransactionController.ResumeLedgerTransactions(serverCtx)
+	if err != nil {
+		panic(fmt.Sprintf("could not resume ledger transactions: %s", err))
+	}
+	assetController := controller_server.NewAssetController(sigGraphApi, assetRepository, userKeyPairRepository, transactionManager, hashedIdGenerator, utility.NewSecretIdGeneratorCrypto(20), ledgerTransactionController)
 	userKeyPairController := controller_server.NewUserKeyPairController(userKeyPairRepository, transactionManager)
 	peerController := controller_server.NewPeerController(transactionManager, peerRepository)
 	assetTransferController := controller_server.NewAssetTransferController(
@@ -139,14 +224,65 @@ func main() {
 		peerRepository,
 		assetController,
 		assetTransferRepository,
+		ledgerTransactionController,
 	)
+	// FABRIC_CA_URL enables enrolling the Fabric identities of users with the CA
+	var fabricCaEnroller api_sig_graph.FabricCaEnrollerI
+	if caUrl := os.Getenv("FABRIC_CA_URL"); caUrl != "" {
+		var caTlsCertificate *x509.Certificate
+		if caTlsCertificatePath := os.Getenv("FABRIC_CA_TLS_CERTIFICATE_PATH"); caTlsCertificatePath != "" {
+			certificatePem, err := os.ReadFile(caTlsCertificatePath)
+			if err != nil {
+				panic(fmt.Sprintf("could not read fabric ca tls certificate: %s", err))
+			}
+			block, _ := pem.Decode(certificatePem)
+			if block == nil {
+				panic("could not decode fabric ca tls certificate")
+			}
+			caTlsCertificate, err = x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				panic(fmt.Sprintf("could not parse fabric ca tls certificate: %s", err))
+			}
+		}
+		fabricCaEnroller = api_sig_graph.NewFabricCaEnroller(caUrl, os.Getenv("FABRIC_CA_NAME"), os.Getenv("FABRIC_CA_MSP_ID"), caTlsCertificate)
+	}
+	fabricIdentityController := controller_server.NewFabricIdentityController(clock, fabricIdentityRepository, transactionManager, fabricCaEnroller)
 	userController := controller_server.NewUserController(
 		userRepository,
 		transactionManager,
 	)
 
+	// asset transfer server api
+	assetTransferServerGrpcAddress := os.Getenv("ASSET_TRANSFER_SERVER_GRPC_ADDRESS")
+	if assetTransferServerGrpcAddress == "" {
+		assetTransferServerGrpcAddress = "localhost:5000"
+	}
+	assetTransferServerApi, err := api_asset_transfer.NewAssetTransferServerApi(
+		assetTransferServerGrpcAddress,
+		api_asset_transfer.AssetTransferServerApiOptions{
+			EventBus:        eventBus,
+			ClockSkewLimits: &requestClockSkewLimits,
+			NodeIdValidator: nodeIdValidator,
+			Tls:             assetTransferTls,
+			// also drops exposed secret ids of unknown nodes
+			SigGraphApiClient:      sigGraphApi,
+			RequireSenderSignature: true,
+			// accepts of our requests come from the peers they were sent to
+			OutboundRequestPeers: assetTransferController,
+		},
+	)
+	if err != nil {
+		panic(fmt.Sprintf("could not create asset transfer api: %s", err))
+	}
+	go func() {
+		err := assetTransferServerApi.Start()
+		if err != nil {
+			panic(fmt.Sprintf("could not start asset transfer grpc server: %s", err.Error()))
+		}
+	}()
+
 	{
-		ctx := context.Background()
+		ctx := serverCtx
 		assetTransferController.SubscribeNewAcceptAssetRequestReceivedEvent(
 			ctx,
 			eventBus,
@@ -157,6 +293,11 @@ func main() {
 			eventBus,
 			assetTransferServerApi.GetDefaultNewReceivedAssetAcceptTopic(),
 		)
+
+		err := nodeController.SubscribeNodeEvents(ctx, sigGraphApi)
+		if err != nil {
+			panic(fmt.Sprintf("could not subscribe to sig graph node events: %s", err))
+		}
 	}
 
 	// middleware
@@ -165,13 +306,21 @@ func main() {
 		"api.dev.com",
 	)
 	cors := middleware.CORSMiddleware()
+	fabricIdentity := middleware.NewFabricIdentitySelector(fabricIdentityController)
 
 	// view
-	assetView := view.NewAssetView(assetController)
+	assetView := view.NewAssetView(assetController, nodeIdValidator)
 	userKeyPairView := view.NewUserKeyPairView(userKeyPairController)
 	peerView := view.NewPeerView(peerController)
 	assetTransferView := view.NewAssetTransferView(assetTransferController)
 	userView := view.NewUserView(userController, auth, auth)
+	ledgerTransactionView := view.NewLedgerTransactionView(ledgerTransactionController)
+	fabricIdentityView := view.NewFabricIdentityView(fabricIdentityController)
+	nodeView := view.NewNodeView(nodeController, []exporter_server.TraceExporterI{
+		exporter_server.NewTraceExporterEpcis(clock),
+		exporter_server.NewTraceExporterProv(),
+		exporter_server.NewTraceExporterDot(),
+	}, nodeIdValidator)
 
 	// api
 	router.Use(cors)
@@ -183,10 +332,14 @@ func main() {
 		api.DELETE("/logins", userView.LogOut)
 
 		// asset
-		api.GET("/assets", auth.Authenticate, assetView.GetAssetById)
-		api.POST("/assets", auth.Authenticate, assetView.CreateAsset)
-		api.GET("/assets/db_ids", auth.Authenticate, assetView.GetAssetByDbId)
-		api.GET("/assets/cache/owned", auth.Authenticate, assetView.GetOwnedAssetsFromCache)
+		api.GET("/assets", auth.Authenticate, fabricIdentity.Select, assetView.GetAssetById)
+		api.POST("/assets", auth.Authenticate, fabricIdentity.Select, assetView.CreateAsset)
+		api.POST("/assets/split", auth.Authenticate, fabricIdentity.Select, assetView.SplitAsset)
+		api.GET("/assets/db_ids", auth.Authenticate, fabricIdentity.Select, assetView.GetAssetByDbId)
+		api.GET("/assets/cache/owned", auth.Authenticate, fabricIdentity.Select, assetView.GetOwnedAssetsFromCache)
+		api.GET("/assets/trace", auth.Authenticate, fabricIdentity.Select, nodeView.TraceNode)
+		api.GET("/assets/trace/:format", auth.Authenticate, fabricIdentity.Select, nodeView.ExportTrace)
+		api.GET("/assets/proof_bundle", auth.Authenticate, fabricIdentity.Select, nodeView.GetProofBundle)
 
 		// user key pair
 		api.GET("/key_pairs", auth.Authenticate, userKeyPairView.GetUserKeyPairsByUser)
@@ -197,12 +350,20 @@ func main() {
 		api.POST("/peers", auth.Authenticate, peerView.CreatePeer)
 
 		// asset transfer
-		api.POST("/asset_accept_requests", auth.Authenticate, assetTransferView.CreateRequestToAcceptAsset)
-		api.GET("/asset_accept_requests", auth.Authenticate, assetTransferView.GetReceivedRequestToAcceptAsset)
-		api.GET("/asset_accept_requests/private_edges", auth.Authenticate, assetTransferView.GetPrivateEdges)
+		api.POST("/asset_accept_requests", auth.Authenticate, fabricIdentity.Select, assetTransferView.CreateRequestToAcceptAsset)
+		api.GET("/asset_accept_requests", auth.Authenticate, fabricIdentity.Select, assetTransferView.GetReceivedRequestToAcceptAsset)
+		api.GET("/asset_accept_requests/private_edges", auth.Authenticate, fabricIdentity.Select, assetTransferView.GetPrivateEdges)
 
 		// accept asset transfer
-		api.POST("/asset_accept_requests/acceptance", auth.Authenticate, assetTransferView.AcceptReceivedRequestToAcceptAsset)
+		api.POST("/asset_accept_requests/acceptance", auth.Authenticate, fabricIdentity.Select, assetTransferView.AcceptReceivedRequestToAcceptAsset)
+
+		// ledger transactions
+		api.GET("/ledger_transactions", auth.Authenticate, ledgerTransactionView.GetLedgerTransactions)
+
+		// fabric identities
+		api.GET("/fabric_identities", auth.Authenticate, fabricIdentityView.GetFabricIdentities)
+		api.POST("/fabric_identities", auth.Authenticate, fabricIdentityView.AddFabricIdentity)
+		api.POST("/fabric_identities/enrollment", auth.Authenticate, fabricIdentityView.EnrollFabricIdentity)
 	}
 
 	router.NoRoute(func(ctx *gin.Context) { ctx.JSON(http.StatusNotFound, gin.H{}) })
@@ -213,8 +374,107 @@ func main() {
 	}
 
 	fmt.Println("Starting host at ", serverAddress)
-	err = router.Run(serverAddress)
+	httpServer := &http.Server{
+		Addr:    serverAddress,
+		Handler: router,
+	}
+	go func() {
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			panic(fmt.Sprintf("could not start server: %s", err))
+		}
+	}()
+
+	<-serverCtx.Done()
+	fmt.Println("Stopping host at ", serverAddress)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		panic(fmt.Sprintf("could not stop server: %s", err))
+	}
+}
+
+// duration like "5m" from the env var, or fallback if it is unset
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
 	if err != nil {
-		panic(fmt.Sprintf("could not start server: %s", err))
+		panic(fmt.Sprintf("invalid %s env var: %s", name, err))
+	}
+	return duration
+}
+
+// nil without ASSET_TRANSFER_TLS_CA_PATH. The server certificate is read from
+// ASSET_TRANSFER_TLS_CERTIFICATE_PATH and ASSET_TRANSFER_TLS_KEY_PATH, the
+// certificates of the users from the space separated <certificate>:<key> paths
+// of ASSET_TRANSFER_TLS_CLIENT_CERTIFICATES, default to the server certificate
+func grpcTlsOptionsFromEnv() *utility.GrpcTlsOptions {
+	caPath := os.Getenv("ASSET_TRANSFER_TLS_CA_PATH")
+	if caPath == "" {
+		return nil
+	}
+
+	caPem, err := os.ReadFile(caPath)
+	if err != nil {
+		panic(fmt.Sprintf("could not read asset transfer TLS CA: %s", err))
+	}
+	caCertificates := x509.NewCertPool()
+	if !caCertificates.AppendCertsFromPEM(caPem) {
+		panic("asset transfer TLS CA has no pem certificate")
+	}
+
+	serverCertificate, err := tls.LoadX509KeyPair(os.Getenv("ASSET_TRANSFER_TLS_CERTIFICATE_PATH"), os.Getenv("ASSET_TRANSFER_TLS_KEY_PATH"))
+	if err != nil {
+		panic(fmt.Sprintf("could not load asset transfer TLS certificate: %s", err))
+	}
+
+	clientCertificates := []tls.Certificate{}
+	for _, paths := range strings.Fields(os.Getenv("ASSET_TRANSFER_TLS_CLIENT_CERTIFICATES")) {
+		certificatePath, keyPath, ok := strings.Cut(paths, ":")
+		if !ok {
+			panic(fmt.Sprintf("asset transfer TLS client certificate %s is not <certificate>:<key>", paths))
+		}
+		clientCertificate, err := tls.LoadX509KeyPair(certificatePath, keyPath)
+		if err != nil {
+			panic(fmt.Sprintf("could not load asset transfer TLS client certificate %s: %s", certificatePath, err))
+		}
+		clientCertificates = append(clientCertificates, clientCertificate)
+	}
+
+	// users created at runtime get a certificate of this CA, peers must trust it
+	var clientCertificateIssuer *utility.GrpcClientCertificateIssuer
+	if issuerCertificatePath := os.Getenv("ASSET_TRANSFER_TLS_ISSUER_CERTIFICATE_PATH"); issuerCertificatePath != "" {
+		issuer, err := tls.LoadX509KeyPair(issuerCertificatePath, os.Getenv("ASSET_TRANSFER_TLS_ISSUER_KEY_PATH"))
+		if err != nil {
+			panic(fmt.Sprintf("could not load asset transfer TLS issuer: %s", err))
+		}
+		issuerCertificate, err := x509.ParseCertificate(issuer.Certificate[0])
+		if err != nil {
+			panic(fmt.Sprintf("could not parse asset transfer TLS issuer: %s", err))
+		}
+		issuerKey, ok := issuer.PrivateKey.(crypto.Signer)
+		if !ok {
+			panic("asset transfer TLS issuer key can not sign")
+		}
+		clientCertificateIssuer = &utility.GrpcClientCertificateIssuer{
+			Certificate: issuerCertificate,
+			Key:         issuerKey,
+			Clock:       utility.NewClockWall(),
+		}
+	}
+
+	if len(clientCertificates) == 0 && clientCertificateIssuer == nil {
+		clientCertificates = append(clientCertificates, serverCertificate)
+	}
+
+	return &utility.GrpcTlsOptions{
+		CaCertificates:          caCertificates,
+		ServerCertificate:       serverCertificate,
+		ClientCertificates:      clientCertificates,
+		ClientCertificateIssuer: clientCertificateIssuer,
 	}
 }
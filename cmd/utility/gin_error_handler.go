@@ -10,10 +10,20 @@ import (
 
 func AbortWithError(c *gin.Context, err error) {
 	status := http.StatusInternalServerError
-	if errors.Is(err, utility.ErrNotFound) {
+	switch {
+	case errors.Is(err, utility.ErrNotFound):
 		status = http.StatusNotFound
-	} else if errors.Is(err, utility.ErrInvalidArgument) {
+	case errors.Is(err, utility.ErrInvalidArgument):
 		status = http.StatusBadRequest
+	case errors.Is(err, utility.ErrInvalidSignature):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, utility.ErrPermissionDenied):
+		status = http.StatusForbidden
+	case errors.Is(err, utility.ErrAlreadyExists), errors.Is(err, utility.ErrInvalidState):
+		// ErrInvalidState includes ErrAlreadyFinalized
+		status = http.StatusConflict
+	case errors.Is(err, utility.ErrTransient), errors.Is(err, utility.ErrTimedOut):
+		status = http.StatusServiceUnavailable
 	}
 
 	c.AbortWithStatusJSON(
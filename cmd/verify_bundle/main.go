@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+)
+
+// verify a proof bundle of GET /assets/proof_bundle without network access,
+// exit with 1 if it is not valid
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("verify_bundle", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	inputPath := flags.String("in", "", "proof bundle json, stdin if empty")
+	trustedPath := flags.String("trusted", "", "pem public keys of the trusted owners, one block after the other")
+	err := flags.Parse(args)
+	if err != nil {
+		return 1
+	}
+
+	if *trustedPath == "" {
+		fmt.Fprintln(stderr, "-trusted is required, the public keys of the bundle are not trusted")
+		return 1
+	}
+	trustedPublicKeys, err := readPemPublicKeys(*trustedPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "could not read trusted public keys: %s\n", err)
+		return 1
+	}
+
+	input := stdin
+	if *inputPath != "" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "could not open proof bundle: %s\n", err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	bundle := model_sig_graph.ProofBundle{}
+	err = json.NewDecoder(input).Decode(&bundle)
+	if err != nil {
+		fmt.Fprintf(stderr, "could not decode proof bundle: %s\n", err)
+		return 1
+	}
+
+	report, err := api_sig_graph.NewProofBundleVerifier().Verify(context.Background(), &bundle, trustedPublicKeys)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid proof bundle: %s\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "valid proof bundle of %s on %s\n", report.RootId, bundle.GraphName)
+	fmt.Fprintf(stdout, "%d signed nodes, %d trusted keys\n", len(report.NodeIds), len(trustedPublicKeys))
+	for _, edge := range report.Edges {
+		visibility := "public"
+		if edge.IsPrivate {
+			visibility = "private"
+		}
+		fmt.Fprintf(stdout, "%s -> %s (%s)\n", edge.ParentId, edge.ChildId, visibility)
+	}
+	return 0
+}
+
+// every "PUBLIC KEY" block of the file
+func readPemPublicKeys(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := []string{}
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		publicKeys = append(publicKeys, string(pem.EncodeToMemory(block)))
+	}
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("no public key in %s", path)
+	}
+	return publicKeys, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	verifyBundleTestParentId = "sgp://memory:[]:public:parent"
+	verifyBundleTestChildId  = "sgp://memory:[]:public:child"
+)
+
+func newVerifyBundleTestKeyPair(t *testing.T) *model_sig_graph.UserKeyPair {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &model_sig_graph.UserKeyPair{
+		Public:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+		Private: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDer})),
+	}
+}
+
+func newVerifyBundleTestNode(t *testing.T, id string, owner *model_sig_graph.UserKeyPair, edge func(node *model_sig_graph.Node)) json.RawMessage {
+	t.Helper()
+
+	signingService := service_sig_graph.NewNodeSigningService()
+	asset := model_sig_graph.NewAsset(
+		model_sig_graph.NewDefaultNode(id, model.ENodeTypeAsset, 1, 1, "", owner.Public),
+		model.ECreationProcessCreate,
+		"kg",
+		decimal.NewFromInt(10),
+		"flour",
+	)
+	asset.SignatureScheme = signingService.Scheme()
+	edge(&asset.Node)
+
+	signature, err := signingService.Sign(context.Background(), owner, asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asset.Signature = signature
+
+	assetJson, err := json.Marshal(asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assetJson
+}
+
+// path of a bundle with a public edge from parent to child, signed by owner
+func writeVerifyBundleTestBundle(t *testing.T, owner *model_sig_graph.UserKeyPair) string {
+	t.Helper()
+
+	bundle := model_sig_graph.ProofBundle{
+		Version:   model_sig_graph.ProofBundleVersionV1,
+		GraphName: "memory",
+		RootId:    verifyBundleTestChildId,
+		Nodes: []json.RawMessage{
+			newVerifyBundleTestNode(t, verifyBundleTestParentId, owner, func(node *model_sig_graph.Node) {
+				node.PublicChildrenIds[verifyBundleTestChildId] = true
+			}),
+			newVerifyBundleTestNode(t, verifyBundleTestChildId, owner, func(node *model_sig_graph.Node) {
+				node.PublicParentsIds[verifyBundleTestParentId] = true
+			}),
+		},
+		Secrets:    []model_sig_graph.ProofBundleSecret{},
+		PublicKeys: []string{owner.Public},
+	}
+	bundleJson, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writeVerifyBundleTestFile(t, "bundle.json", string(bundleJson))
+}
+
+func writeVerifyBundleTestFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runVerifyBundleTest(args []string, stdin string) (int, string, string) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	code := run(args, strings.NewReader(stdin), stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestVerifyBundleValid(t *testing.T) {
+	owner := newVerifyBundleTestKeyPair(t)
+	bundlePath := writeVerifyBundleTestBundle(t, owner)
+	// the trusted keys may hold other pem blocks
+	trustedPath := writeVerifyBundleTestFile(t, "trusted.pem", owner.Private+owner.Public)
+
+	code, stdout, stderr := runVerifyBundleTest([]string{"-in", bundlePath, "-trusted", trustedPath}, "")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	expected := "valid proof bundle of " + verifyBundleTestChildId + " on memory\n" +
+		"2 signed nodes, 1 trusted keys\n" +
+		verifyBundleTestParentId + " -> " + verifyBundleTestChildId + " (public)\n"
+	if stdout != expected {
+		t.Fatalf("expected output %q, got %q", expected, stdout)
+	}
+}
+
+func TestVerifyBundleReadsStdin(t *testing.T) {
+	owner := newVerifyBundleTestKeyPair(t)
+	bundleJson, err := os.ReadFile(writeVerifyBundleTestBundle(t, owner))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trustedPath := writeVerifyBundleTestFile(t, "trusted.pem", owner.Public)
+
+	code, _, stderr := runVerifyBundleTest([]string{"-trusted", trustedPath}, string(bundleJson))
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+}
+
+func TestVerifyBundleInvalid(t *testing.T) {
+	owner := newVerifyBundleTestKeyPair(t)
+	other := newVerifyBundleTestKeyPair(t)
+	bundlePath := writeVerifyBundleTestBundle(t, owner)
+	trustedPath := writeVerifyBundleTestFile(t, "trusted.pem", owner.Public)
+
+	cases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		stderr string
+	}{
+		{"no trusted keys", []string{"-in", bundlePath}, "", "-trusted is required"},
+		{"trusted file without public key", []string{"-in", bundlePath, "-trusted", writeVerifyBundleTestFile(t, "private.pem", owner.Private)}, "", "could not read trusted public keys"},
+		{"missing bundle", []string{"-in", filepath.Join(t.TempDir(), "missing.json"), "-trusted", trustedPath}, "", "could not open proof bundle"},
+		{"bundle not json", []string{"-trusted", trustedPath}, "not json", "could not decode proof bundle"},
+		{"owner not trusted", []string{"-in", bundlePath, "-trusted", writeVerifyBundleTestFile(t, "other.pem", other.Public)}, "", "invalid proof bundle"},
+		{"unknown flag", []string{"-unknown"}, "", "flag provided but not defined"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code, stdout, stderr := runVerifyBundleTest(c.args, c.stdin)
+			if code != 1 {
+				t.Fatalf("expected exit code 1, got %d", code)
+			}
+			if stdout != "" {
+				t.Fatalf("expected no output, got %q", stdout)
+			}
+			if !strings.Contains(stderr, c.stderr) {
+				t.Fatalf("expected %q in the errors, got %q", c.stderr, stderr)
+			}
+		})
+	}
+}
@@ -30,7 +30,7 @@ type CreateRequestToAcceptAssetRequest struct {
 	AssetId                        uint64     `json:"asset_id"`
 	PeerId                         uint64     `json:"peer_id"`
 	Edges                          []NodeEdge `json:"edges"`
-	isNewConnectionPrivateOrPublic bool       `json:"is_new_connection_private_or_public"`
+	IsNewConnectionPrivateOrPublic bool       `json:"is_new_connection_private_or_public"`
 }
 
 func (v *assetTransferView) CreateRequestToAcceptAsset(c *gin.Context) {
@@ -57,7 +57,7 @@ func (v *assetTransferView) CreateRequestToAcceptAsset(c *gin.Context) {
 		model_server.NodeDbId(request.AssetId),
 		request.PeerId,
 		exposedSecretIds,
-		request.isNewConnectionPrivateOrPublic,
+		request.IsNewConnectionPrivateOrPublic,
 	)
 
 	if err != nil {
@@ -127,7 +127,7 @@ func (v *assetTransferView) AcceptReceivedRequestToAcceptAsset(c *gin.Context) {
 		return
 	}
 
-	requestToAcceptAsset, err := v.controller.AcceptReceivedRequestsToAcceptAsset(
+	requestToAcceptAsset, ledgerTransaction, err := v.controller.AcceptReceivedRequestsToAcceptAsset(
 		ctx,
 		user,
 		request.KeyPairId,
@@ -141,7 +141,15 @@ func (v *assetTransferView) AcceptReceivedRequestToAcceptAsset(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, requestToAcceptAsset)
+	if ledgerTransaction == nil {
+		c.JSON(http.StatusOK, requestToAcceptAsset)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"request":            requestToAcceptAsset,
+		"ledger_transaction": ledgerTransaction,
+	})
 	return
 }
 
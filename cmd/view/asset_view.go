@@ -7,26 +7,36 @@ import (
 	controller_server "sig_graph_scp/pkg/server/controller"
 	model_server "sig_graph_scp/pkg/server/model"
 	repository_server "sig_graph_scp/pkg/server/repository"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
 
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
 )
 
 type assetView struct {
-	controller controller_server.AssetControllerI
+	controller      controller_server.AssetControllerI
+	nodeIdValidator api_sig_graph.NodeIdValidatorI
 }
 
-func NewAssetView(controller controller_server.AssetControllerI) *assetView {
+func NewAssetView(controller controller_server.AssetControllerI, nodeIdValidator api_sig_graph.NodeIdValidatorI) *assetView {
 	return &assetView{
-		controller: controller,
+		controller:      controller,
+		nodeIdValidator: nodeIdValidator,
 	}
 }
 
+type CreateAssetIngredientRequest struct {
+	DbId model_server.NodeDbId `json:"db_id"`
+	// link the ingredient by generated secrets instead of public edges
+	IsPrivate bool `json:"is_private"`
+}
+
 type CreateAssetRequest struct {
-	MaterialName string          `json:"material_name"`
-	Unit         string          `json:"unit"`
-	Quantity     decimal.Decimal `json:"quantity"`
-	KeyId        uint64          `json:"key_id"`
+	MaterialName string                         `json:"material_name"`
+	Unit         string                         `json:"unit"`
+	Quantity     decimal.Decimal                `json:"quantity"`
+	KeyId        uint64                         `json:"key_id"`
+	Ingredients  []CreateAssetIngredientRequest `json:"ingredients"`
 }
 
 type SerializableNode struct {
@@ -41,17 +51,22 @@ func (v *assetView) CreateAsset(c *gin.Context) {
 		return
 	}
 
-	asset, err := v.controller.CreateAsset(
+	ingredientDbIds := make([]model_server.NodeDbId, 0, len(request.Ingredients))
+	isIngredientPrivate := make([]bool, 0, len(request.Ingredients))
+	for _, ingredient := range request.Ingredients {
+		ingredientDbIds = append(ingredientDbIds, ingredient.DbId)
+		isIngredientPrivate = append(isIngredientPrivate, ingredient.IsPrivate)
+	}
+
+	asset, ingredients, ledgerTransaction, err := v.controller.ManufactureAsset(
 		c.Request.Context(),
 		user,
 		request.MaterialName,
 		request.Unit,
 		request.Quantity,
 		request.KeyId,
-		[]model_server.Asset{},
-		[]string{},
-		[]string{},
-		[]string{},
+		ingredientDbIds,
+		isIngredientPrivate,
 	)
 
 	if err != nil {
@@ -59,7 +74,67 @@ func (v *assetView) CreateAsset(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, asset)
+	c.JSON(http.StatusAccepted, gin.H{
+		"asset":              asset,
+		"ingredients":        ingredients,
+		"ledger_transaction": ledgerTransaction,
+	})
+	return
+}
+
+type SplitAssetChildRequest struct {
+	Quantity decimal.Decimal `json:"quantity"`
+	// hides the child in the asset, public edge if empty
+	SecretId string `json:"secret_id"`
+	// hides the asset in the child, public edge if empty
+	ParentSecretId string `json:"parent_secret_id"`
+}
+
+type SplitAssetRequest struct {
+	AssetId  string                   `json:"asset_id"`
+	Children []SplitAssetChildRequest `json:"children"`
+}
+
+func (v *assetView) SplitAsset(c *gin.Context) {
+	user := middleware.GetUser(c.Request.Context())
+
+	request := SplitAssetRequest{}
+	if err := c.ShouldBind(&request); err != nil {
+		utility.AbortBadRequest(c, err)
+		return
+	}
+	if err := v.nodeIdValidator.ValidateNodeId(c.Request.Context(), request.AssetId); err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	quantities := make([]decimal.Decimal, 0, len(request.Children))
+	secretIds := make([]string, 0, len(request.Children))
+	parentSecretIds := make([]string, 0, len(request.Children))
+	for _, child := range request.Children {
+		quantities = append(quantities, child.Quantity)
+		secretIds = append(secretIds, child.SecretId)
+		parentSecretIds = append(parentSecretIds, child.ParentSecretId)
+	}
+
+	asset, children, ledgerTransaction, err := v.controller.SplitAsset(
+		c.Request.Context(),
+		user,
+		model_server.NodeId(request.AssetId),
+		quantities,
+		secretIds,
+		parentSecretIds,
+	)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"asset":              asset,
+		"children":           children,
+		"ledger_transaction": ledgerTransaction,
+	})
 	return
 }
 
@@ -76,6 +151,10 @@ func (v *assetView) GetAssetById(c *gin.Context) {
 		utility.AbortBadRequest(c, err)
 		return
 	}
+	if err := v.nodeIdValidator.ValidateNodeId(c.Request.Context(), request.AssetId); err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
 
 	asset, err := v.controller.GetAssetById(c.Request.Context(), user, model_server.NodeId(request.AssetId), request.UseCache)
 	if err != nil {
@@ -89,6 +168,8 @@ func (v *assetView) GetAssetById(c *gin.Context) {
 
 type GetAssetByDbIdRequest struct {
 	Ids []uint64 `form:"ids"`
+	// convert quantities to this unit if not empty
+	Unit string `form:"unit"`
 }
 
 func (v *assetView) GetAssetByDbId(c *gin.Context) {
@@ -109,6 +190,7 @@ func (v *assetView) GetAssetByDbId(c *gin.Context) {
 		c.Request.Context(),
 		user,
 		idsMap,
+		request.Unit,
 	)
 	if err != nil {
 		utility.AbortWithError(c, err)
@@ -123,6 +205,8 @@ type GetOwnedAssetsFromCacheRequest struct {
 	IsTransferred []bool                `form:"is_transferred"`
 	MinId         model_server.NodeDbId `form:"min_id"`
 	Limit         int                   `form:"limit"`
+	// convert quantities to this unit if not empty
+	Unit string `form:"unit"`
 }
 
 func (v *assetView) GetOwnedAssetsFromCache(
@@ -146,6 +230,7 @@ func (v *assetView) GetOwnedAssetsFromCache(
 		user,
 		request.IsTransferred,
 		pagination,
+		request.Unit,
 	)
 	if err != nil {
 		utility.AbortWithError(c, err)
@@ -0,0 +1,100 @@
+package view
+
+import (
+	"net/http"
+	"sig_graph_scp/cmd/middleware"
+	"sig_graph_scp/cmd/utility"
+	controller_server "sig_graph_scp/pkg/server/controller"
+	model_server "sig_graph_scp/pkg/server/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fabricIdentityView struct {
+	controller controller_server.FabricIdentityControllerI
+}
+
+func NewFabricIdentityView(
+	controller controller_server.FabricIdentityControllerI,
+) *fabricIdentityView {
+	return &fabricIdentityView{
+		controller: controller,
+	}
+}
+
+func (v *fabricIdentityView) GetFabricIdentities(c *gin.Context) {
+	user := middleware.GetUser(c.Request.Context())
+
+	fabricIdentities, err := v.controller.FetchFabricIdentitiesOfUser(c.Request.Context(), user)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fabricIdentities)
+	return
+}
+
+type AddFabricIdentityRequest struct {
+	Name  string `json:"name"`
+	MspId string `json:"msp_id"`
+	// pem encoded
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"private_key"`
+}
+
+func (v *fabricIdentityView) AddFabricIdentity(c *gin.Context) {
+	user := middleware.GetUser(c.Request.Context())
+
+	request := AddFabricIdentityRequest{}
+	if err := c.ShouldBind(&request); err != nil {
+		utility.AbortBadRequest(c, err)
+		return
+	}
+
+	fabricIdentity := &model_server.FabricIdentity{
+		Name:        request.Name,
+		MspId:       request.MspId,
+		Certificate: request.Certificate,
+		PrivateKey:  request.PrivateKey,
+	}
+	err := v.controller.AddFabricIdentity(c.Request.Context(), user, fabricIdentity)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, fabricIdentity)
+	return
+}
+
+type EnrollFabricIdentityRequest struct {
+	Name             string `json:"name"`
+	EnrollmentId     string `json:"enrollment_id"`
+	EnrollmentSecret string `json:"enrollment_secret"`
+}
+
+func (v *fabricIdentityView) EnrollFabricIdentity(c *gin.Context) {
+	user := middleware.GetUser(c.Request.Context())
+
+	request := EnrollFabricIdentityRequest{}
+	if err := c.ShouldBind(&request); err != nil {
+		utility.AbortBadRequest(c, err)
+		return
+	}
+
+	fabricIdentity, err := v.controller.EnrollFabricIdentity(
+		c.Request.Context(),
+		user,
+		request.Name,
+		request.EnrollmentId,
+		request.EnrollmentSecret,
+	)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, fabricIdentity)
+	return
+}
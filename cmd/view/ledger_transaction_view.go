@@ -0,0 +1,50 @@
+package view
+
+import (
+	"net/http"
+	"sig_graph_scp/cmd/middleware"
+	"sig_graph_scp/cmd/utility"
+	controller_server "sig_graph_scp/pkg/server/controller"
+	model_server "sig_graph_scp/pkg/server/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ledgerTransactionView struct {
+	controller controller_server.LedgerTransactionControllerI
+}
+
+func NewLedgerTransactionView(controller controller_server.LedgerTransactionControllerI) *ledgerTransactionView {
+	return &ledgerTransactionView{
+		controller: controller,
+	}
+}
+
+type GetLedgerTransactionsRequest struct {
+	Ids []model_server.LedgerTransactionDbId `form:"ids"`
+}
+
+func (v *ledgerTransactionView) GetLedgerTransactions(c *gin.Context) {
+	ctx := c.Request.Context()
+	user := middleware.GetUser(c.Request.Context())
+
+	request := GetLedgerTransactionsRequest{}
+	if err := c.ShouldBind(&request); err != nil {
+		utility.AbortBadRequest(c, err)
+		return
+	}
+
+	ids := map[model_server.LedgerTransactionDbId]bool{}
+	for _, id := range request.Ids {
+		ids[id] = true
+	}
+
+	transactions, err := v.controller.GetLedgerTransactionsByIds(ctx, user, ids)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+	return
+}
@@ -0,0 +1,168 @@
+package view
+
+import (
+	"fmt"
+	"net/http"
+	"sig_graph_scp/cmd/middleware"
+	"sig_graph_scp/cmd/utility"
+	controller_server "sig_graph_scp/pkg/server/controller"
+	exporter_server "sig_graph_scp/pkg/server/exporter"
+	model_server "sig_graph_scp/pkg/server/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deeper traces are rejected, each level costs a ledger query
+const maxTraceDepth = 50
+
+type nodeView struct {
+	controller controller_server.NodeControllerI
+	// by format
+	exporters       map[string]exporter_server.TraceExporterI
+	nodeIdValidator api_sig_graph.NodeIdValidatorI
+}
+
+func NewNodeView(
+	controller controller_server.NodeControllerI,
+	exporters []exporter_server.TraceExporterI,
+	nodeIdValidator api_sig_graph.NodeIdValidatorI,
+) *nodeView {
+	exportersByFormat := map[string]exporter_server.TraceExporterI{}
+	for _, exporter := range exporters {
+		exportersByFormat[exporter.Format()] = exporter
+	}
+
+	return &nodeView{
+		controller:      controller,
+		exporters:       exportersByFormat,
+		nodeIdValidator: nodeIdValidator,
+	}
+}
+
+type TraceNodeRequest struct {
+	AssetId   string                       `form:"asset_id"`
+	Direction model_server.ETraceDirection `form:"direction,default=both"`
+	MaxDepth  int                          `form:"max_depth,default=10"`
+	UseCache  bool                         `form:"use_cache"`
+}
+
+func (v *nodeView) TraceNode(c *gin.Context) {
+	trace, ok := v.traceNode(c)
+	if !ok {
+		return
+	}
+
+	// serialize the specific model of the nodes, e.g. assets
+	nodes := map[model_server.NodeId]any{}
+	for id, node := range trace.Nodes {
+		if node.Extra != nil {
+			nodes[id] = node.Extra
+		} else {
+			nodes[id] = node
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"root_id": trace.RootId,
+		"nodes":   nodes,
+		"depths":  trace.Depths,
+		"edges":   trace.Edges,
+	})
+	return
+}
+
+// GET /assets/trace/:format, the trace in a standard format, e.g. epcis
+func (v *nodeView) ExportTrace(c *gin.Context) {
+	exporter, ok := v.exporters[c.Param("format")]
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("unknown trace format %s", c.Param("format"))})
+		return
+	}
+
+	trace, ok := v.traceNode(c)
+	if !ok {
+		return
+	}
+
+	document, err := exporter.Export(c.Request.Context(), trace)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, exporter.ContentType(), document)
+	return
+}
+
+// abort the request and return false on failure
+func (v *nodeView) traceNode(c *gin.Context) (*model_server.Trace, bool) {
+	user := middleware.GetUser(c.Request.Context())
+
+	request := TraceNodeRequest{}
+	if err := c.ShouldBind(&request); err != nil {
+		utility.AbortBadRequest(c, err)
+		return nil, false
+	}
+	if request.MaxDepth > maxTraceDepth {
+		utility.AbortBadRequest(c, fmt.Errorf("max_depth must be at most %d", maxTraceDepth))
+		return nil, false
+	}
+	if err := v.nodeIdValidator.ValidateNodeId(c.Request.Context(), request.AssetId); err != nil {
+		utility.AbortWithError(c, err)
+		return nil, false
+	}
+
+	trace, err := v.controller.TraceNode(
+		c.Request.Context(),
+		user,
+		model_server.NodeId(request.AssetId),
+		request.Direction,
+		request.MaxDepth,
+		request.UseCache,
+	)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return nil, false
+	}
+	return trace, true
+}
+
+type GetProofBundleRequest struct {
+	AssetId   string                       `form:"asset_id"`
+	Direction model_server.ETraceDirection `form:"direction,default=ancestors"`
+	MaxDepth  int                          `form:"max_depth,default=10"`
+}
+
+func (v *nodeView) GetProofBundle(c *gin.Context) {
+	user := middleware.GetUser(c.Request.Context())
+
+	request := GetProofBundleRequest{}
+	if err := c.ShouldBind(&request); err != nil {
+		utility.AbortBadRequest(c, err)
+		return
+	}
+	if request.MaxDepth > maxTraceDepth {
+		utility.AbortBadRequest(c, fmt.Errorf("max_depth must be at most %d", maxTraceDepth))
+		return
+	}
+	if err := v.nodeIdValidator.ValidateNodeId(c.Request.Context(), request.AssetId); err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	bundle, err := v.controller.BuildProofBundle(
+		c.Request.Context(),
+		user,
+		model_server.NodeId(request.AssetId),
+		request.Direction,
+		request.MaxDepth,
+	)
+	if err != nil {
+		utility.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+	return
+}
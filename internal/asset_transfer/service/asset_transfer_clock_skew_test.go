@@ -0,0 +1,288 @@
+package service_asset_transfer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	sig_graph_grpc "sig_graph_scp/internal/grpc"
+	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
+	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	"sig_graph_scp/pkg/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+)
+
+const clockSkewTestGraphName = "sgp://memory:[]:public"
+
+var clockSkewTestNow = time.UnixMilli(1700000000000)
+
+var clockSkewTestLimits = utility.ClockSkewLimits{
+	MaxPast:   5 * time.Minute,
+	MaxFuture: time.Minute,
+}
+
+func newClockSkewTestKeyPair(t *testing.T) *model_sig_graph.UserKeyPair {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &model_sig_graph.UserKeyPair{
+		Public:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+		Private: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDer})),
+	}
+}
+
+// counts the requests passed on by the server
+type countingAssetTransferHandler struct {
+	count int
+}
+
+func (h *countingAssetTransferHandler) HandleAssetTransfer(
+	ctx context.Context,
+	ackId string,
+	requestTime *time.Time,
+	assetId string,
+	senderPublicKey string,
+	recipientPublicKey string,
+	exposedSecretIds map[string]model_asset_transfer.PrivateId,
+	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
+) error {
+	h.count++
+	return nil
+}
+
+// records the transfers instead of submitting them
+type transferringSigGraphClientApi struct {
+	api_sig_graph.SigGraphClientApi
+	count int
+}
+
+func (a *transferringSigGraphClientApi) TransferAssetAsync(
+	ctx context.Context,
+	time_ms uint64,
+	asset *model_sig_graph.Asset,
+	newOwnerKey *model_sig_graph.UserKeyPair,
+	newId string,
+	newSecret string,
+	currentSecret string,
+	currentSignature string,
+	currentSignatureScheme model.ESignatureScheme,
+) (*model_sig_graph.Asset, *model_sig_graph.Asset, api_sig_graph.LedgerTransactionI, error) {
+	a.count++
+	newAsset := *asset
+	newAsset.Id = newId
+	return asset, &newAsset, nil, nil
+}
+
+// address of a gRPC server checking request times against clock
+func startClockSkewTestServer(t *testing.T, clock utility.ClockI, handler AssetTransferHandlerI) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewAssetTransferServerGrpc(
+		handler,
+		nil,
+		listener.Addr().String(),
+		utility.NewHashedIdGeneratorService(),
+		clock,
+		&clockSkewTestLimits,
+		service_sig_graph.NewNodeIdValidator([]string{clockSkewTestGraphName}, []model.ENodeIdFormat{model.ENodeIdFormatUuidV4}),
+		nil,
+		nil,
+	)
+	grpcServer := grpc.NewServer()
+	sig_graph_grpc.RegisterTransferAssetServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	return listener.Addr().String()
+}
+
+func newClockSkewTestService(clock utility.ClockI, sigGraphClientApi api_sig_graph.SigGraphClientApi) *assetTransferServiceGrpc {
+	return NewAssetTransferServiceGrpc(
+		utility.NewGrpcConnectionPool(),
+		2,
+		utility.NewSecretIdGeneratorCrypto(32),
+		service_sig_graph.NewIdGenerateServiceUuid(clockSkewTestGraphName),
+		service_sig_graph.NewNodeSigningService(),
+		sigGraphClientApi,
+		utility.NewHashedIdGeneratorService(),
+		utility.NewCloner(),
+		clock,
+		&utility.ClockSkewLimits{MaxPast: 24 * time.Hour, MaxFuture: time.Minute},
+	)
+}
+
+func newClockSkewTestAsset(t *testing.T, owner *model_sig_graph.UserKeyPair) *model_sig_graph.Asset {
+	t.Helper()
+
+	id, err := service_sig_graph.NewIdGenerateServiceUuid(clockSkewTestGraphName).NewFullIdOfGraph(context.Background(), clockSkewTestGraphName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := model_sig_graph.Node{
+		Id:             id,
+		NodeType:       model.ENodeTypeAsset,
+		CreatedTime:    uint64(clockSkewTestNow.UnixMilli()),
+		UpdatedTime:    uint64(clockSkewTestNow.UnixMilli()),
+		OwnerPublicKey: owner.Public,
+	}
+	node.ClearEdges()
+	asset := model_sig_graph.NewAsset(node, model.ECreationProcessCreate, "kg", decimal.NewFromInt(1), "cocoa beans")
+	return &asset
+}
+
+func TestRequestToAcceptAssetClockSkew(t *testing.T) {
+	cases := []struct {
+		name        string
+		requestTime time.Time
+		isValid     bool
+	}{
+		{"now", clockSkewTestNow, true},
+		{"oldest", clockSkewTestNow.Add(-clockSkewTestLimits.MaxPast), true},
+		{"too old", clockSkewTestNow.Add(-clockSkewTestLimits.MaxPast - time.Millisecond), false},
+		{"furthest ahead", clockSkewTestNow.Add(clockSkewTestLimits.MaxFuture), true},
+		{"too far ahead", clockSkewTestNow.Add(clockSkewTestLimits.MaxFuture + time.Millisecond), false},
+	}
+
+	owner := newClockSkewTestKeyPair(t)
+	recipient := newClockSkewTestKeyPair(t)
+	clock := utility.NewClockFake(clockSkewTestNow)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			handler := &countingAssetTransferHandler{}
+			address := startClockSkewTestServer(t, clock, handler)
+			sender := newClockSkewTestService(clock, nil)
+			peer := &model_asset_transfer.Peer{
+				Protocol:         model_asset_transfer.PeerProtocol{Type: model.EPeerProtocolGrpc},
+				ConnectionUri:    address,
+				PeerPemPublicKey: recipient.Public,
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			request, err := sender.TransferAsset(ctx, c.requestTime, newClockSkewTestAsset(t, owner), owner, peer, nil, false)
+
+			if c.isValid {
+				if err != nil {
+					t.Fatalf("expected the request to be sent, got %v", err)
+				}
+				if request.AckId == "" || handler.count != 1 {
+					t.Fatalf("expected the request to be handled once with an ack id, got %d times", handler.count)
+				}
+				return
+			}
+
+			if !errors.Is(err, utility.ErrClockSkew) || !errors.Is(err, utility.ErrInvalidArgument) {
+				t.Fatalf("expected ErrClockSkew, got %v", err)
+			}
+			if handler.count != 0 {
+				t.Fatal("expected the request not to be handled")
+			}
+		})
+	}
+}
+
+func TestAcceptAssetClockSkew(t *testing.T) {
+	acceptLimits := utility.ClockSkewLimits{MaxPast: 24 * time.Hour, MaxFuture: time.Minute}
+	cases := []struct {
+		name        string
+		requestTime time.Time
+		isValid     bool
+	}{
+		{"now", clockSkewTestNow, true},
+		{"oldest", clockSkewTestNow.Add(-acceptLimits.MaxPast), true},
+		{"too old", clockSkewTestNow.Add(-acceptLimits.MaxPast - time.Millisecond), false},
+		{"furthest ahead", clockSkewTestNow.Add(acceptLimits.MaxFuture), true},
+		{"too far ahead", clockSkewTestNow.Add(acceptLimits.MaxFuture + time.Millisecond), false},
+	}
+
+	owner := newClockSkewTestKeyPair(t)
+	recipient := newClockSkewTestKeyPair(t)
+	clock := utility.NewClockFake(clockSkewTestNow)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sigGraphClientApi := &transferringSigGraphClientApi{}
+			recipientService := newClockSkewTestService(clock, sigGraphClientApi)
+			request := &model_asset_transfer.RequestToAcceptAsset{
+				TimeMs:      uint64(c.requestTime.UnixMilli()),
+				Asset:       *newClockSkewTestAsset(t, owner),
+				UserKeyPair: *recipient,
+				Candidates:  []model_asset_transfer.CandidateId{{Id: clockSkewTestGraphName + ":candidate"}},
+			}
+
+			_, _, _, _, err := recipientService.TransferAcceptedAsset(context.Background(), request, false)
+
+			if c.isValid {
+				if err != nil {
+					t.Fatalf("expected the asset to be transferred, got %v", err)
+				}
+				if sigGraphClientApi.count != 1 {
+					t.Fatalf("expected one transfer, got %d", sigGraphClientApi.count)
+				}
+				return
+			}
+
+			if !errors.Is(err, utility.ErrClockSkew) || !errors.Is(err, utility.ErrInvalidArgument) {
+				t.Fatalf("expected ErrClockSkew, got %v", err)
+			}
+			if sigGraphClientApi.count != 0 {
+				t.Fatal("expected no transfer")
+			}
+		})
+	}
+}
+
+func TestAcceptAssetClockSkewFollowsClock(t *testing.T) {
+	owner := newClockSkewTestKeyPair(t)
+	recipient := newClockSkewTestKeyPair(t)
+	clock := utility.NewClockFake(clockSkewTestNow)
+	sigGraphClientApi := &transferringSigGraphClientApi{}
+	recipientService := newClockSkewTestService(clock, sigGraphClientApi)
+	request := &model_asset_transfer.RequestToAcceptAsset{
+		TimeMs:      uint64(clockSkewTestNow.UnixMilli()),
+		Asset:       *newClockSkewTestAsset(t, owner),
+		UserKeyPair: *recipient,
+		Candidates:  []model_asset_transfer.CandidateId{{Id: clockSkewTestGraphName + ":candidate"}},
+	}
+
+	// the request was received in time but is accepted too late
+	clock.Advance(24*time.Hour + time.Millisecond)
+	_, _, _, _, err := recipientService.TransferAcceptedAsset(context.Background(), request, false)
+	if !errors.Is(err, utility.ErrClockSkew) {
+		t.Fatalf("expected ErrClockSkew, got %v", err)
+	}
+	if sigGraphClientApi.count != 0 {
+		t.Fatal("expected no transfer")
+	}
+}
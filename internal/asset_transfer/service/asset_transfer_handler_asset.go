@@ -0,0 +1,30 @@
+package service_asset_transfer
+
+import (
+	"context"
+	model_server "sig_graph_scp/pkg/server/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+)
+
+type transferredAssetCtxKeyType struct{}
+
+var transferredAssetCtxKey = transferredAssetCtxKeyType{}
+
+// the next handlers of the request reuse asset instead of reading it from the
+// ledger again. They must not change it
+func withTransferredAsset(ctx context.Context, asset *model_sig_graph.Asset) context.Context {
+	return context.WithValue(ctx, transferredAssetCtxKey, asset)
+}
+
+// the asset read by a previous handler of the request, or from the ledger
+func getTransferredAsset(
+	ctx context.Context,
+	sigGraphApi api_sig_graph.SigGraphClientApi,
+	assetId string,
+) (*model_sig_graph.Asset, error) {
+	if asset, ok := ctx.Value(transferredAssetCtxKey).(*model_sig_graph.Asset); ok && asset.Id == assetId {
+		return asset, nil
+	}
+	return sigGraphApi.GetAssetById(ctx, model_server.NodeId(assetId))
+}
@@ -22,6 +22,7 @@ func (s *assetTransferHandlerDefault) HandleAssetTransfer(
 	recipientPublicKey string,
 	exposedSecretIds map[string]model_asset_transfer.PrivateId,
 	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
 ) error {
 	return nil
 }
@@ -29,6 +29,7 @@ func (s *assetTransferHandlerEventBus) HandleAssetTransfer(
 	recipientPublicKey string,
 	exposedSecretIds map[string]model_asset_transfer.PrivateId,
 	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
 ) error {
 	request := model_asset_transfer.RequestToAcceptAssetEvent{
 		TimeMs:                    uint64(requestTime.UnixMilli()),
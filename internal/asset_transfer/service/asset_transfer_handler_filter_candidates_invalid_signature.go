@@ -0,0 +1,121 @@
+package service_asset_transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"time"
+)
+
+// drop candidates whose signature is not a signature of the finalized asset
+// by its owner, the ledger would reject transferring the asset to them.
+// Reject the request if no candidate is left, or if a signature cannot be
+// checked, e.g. of an unsupported scheme
+type assetTransferHandlerFilterCandidatesInvalidSignature struct {
+	handler       AssetTransferHandlerI
+	sigGraphApi   api_sig_graph.SigGraphClientApi
+	hashGenerator utility.HashedIdGeneratorServiceI
+	cloner        utility.ClonerI
+}
+
+func NewAssetTransferHandlerFilterCandidatesInvalidSignature(
+	handler AssetTransferHandlerI,
+	sigGraphApi api_sig_graph.SigGraphClientApi,
+	hashGenerator utility.HashedIdGeneratorServiceI,
+	cloner utility.ClonerI,
+) *assetTransferHandlerFilterCandidatesInvalidSignature {
+	return &assetTransferHandlerFilterCandidatesInvalidSignature{
+		handler:       handler,
+		sigGraphApi:   sigGraphApi,
+		hashGenerator: hashGenerator,
+		cloner:        cloner,
+	}
+}
+
+func (s *assetTransferHandlerFilterCandidatesInvalidSignature) HandleAssetTransfer(
+	ctx context.Context,
+	ackId string,
+	requestTime *time.Time,
+	assetId string,
+	senderPublicKey string,
+	recipientPublicKey string,
+	exposedSecretIds map[string]model_asset_transfer.PrivateId,
+	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
+) error {
+	asset, err := getTransferredAsset(ctx, s.sigGraphApi, assetId)
+	if err != nil {
+		return err
+	}
+
+	passedCandidates := []model_asset_transfer.CandidateId{}
+	for _, candidate := range candidates {
+		draftAsset, err := s.draftAsset(ctx, asset, requestTime, &candidate)
+		if err != nil {
+			return err
+		}
+
+		err = s.sigGraphApi.VerifyNodeSignature(ctx, asset.OwnerPublicKey, draftAsset, candidate.Signature)
+		if errors.Is(err, utility.ErrInvalidSignature) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		passedCandidates = append(passedCandidates, candidate)
+	}
+	if len(passedCandidates) == 0 {
+		return fmt.Errorf("%w: no candidate has a valid signature", utility.ErrInvalidSignature)
+	}
+
+	return s.handler.HandleAssetTransfer(
+		ctx,
+		ackId,
+		requestTime,
+		assetId,
+		senderPublicKey,
+		recipientPublicKey,
+		exposedSecretIds,
+		passedCandidates,
+		signature,
+	)
+}
+
+// the asset as the ledger finalizes it when it is transferred to candidate,
+// built like the draft signed by the sender
+func (s *assetTransferHandlerFilterCandidatesInvalidSignature) draftAsset(
+	ctx context.Context,
+	asset *model_sig_graph.Asset,
+	requestTime *time.Time,
+	candidate *model_asset_transfer.CandidateId,
+) (*model_sig_graph.Asset, error) {
+	draftAsset := &model_sig_graph.Asset{}
+	err := s.cloner.Clone(ctx, asset, draftAsset)
+	if err != nil {
+		return nil, err
+	}
+	draftAsset.UpdatedTime = uint64(requestTime.UnixMilli())
+	draftAsset.IsFinalized = true
+	draftAsset.SignatureScheme = candidate.SignatureScheme
+
+	if candidate.Secret != "" {
+		hash, err := s.hashGenerator.GenerateHashedId(ctx, candidate.Id, candidate.Secret)
+		if err != nil {
+			return nil, err
+		}
+		if draftAsset.PrivateChildrenHashedIds == nil {
+			draftAsset.PrivateChildrenHashedIds = map[string]bool{}
+		}
+		draftAsset.PrivateChildrenHashedIds[hash] = true
+	} else {
+		if draftAsset.PublicChildrenIds == nil {
+			draftAsset.PublicChildrenIds = map[string]bool{}
+		}
+		draftAsset.PublicChildrenIds[candidate.Id] = true
+	}
+	return draftAsset, nil
+}
@@ -0,0 +1,240 @@
+package service_asset_transfer
+
+import (
+	"context"
+	"errors"
+	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
+	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+	"time"
+)
+
+// a request as received by a handler
+type handledAssetTransfer struct {
+	ackId              string
+	requestTime        *time.Time
+	assetId            string
+	senderPublicKey    string
+	recipientPublicKey string
+	exposedSecretIds   map[string]model_asset_transfer.PrivateId
+	candidates         []model_asset_transfer.CandidateId
+	signature          *model_asset_transfer.RequestSignature
+}
+
+func (r *handledAssetTransfer) handleBy(ctx context.Context, handler AssetTransferHandlerI) error {
+	return handler.HandleAssetTransfer(
+		ctx,
+		r.ackId,
+		r.requestTime,
+		r.assetId,
+		r.senderPublicKey,
+		r.recipientPublicKey,
+		r.exposedSecretIds,
+		r.candidates,
+		r.signature,
+	)
+}
+
+// keeps the requests passed on by the server or the filters
+type recordingAssetTransferHandler struct {
+	requests []handledAssetTransfer
+}
+
+func (h *recordingAssetTransferHandler) HandleAssetTransfer(
+	ctx context.Context,
+	ackId string,
+	requestTime *time.Time,
+	assetId string,
+	senderPublicKey string,
+	recipientPublicKey string,
+	exposedSecretIds map[string]model_asset_transfer.PrivateId,
+	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
+) error {
+	h.requests = append(h.requests, handledAssetTransfer{
+		ackId:              ackId,
+		requestTime:        requestTime,
+		assetId:            assetId,
+		senderPublicKey:    senderPublicKey,
+		recipientPublicKey: recipientPublicKey,
+		exposedSecretIds:   exposedSecretIds,
+		candidates:         candidates,
+		signature:          signature,
+	})
+	return nil
+}
+
+// ledger holding a single asset, signatures are checked for real
+type assetSigGraphClientApi struct {
+	api_sig_graph.SigGraphClientApi
+	asset         *model_sig_graph.Asset
+	getAssetCount int
+}
+
+func (a *assetSigGraphClientApi) GetAssetById(ctx context.Context, id model_server.NodeId) (*model_sig_graph.Asset, error) {
+	a.getAssetCount++
+	if a.asset == nil || string(id) != a.asset.Id {
+		return nil, utility.ErrNotFound
+	}
+
+	asset := &model_sig_graph.Asset{}
+	err := utility.NewCloner().Clone(ctx, a.asset, asset)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+func (a *assetSigGraphClientApi) VerifyNodeSignature(ctx context.Context, publicKey string, node any, signature string) error {
+	return service_sig_graph.NewNodeVerifyingService().Verify(ctx, publicKey, node, signature)
+}
+
+// a request to accept asset with private or public candidates, as sent by
+// the owner of asset and received by the recipient
+func sendFilterTestRequest(
+	t *testing.T,
+	asset *model_sig_graph.Asset,
+	owner *model_sig_graph.UserKeyPair,
+	exposedSecretIds map[string]model_asset_transfer.PrivateId,
+	isPrivate bool,
+) handledAssetTransfer {
+	t.Helper()
+
+	clock := utility.NewClockFake(clockSkewTestNow)
+	handler := &recordingAssetTransferHandler{}
+	address := startClockSkewTestServer(t, clock, handler)
+	peer := &model_asset_transfer.Peer{
+		Protocol:         model_asset_transfer.PeerProtocol{Type: model.EPeerProtocolGrpc},
+		ConnectionUri:    address,
+		PeerPemPublicKey: newClockSkewTestKeyPair(t).Public,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := newClockSkewTestService(clock, nil).TransferAsset(ctx, clockSkewTestNow, asset, owner, peer, exposedSecretIds, isPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(handler.requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(handler.requests))
+	}
+	return handler.requests[0]
+}
+
+func candidateIds(candidates []model_asset_transfer.CandidateId) map[string]bool {
+	ids := map[string]bool{}
+	for _, candidate := range candidates {
+		ids[candidate.Id] = true
+	}
+	return ids
+}
+
+// changes candidate, other is the other candidate as sent
+type candidateTamper func(candidate *model_asset_transfer.CandidateId, other *model_asset_transfer.CandidateId)
+
+func TestFilterCandidatesInvalidSignature(t *testing.T) {
+	var tamperId candidateTamper = func(candidate *model_asset_transfer.CandidateId, other *model_asset_transfer.CandidateId) {
+		candidate.Id = clockSkewTestGraphName + ":tampered"
+	}
+	// signed for the other candidate
+	var tamperSignature candidateTamper = func(candidate *model_asset_transfer.CandidateId, other *model_asset_transfer.CandidateId) {
+		candidate.Signature = other.Signature
+	}
+
+	// the test sender signs two candidates
+	cases := []struct {
+		name    string
+		tampers map[int]candidateTamper
+		// passed candidates by index, the request is rejected if empty
+		passed []int
+	}{
+		{"valid", nil, []int{0, 1}},
+		{"tampered id", map[int]candidateTamper{0: tamperId}, []int{1}},
+		{"tampered signature", map[int]candidateTamper{1: tamperSignature}, []int{0}},
+		{"all invalid", map[int]candidateTamper{0: tamperId, 1: tamperSignature}, nil},
+	}
+
+	for _, isPrivate := range []bool{true, false} {
+		name := "public"
+		if isPrivate {
+			name = "private"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			for _, c := range cases {
+				t.Run(c.name, func(t *testing.T) {
+					owner := newClockSkewTestKeyPair(t)
+					asset := newClockSkewTestAsset(t, owner)
+					request := sendFilterTestRequest(t, asset, owner, nil, isPrivate)
+					sent := append([]model_asset_transfer.CandidateId{}, request.candidates...)
+					for i, tamper := range c.tampers {
+						tamper(&request.candidates[i], &sent[1-i])
+					}
+
+					next := &recordingAssetTransferHandler{}
+					filter := NewAssetTransferHandlerFilterCandidatesInvalidSignature(
+						next,
+						&assetSigGraphClientApi{asset: asset},
+						utility.NewHashedIdGeneratorService(),
+						utility.NewCloner(),
+					)
+					err := request.handleBy(context.Background(), filter)
+
+					if len(c.passed) == 0 {
+						if !errors.Is(err, utility.ErrInvalidSignature) {
+							t.Fatalf("expected ErrInvalidSignature, got %v", err)
+						}
+						if len(next.requests) != 0 {
+							t.Fatal("expected the request not to be passed on")
+						}
+						return
+					}
+
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(next.requests) != 1 {
+						t.Fatalf("expected the request to be passed on once, got %d times", len(next.requests))
+					}
+					passedIds := candidateIds(next.requests[0].candidates)
+					if len(passedIds) != len(c.passed) {
+						t.Fatalf("expected %d candidates, got %d", len(c.passed), len(passedIds))
+					}
+					for _, i := range c.passed {
+						if !passedIds[request.candidates[i].Id] {
+							t.Fatalf("expected candidate %d to be passed on", i)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestFilterCandidatesInvalidSignatureReturnsOtherErrors(t *testing.T) {
+	owner := newClockSkewTestKeyPair(t)
+	asset := newClockSkewTestAsset(t, owner)
+	request := sendFilterTestRequest(t, asset, owner, nil, false)
+	// the signature of this candidate cannot be checked at all
+	request.candidates[1].SignatureScheme = "unknown"
+
+	next := &recordingAssetTransferHandler{}
+	filter := NewAssetTransferHandlerFilterCandidatesInvalidSignature(
+		next,
+		&assetSigGraphClientApi{asset: asset},
+		utility.NewHashedIdGeneratorService(),
+		utility.NewCloner(),
+	)
+	err := request.handleBy(context.Background(), filter)
+	if !errors.Is(err, utility.ErrInvalidArgument) || errors.Is(err, utility.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+	if len(next.requests) != 0 {
+		t.Fatal("expected the request not to be passed on")
+	}
+}
@@ -31,6 +31,7 @@ func (s *assetTransferHandlerFilterExposedSecretIdsInvalidHash) HandleAssetTrans
 	recipientPublicKey string,
 	exposedSecretIds map[string]model_asset_transfer.PrivateId,
 	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
 ) error {
 	passedExposedSecretIds := map[string]model_asset_transfer.PrivateId{}
 	for hash := range exposedSecretIds {
@@ -68,5 +69,6 @@ func (s *assetTransferHandlerFilterExposedSecretIdsInvalidHash) HandleAssetTrans
 		recipientPublicKey,
 		passedExposedSecretIds,
 		candidates,
+		signature,
 	)
 }
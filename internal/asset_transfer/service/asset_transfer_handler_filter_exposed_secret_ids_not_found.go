@@ -32,6 +32,7 @@ func (s *assetTransferHandlerFilterExposedSecretIdsNotFound) HandleAssetTransfer
 	recipientPublicKey string,
 	exposedSecretIds map[string]model_asset_transfer.PrivateId,
 	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
 ) error {
 	// verify that nodes exist
 	ids := map[string]bool{}
@@ -70,5 +71,6 @@ func (s *assetTransferHandlerFilterExposedSecretIdsNotFound) HandleAssetTransfer
 		recipientPublicKey,
 		foundExposedSecretIds,
 		candidates,
+		signature,
 	)
 }
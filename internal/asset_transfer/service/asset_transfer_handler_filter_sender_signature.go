@@ -0,0 +1,85 @@
+package service_asset_transfer
+
+import (
+	"context"
+	"fmt"
+	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	"sig_graph_scp/pkg/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	"sig_graph_scp/pkg/utility"
+	"time"
+)
+
+// reject requests that are not signed by the sender, or whose sender does not
+// own the asset on the ledger
+type assetTransferHandlerFilterSenderSignature struct {
+	handler     AssetTransferHandlerI
+	sigGraphApi api_sig_graph.SigGraphClientApi
+}
+
+func NewAssetTransferHandlerFilterSenderSignature(
+	handler AssetTransferHandlerI,
+	sigGraphApi api_sig_graph.SigGraphClientApi,
+) *assetTransferHandlerFilterSenderSignature {
+	return &assetTransferHandlerFilterSenderSignature{
+		handler:     handler,
+		sigGraphApi: sigGraphApi,
+	}
+}
+
+func (s *assetTransferHandlerFilterSenderSignature) HandleAssetTransfer(
+	ctx context.Context,
+	ackId string,
+	requestTime *time.Time,
+	assetId string,
+	senderPublicKey string,
+	recipientPublicKey string,
+	exposedSecretIds map[string]model_asset_transfer.PrivateId,
+	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
+) error {
+	if signature == nil {
+		return fmt.Errorf("%w: request is not signed by the sender", utility.ErrInvalidSignature)
+	}
+	// requests were never signed with the legacy scheme
+	if signature.SignatureScheme != model.ESignatureSchemeCanonicalJsonV1 {
+		return fmt.Errorf("%w: unsupported request signature scheme %s", utility.ErrInvalidSignature, signature.SignatureScheme)
+	}
+
+	signingPayload := newRequestToAcceptAssetSigningPayload(
+		uint64(requestTime.UnixMilli()),
+		assetId,
+		senderPublicKey,
+		recipientPublicKey,
+		candidates,
+		exposedSecretIds,
+		signature.SignatureScheme,
+	)
+	err := s.sigGraphApi.VerifyNodeSignature(ctx, senderPublicKey, signingPayload, signature.Signature)
+	if err != nil {
+		return err
+	}
+
+	asset, err := getTransferredAsset(ctx, s.sigGraphApi, assetId)
+	if err != nil {
+		return err
+	}
+	if asset.OwnerPublicKey != senderPublicKey {
+		return fmt.Errorf("%w: sender does not own asset %s", utility.ErrPermissionDenied, assetId)
+	}
+	if asset.IsFinalized {
+		return fmt.Errorf("%w: asset %s", utility.ErrAlreadyFinalized, assetId)
+	}
+
+	return s.handler.HandleAssetTransfer(
+		withTransferredAsset(ctx, asset),
+		ackId,
+		requestTime,
+		assetId,
+		senderPublicKey,
+		recipientPublicKey,
+		exposedSecretIds,
+		candidates,
+		signature,
+	)
+}
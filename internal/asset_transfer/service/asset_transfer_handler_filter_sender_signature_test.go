@@ -0,0 +1,122 @@
+package service_asset_transfer
+
+import (
+	"context"
+	"errors"
+	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+)
+
+// an exposed secret id between two nodes of the test graph, by its hash
+func newSenderSignatureTestSecretIds(t *testing.T, owner *model_sig_graph.UserKeyPair) map[string]model_asset_transfer.PrivateId {
+	t.Helper()
+
+	this := newClockSkewTestAsset(t, owner)
+	other := newClockSkewTestAsset(t, owner)
+	hash, err := utility.NewHashedIdGeneratorService().GenerateHashedId(context.Background(), this.Id, "this secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]model_asset_transfer.PrivateId{
+		hash: {
+			ThisId:      this.Id,
+			ThisSecret:  "this secret",
+			OtherId:     other.Id,
+			OtherSecret: "other secret",
+		},
+	}
+}
+
+func TestFilterSenderSignature(t *testing.T) {
+	cases := []struct {
+		name string
+		// changes the request or the asset on the ledger after the request is sent
+		tamper func(request *handledAssetTransfer, asset *model_sig_graph.Asset)
+		err    error
+	}{
+		{"signed by the sender", func(request *handledAssetTransfer, asset *model_sig_graph.Asset) {}, nil},
+		{"no signature", func(request *handledAssetTransfer, asset *model_sig_graph.Asset) {
+			request.signature = nil
+		}, utility.ErrInvalidSignature},
+		{"legacy scheme", func(request *handledAssetTransfer, asset *model_sig_graph.Asset) {
+			request.signature.SignatureScheme = model.ESignatureSchemeLegacy
+		}, utility.ErrInvalidSignature},
+		{"asset of another owner", func(request *handledAssetTransfer, asset *model_sig_graph.Asset) {
+			asset.OwnerPublicKey = newClockSkewTestKeyPair(t).Public
+		}, utility.ErrPermissionDenied},
+		{"finalized asset", func(request *handledAssetTransfer, asset *model_sig_graph.Asset) {
+			asset.IsFinalized = true
+		}, utility.ErrAlreadyFinalized},
+		{"changed candidate", func(request *handledAssetTransfer, asset *model_sig_graph.Asset) {
+			request.candidates[0].Id = clockSkewTestGraphName + ":tampered"
+		}, utility.ErrInvalidSignature},
+		{"changed secret id", func(request *handledAssetTransfer, asset *model_sig_graph.Asset) {
+			for hash, id := range request.exposedSecretIds {
+				id.OtherSecret = "tampered"
+				request.exposedSecretIds[hash] = id
+			}
+		}, utility.ErrInvalidSignature},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			owner := newClockSkewTestKeyPair(t)
+			asset := newClockSkewTestAsset(t, owner)
+			request := sendFilterTestRequest(t, asset, owner, newSenderSignatureTestSecretIds(t, owner), true)
+			c.tamper(&request, asset)
+
+			next := &recordingAssetTransferHandler{}
+			filter := NewAssetTransferHandlerFilterSenderSignature(next, &assetSigGraphClientApi{asset: asset})
+			err := request.handleBy(context.Background(), filter)
+
+			if c.err == nil {
+				if err != nil {
+					t.Fatalf("expected the request to verify, got %v", err)
+				}
+				if len(next.requests) != 1 {
+					t.Fatalf("expected the request to be passed on once, got %d times", len(next.requests))
+				}
+				return
+			}
+
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+			if len(next.requests) != 0 {
+				t.Fatal("expected the request not to be passed on")
+			}
+		})
+	}
+}
+
+func TestFilterSenderSignatureReadsAssetOnce(t *testing.T) {
+	owner := newClockSkewTestKeyPair(t)
+	asset := newClockSkewTestAsset(t, owner)
+	request := sendFilterTestRequest(t, asset, owner, nil, false)
+
+	sigGraphApi := &assetSigGraphClientApi{asset: asset}
+	next := &recordingAssetTransferHandler{}
+	filter := NewAssetTransferHandlerFilterSenderSignature(
+		NewAssetTransferHandlerFilterCandidatesInvalidSignature(
+			next,
+			sigGraphApi,
+			utility.NewHashedIdGeneratorService(),
+			utility.NewCloner(),
+		),
+		sigGraphApi,
+	)
+	err := request.handleBy(context.Background(), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.requests) != 1 || len(next.requests[0].candidates) != len(request.candidates) {
+		t.Fatal("expected the request to be passed on with every candidate")
+	}
+	if sigGraphApi.getAssetCount != 1 {
+		t.Fatalf("expected the asset to be read once, got %d times", sigGraphApi.getAssetCount)
+	}
+}
@@ -16,5 +16,7 @@ type AssetTransferHandlerI interface {
 		recipientPublicKey string,
 		exposedSecretIds map[string]model_asset_transfer.PrivateId,
 		candidates []model_asset_transfer.CandidateId,
+		// nil if the sender did not sign the request
+		signature *model_asset_transfer.RequestSignature,
 	) error
 }
@@ -35,6 +35,7 @@ func (s *assetTransferHandlerMultiple) HandleAssetTransfer(
 	recipientPublicKey string,
 	exposedSecretIds map[string]model_asset_transfer.PrivateId,
 	candidates []model_asset_transfer.CandidateId,
+	signature *model_asset_transfer.RequestSignature,
 ) error {
 	for i := len(s.handlers) - 1; i >= 0; i-- {
 		err := s.handlers[i].HandleAssetTransfer(
@@ -46,6 +47,7 @@ func (s *assetTransferHandlerMultiple) HandleAssetTransfer(
 			recipientPublicKey,
 			exposedSecretIds,
 			candidates,
+			signature,
 		)
 
 		if err != nil {
@@ -2,15 +2,19 @@ package service_asset_transfer
 
 import (
 	"context"
+	"fmt"
 	"net"
 	utility_asset_transfer "sig_graph_scp/internal/asset_transfer/utility"
 	sig_graph_grpc "sig_graph_scp/internal/grpc"
+	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
 	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 	"sig_graph_scp/pkg/utility"
 	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 type assetTransferServerGrpc struct {
@@ -20,6 +24,13 @@ type assetTransferServerGrpc struct {
 	assetAcceptHandler     AssetAcceptHandlerI
 	address                string
 	hashGenerator          utility.HashedIdGeneratorServiceI
+	clock                  utility.ClockI
+	clockSkewLimits        *utility.ClockSkewLimits
+	nodeIdValidator        service_sig_graph.NodeIdValidatorI
+	// plaintext if nil
+	tlsOptions *utility.GrpcTlsOptions
+	// authenticates the peers accepting our requests, with tlsOptions
+	outboundRequestPeers OutboundRequestPeerResolverI
 }
 
 func NewAssetTransferServerGrpc(
@@ -27,6 +38,11 @@ func NewAssetTransferServerGrpc(
 	assetAcceptHandler AssetAcceptHandlerI,
 	address string,
 	hashGenerator utility.HashedIdGeneratorServiceI,
+	clock utility.ClockI,
+	clockSkewLimits *utility.ClockSkewLimits,
+	nodeIdValidator service_sig_graph.NodeIdValidatorI,
+	tlsOptions *utility.GrpcTlsOptions,
+	outboundRequestPeers OutboundRequestPeerResolverI,
 ) *assetTransferServerGrpc {
 	return &assetTransferServerGrpc{
 		mtx:                    utility.NewMutex(),
@@ -34,6 +50,11 @@ func NewAssetTransferServerGrpc(
 		assetAcceptHandler:     assetAcceptHandler,
 		address:                address,
 		hashGenerator:          hashGenerator,
+		clock:                  clock,
+		clockSkewLimits:        clockSkewLimits,
+		nodeIdValidator:        nodeIdValidator,
+		tlsOptions:             tlsOptions,
+		outboundRequestPeers:   outboundRequestPeers,
 	}
 }
 
@@ -64,7 +85,11 @@ func (s *assetTransferServerGrpc) Start() error {
 		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	serverOptions := []grpc.ServerOption{}
+	if s.tlsOptions != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(s.tlsOptions.ServerConfig())))
+	}
+	grpcServer := grpc.NewServer(serverOptions...)
 
 	sig_graph_grpc.RegisterTransferAssetServer(grpcServer, s)
 
@@ -85,7 +110,32 @@ func (s *assetTransferServerGrpc) RequestToAcceptAsset(
 	handler := s.requestToAcceptHandler
 	s.mtx.Unlock(ctx)
 
+	// the sender must be authenticated as the owner of the asset
+	if s.tlsOptions != nil {
+		err := utility.VerifyGrpcPeerPublicKey(ctx, request.OwnerPublicKey)
+		if err != nil {
+			return &sig_graph_grpc.RequestToAcceptAssetResponse{
+				Error: utility_asset_transfer.ToGrpcError(err),
+			}, nil
+		}
+	}
+
 	requestTime := time.UnixMilli(int64(request.TimeMs))
+	// the receiver transfers the asset at the time of the request
+	err := utility.CheckClockSkew(s.clock, s.clockSkewLimits, requestTime)
+	if err != nil {
+		return &sig_graph_grpc.RequestToAcceptAssetResponse{
+			Error: utility_asset_transfer.ToGrpcError(err),
+		}, nil
+	}
+
+	err = s.validateNodeIds(ctx, request)
+	if err != nil {
+		return &sig_graph_grpc.RequestToAcceptAssetResponse{
+			Error: utility_asset_transfer.ToGrpcError(err),
+		}, nil
+	}
+
 	assetId := request.AssetId
 	senderPublicKey := request.OwnerPublicKey
 	recipientPublicKey := request.NewOwnerPublicKey
@@ -121,14 +171,23 @@ func (s *assetTransferServerGrpc) RequestToAcceptAsset(
 	candidates := []model_asset_transfer.CandidateId{}
 	for i := range request.Candidates {
 		candidates = append(candidates, model_asset_transfer.CandidateId{
-			Id:        request.Candidates[i].Id,
-			Secret:    request.Candidates[i].Secret,
-			Signature: request.Candidates[i].Signature,
+			Id:              request.Candidates[i].Id,
+			Secret:          request.Candidates[i].Secret,
+			Signature:       request.Candidates[i].Signature,
+			SignatureScheme: request.Candidates[i].SignatureScheme,
 		})
 	}
 
+	var signature *model_asset_transfer.RequestSignature
+	if request.Signature != "" {
+		signature = &model_asset_transfer.RequestSignature{
+			Signature:       request.Signature,
+			SignatureScheme: request.SignatureScheme,
+		}
+	}
+
 	ackId := uuid.New().String()
-	err := handler.HandleAssetTransfer(ctx, ackId, &requestTime, assetId, senderPublicKey, recipientPublicKey, exposedSecretIds, candidates)
+	err = handler.HandleAssetTransfer(ctx, ackId, &requestTime, assetId, senderPublicKey, recipientPublicKey, exposedSecretIds, candidates, signature)
 	if err != nil {
 		return &sig_graph_grpc.RequestToAcceptAssetResponse{
 			Error: utility_asset_transfer.ToGrpcError(err),
@@ -140,17 +199,64 @@ func (s *assetTransferServerGrpc) RequestToAcceptAsset(
 	}, nil
 }
 
+// the asset and the exposed nodes must be on our graphs, the candidates on the
+// graph of the asset
+func (s *assetTransferServerGrpc) validateNodeIds(
+	ctx context.Context,
+	request *sig_graph_grpc.RequestToAcceptAssetRequest,
+) error {
+	err := s.nodeIdValidator.ValidateNodeId(ctx, request.AssetId)
+	if err != nil {
+		return err
+	}
+	graphName, err := model_sig_graph.GraphNameOfNodeId(request.AssetId)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range request.Candidates {
+		err = s.nodeIdValidator.ValidateNodeIdOfGraph(ctx, candidate.Id, graphName)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range request.SecretIds {
+		err = s.nodeIdValidator.ValidateNodeId(ctx, id.ThisId)
+		if err != nil {
+			return err
+		}
+		err = s.nodeIdValidator.ValidateNodeId(ctx, id.OtherId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *assetTransferServerGrpc) AcceptAsset(
 	ctx context.Context,
 	request *sig_graph_grpc.AcceptAssetRequest,
 ) (*sig_graph_grpc.AcceptAssetResponse, error) {
 	if !s.mtx.Lock(ctx) {
-		return &sig_graph_grpc.AcceptAssetResponse{}, nil
+		return &sig_graph_grpc.AcceptAssetResponse{
+			Error: utility_asset_transfer.ToGrpcError(utility.ErrTimedOut),
+		}, nil
 	}
 
 	handler := s.assetAcceptHandler
 	s.mtx.Unlock(ctx)
 
+	// only the peer the request was sent to may answer it
+	if s.tlsOptions != nil {
+		err := s.verifyAcceptingPeer(ctx, request.AckId)
+		if err != nil {
+			return &sig_graph_grpc.AcceptAssetResponse{
+				Error: utility_asset_transfer.ToGrpcError(err),
+			}, nil
+		}
+	}
+
 	handler.HandleAssetAccept(
 		ctx,
 		request.AckId,
@@ -160,3 +266,15 @@ func (s *assetTransferServerGrpc) AcceptAsset(
 
 	return &sig_graph_grpc.AcceptAssetResponse{}, nil
 }
+
+func (s *assetTransferServerGrpc) verifyAcceptingPeer(ctx context.Context, ackId string) error {
+	if s.outboundRequestPeers == nil {
+		return fmt.Errorf("%w: the peers of outbound requests are unknown", utility.ErrPermissionDenied)
+	}
+
+	peerPublicKey, err := s.outboundRequestPeers.GetPeerPublicKeyByAckId(ctx, ackId)
+	if err != nil {
+		return err
+	}
+	return utility.VerifyGrpcPeerPublicKey(ctx, peerPublicKey)
+}
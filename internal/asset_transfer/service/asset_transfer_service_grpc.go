@@ -2,6 +2,8 @@ package service_asset_transfer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	utility_asset_transfer "sig_graph_scp/internal/asset_transfer/utility"
 	sig_graph_grpc "sig_graph_scp/internal/grpc"
 	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
@@ -14,35 +16,41 @@ import (
 )
 
 type assetTransferServiceGrpc struct {
-	connPool             utility.GrpcConnectionPoolI
-	numberOfCandidate    uint32
-	secretIdGeneratorI   SecretIdGeneratorI
-	idGeneratorService   service_sig_graph.IdGenerateServiceI
-	nodeSigningService   service_sig_graph.NodeSigningServiceI
-	sigGraphClientApi    api_sig_graph.SigGraphClientApi
-	hashGeneratorService utility.HashedIdGeneratorServiceI
-	cloner               utility.ClonerI
+	connPool              utility.GrpcConnectionPoolI
+	numberOfCandidate     uint32
+	secretIdGeneratorI    utility.SecretIdGeneratorI
+	idGeneratorService    service_sig_graph.IdGenerateServiceI
+	nodeSigningService    service_sig_graph.NodeSigningServiceI
+	sigGraphClientApi     api_sig_graph.SigGraphClientApi
+	hashGeneratorService  utility.HashedIdGeneratorServiceI
+	cloner                utility.ClonerI
+	clock                 utility.ClockI
+	acceptClockSkewLimits *utility.ClockSkewLimits
 }
 
 func NewAssetTransferServiceGrpc(
 	connPool utility.GrpcConnectionPoolI,
 	numberOfCandidate uint32,
-	secretIdGeneratorI SecretIdGeneratorI,
+	secretIdGeneratorI utility.SecretIdGeneratorI,
 	idGeneratorService service_sig_graph.IdGenerateServiceI,
 	nodeSigningService service_sig_graph.NodeSigningServiceI,
 	sigGraphClientApi api_sig_graph.SigGraphClientApi,
 	hashGeneratorService utility.HashedIdGeneratorServiceI,
 	cloner utility.ClonerI,
+	clock utility.ClockI,
+	acceptClockSkewLimits *utility.ClockSkewLimits,
 ) *assetTransferServiceGrpc {
 	return &assetTransferServiceGrpc{
-		connPool:             connPool,
-		numberOfCandidate:    numberOfCandidate,
-		secretIdGeneratorI:   secretIdGeneratorI,
-		idGeneratorService:   idGeneratorService,
-		nodeSigningService:   nodeSigningService,
-		sigGraphClientApi:    sigGraphClientApi,
-		hashGeneratorService: hashGeneratorService,
-		cloner:               cloner,
+		connPool:              connPool,
+		numberOfCandidate:     numberOfCandidate,
+		secretIdGeneratorI:    secretIdGeneratorI,
+		idGeneratorService:    idGeneratorService,
+		nodeSigningService:    nodeSigningService,
+		sigGraphClientApi:     sigGraphClientApi,
+		hashGeneratorService:  hashGeneratorService,
+		cloner:                cloner,
+		clock:                 clock,
+		acceptClockSkewLimits: acceptClockSkewLimits,
 	}
 }
 
@@ -67,11 +75,11 @@ func (s *assetTransferServiceGrpc) TransferAsset(
 		return nil, utility.ErrInvalidArgument
 	}
 
-	conn, err := s.connPool.NewConnection(ctx, peer.ConnectionUri)
+	conn, err := s.connPool.NewConnection(ctx, peer.ConnectionUri, ownerKey.Public, ownerKey.Private)
 	if err != nil {
 		return nil, err
 	}
-	defer s.connPool.ReturnConnection(ctx, peer.ConnectionUri, conn)
+	defer s.connPool.ReturnConnection(ctx, peer.ConnectionUri, ownerKey.Public, conn)
 
 	secretIds := map[string]*sig_graph_grpc.SecretId{}
 	candidates := []*sig_graph_grpc.SignatureCandidate{}
@@ -87,6 +95,12 @@ func (s *assetTransferServiceGrpc) TransferAsset(
 		}
 	}
 
+	// candidates are on the graph of the asset
+	graphName, err := model_sig_graph.GraphNameOfNodeId(string(asset.Node.Id))
+	if err != nil {
+		return nil, err
+	}
+
 	for i := uint32(0); i < s.numberOfCandidate; i++ {
 		// generate signature for this candidate
 		secret := ""
@@ -99,8 +113,9 @@ func (s *assetTransferServiceGrpc) TransferAsset(
 		}
 		draftAsset.UpdatedTime = uint64(requestTime.UnixMilli())
 		draftAsset.IsFinalized = true
+		draftAsset.SignatureScheme = s.nodeSigningService.Scheme()
 
-		id, err := s.idGeneratorService.NewFullId(ctx)
+		id, err := s.idGeneratorService.NewFullIdOfGraph(ctx, graphName)
 		if err != nil {
 			return nil, err
 		}
@@ -126,13 +141,39 @@ func (s *assetTransferServiceGrpc) TransferAsset(
 			return nil, err
 		}
 		newCandidate := sig_graph_grpc.SignatureCandidate{
-			Id:        string(id),
-			Secret:    secret,
-			Signature: signature,
+			Id:              string(id),
+			Secret:          secret,
+			Signature:       signature,
+			SignatureScheme: draftAsset.SignatureScheme,
 		}
 		candidates = append(candidates, &newCandidate)
 	}
 
+	tempCandidates := []model_asset_transfer.CandidateId{}
+	for i := range candidates {
+		tempCandidates = append(tempCandidates, model_asset_transfer.CandidateId{
+			Id:              candidates[i].Id,
+			Secret:          candidates[i].Secret,
+			Signature:       candidates[i].Signature,
+			SignatureScheme: candidates[i].SignatureScheme,
+		})
+	}
+
+	// the recipient checks that the request comes from the owner of the asset
+	signingPayload := newRequestToAcceptAssetSigningPayload(
+		uint64(requestTime.UnixMilli()),
+		string(asset.Node.Id),
+		ownerKey.Public,
+		peer.PeerPemPublicKey,
+		tempCandidates,
+		exposedPrivateConnections,
+		s.nodeSigningService.Scheme(),
+	)
+	requestSignature, err := s.nodeSigningService.Sign(ctx, ownerKey, signingPayload)
+	if err != nil {
+		return nil, err
+	}
+
 	grpcRequest := sig_graph_grpc.RequestToAcceptAssetRequest{
 		TimeMs:            uint64(requestTime.UnixMilli()),
 		AssetId:           string(asset.Node.Id),
@@ -140,6 +181,8 @@ func (s *assetTransferServiceGrpc) TransferAsset(
 		NewOwnerPublicKey: peer.PeerPemPublicKey,
 		SecretIds:         secretIds,
 		Candidates:        candidates,
+		Signature:         requestSignature,
+		SignatureScheme:   signingPayload.SignatureScheme,
 	}
 
 	response, err := client.RequestToAcceptAsset(ctx, &grpcRequest)
@@ -153,15 +196,6 @@ func (s *assetTransferServiceGrpc) TransferAsset(
 	}
 
 	{
-		tempCandidates := []model_asset_transfer.CandidateId{}
-		for i := range candidates {
-			tempCandidates = append(tempCandidates, model_asset_transfer.CandidateId{
-				Id:        candidates[i].Id,
-				Secret:    candidates[i].Secret,
-				Signature: candidates[i].Signature,
-			})
-		}
-
 		modelRequest := model_asset_transfer.RequestToAcceptAsset{
 			Status:                    model.ERequestToAcceptAssetStatusPending,
 			IsOutboundOrInbound:       true,
@@ -187,46 +221,86 @@ func (s *assetTransferServiceGrpc) AcceptRequestToAcceptAsset(
 	message string,
 	isNewConnectionSecretOrPublic bool,
 ) (updatedRequest *model_asset_transfer.RequestToAcceptAsset, newSecret string, oldSecret string, err error) {
-	conn, err := s.connPool.NewConnection(ctx, peer.ConnectionUri)
-	if err != nil {
-		return
-	}
-	defer s.connPool.ReturnConnection(ctx, peer.ConnectionUri, conn)
-
 	updatedRequest = &model_asset_transfer.RequestToAcceptAsset{}
 	*updatedRequest = *request
 
-	grpcRequest := sig_graph_grpc.AcceptAssetRequest{
-		AckId:    updatedRequest.AckId,
-		Accepted: acceptOrReject,
-		Message:  message,
-	}
-
 	if acceptOrReject {
-		newSecret, oldSecret, err = s.transferAssetOnSigraphAndUpdateAssetOfRequest(
+		var transaction api_sig_graph.LedgerTransactionI
+		updatedRequest, newSecret, oldSecret, transaction, err = s.TransferAcceptedAsset(
 			ctx,
+			request,
 			isNewConnectionSecretOrPublic,
-			updatedRequest,
 		)
 		if err != nil {
 			return
 		}
+
+		err = transaction.WaitForCommit(ctx)
+		if err != nil {
+			return
+		}
+	}
+
+	err = s.SendAcceptAsset(ctx, peer, updatedRequest, acceptOrReject, message)
+	return
+}
+
+func (s *assetTransferServiceGrpc) TransferAcceptedAsset(
+	ctx context.Context,
+	request *model_asset_transfer.RequestToAcceptAsset,
+	isNewConnectionSecretOrPublic bool,
+) (updatedRequest *model_asset_transfer.RequestToAcceptAsset, newSecret string, oldSecret string, transaction api_sig_graph.LedgerTransactionI, err error) {
+	updatedRequest = &model_asset_transfer.RequestToAcceptAsset{}
+	*updatedRequest = *request
+
+	newSecret, oldSecret, transaction, err = s.transferAssetOnSigraphAndUpdateAssetOfRequest(
+		ctx,
+		isNewConnectionSecretOrPublic,
+		updatedRequest,
+	)
+	return
+}
+
+func (s *assetTransferServiceGrpc) SendAcceptAsset(
+	ctx context.Context,
+	peer *model_asset_transfer.Peer,
+	request *model_asset_transfer.RequestToAcceptAsset,
+	acceptOrReject bool,
+	message string,
+) error {
+	// answer as the recipient
+	conn, err := s.connPool.NewConnection(ctx, peer.ConnectionUri, request.UserKeyPair.Public, request.UserKeyPair.Private)
+	if err != nil {
+		return err
+	}
+	defer s.connPool.ReturnConnection(ctx, peer.ConnectionUri, request.UserKeyPair.Public, conn)
+
+	grpcRequest := sig_graph_grpc.AcceptAssetRequest{
+		AckId:    request.AckId,
+		Accepted: acceptOrReject,
+		Message:  message,
 	}
 
 	client := sig_graph_grpc.NewTransferAssetClient(conn)
-	_, err = client.AcceptAsset(ctx, &grpcRequest)
+	response, err := client.AcceptAsset(ctx, &grpcRequest)
 	if err != nil {
-		return
+		return err
 	}
 
-	return
+	return utility_asset_transfer.WrapGrpcError(response.GetError())
 }
 
 func (s *assetTransferServiceGrpc) transferAssetOnSigraphAndUpdateAssetOfRequest(
 	ctx context.Context,
 	isNewConnectionSecretOrPublic bool,
 	request *model_asset_transfer.RequestToAcceptAsset,
-) (newSecret string, oldSecret string, err error) {
+) (newSecret string, oldSecret string, transaction api_sig_graph.LedgerTransactionI, err error) {
+	// the new node keeps the time of the request
+	err = utility.CheckClockSkew(s.clock, s.acceptClockSkewLimits, time.UnixMilli(int64(request.TimeMs)))
+	if err != nil {
+		return
+	}
+
 	currentSecret := ""
 	if isNewConnectionSecretOrPublic {
 		currentSecret, err = s.secretIdGeneratorI.NewSecretId(ctx)
@@ -238,7 +312,7 @@ func (s *assetTransferServiceGrpc) transferAssetOnSigraphAndUpdateAssetOfRequest
 	var selectedCandidate *model_asset_transfer.CandidateId
 	for i := range request.Candidates {
 		var newAsset, updatedAsset *model_sig_graph.Asset
-		updatedAsset, newAsset, err = s.sigGraphClientApi.TransferAsset(
+		updatedAsset, newAsset, transaction, err = s.sigGraphClientApi.TransferAssetAsync(
 			ctx,
 			request.TimeMs,
 			&request.Asset,
@@ -247,11 +321,12 @@ func (s *assetTransferServiceGrpc) transferAssetOnSigraphAndUpdateAssetOfRequest
 			request.Candidates[i].Secret,
 			currentSecret,
 			request.Candidates[i].Signature,
+			request.Candidates[i].SignatureScheme,
 		)
 
 		if err != nil {
 			// if already exists, use another candidate
-			if err == utility.ErrAlreadyExists {
+			if errors.Is(err, utility.ErrAlreadyExists) {
 				continue
 			}
 			return
@@ -262,6 +337,10 @@ func (s *assetTransferServiceGrpc) transferAssetOnSigraphAndUpdateAssetOfRequest
 		request.Asset = *updatedAsset
 		break
 	}
+	if selectedCandidate == nil {
+		err = fmt.Errorf("%w: no usable candidate id", utility.ErrAlreadyExists)
+		return
+	}
 	newSecret = selectedCandidate.Secret
 	oldSecret = currentSecret
 	return
@@ -3,6 +3,7 @@ package service_asset_transfer
 import (
 	"context"
 	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 	"time"
 )
@@ -37,5 +38,23 @@ type AssetTransferServiceI interface {
 		isNewConnectionSecretOrPublic bool,
 	) (updatedRequest *model_asset_transfer.RequestToAcceptAsset, newSecret string, oldSecret string, err error)
 
+	// the transfer step of AcceptRequestToAcceptAsset. It returns once the
+	// transfer is endorsed, SendAcceptAsset should be called after it is
+	// committed
+	TransferAcceptedAsset(
+		ctx context.Context,
+		request *model_asset_transfer.RequestToAcceptAsset,
+		isNewConnectionSecretOrPublic bool,
+	) (updatedRequest *model_asset_transfer.RequestToAcceptAsset, newSecret string, oldSecret string, transaction api_sig_graph.LedgerTransactionI, err error)
+
+	// inform the sender whether the request is accepted
+	SendAcceptAsset(
+		ctx context.Context,
+		peer *model_asset_transfer.Peer,
+		request *model_asset_transfer.RequestToAcceptAsset,
+		acceptOrReject bool,
+		message string,
+	) error
+
 	SetNumberOfCandidatesSignature(ctx context.Context, numberOfCandidate uint32) error
 }
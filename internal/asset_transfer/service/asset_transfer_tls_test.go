@@ -0,0 +1,275 @@
+package service_asset_transfer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	utility_asset_transfer "sig_graph_scp/internal/asset_transfer/utility"
+	sig_graph_grpc "sig_graph_scp/internal/grpc"
+	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// CA issuing the client certificates and the certificate of a server on
+// 127.0.0.1
+func newTlsTestOptions(t *testing.T) *utility.GrpcTlsOptions {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sgp test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCertificate, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "sgp test server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	serverDer, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCertificate, &serverKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCertificates := x509.NewCertPool()
+	caCertificates.AddCert(caCertificate)
+	return &utility.GrpcTlsOptions{
+		CaCertificates: caCertificates,
+		ServerCertificate: tls.Certificate{
+			Certificate: [][]byte{serverDer, caDer},
+			PrivateKey:  serverKey,
+		},
+		ClientCertificateIssuer: &utility.GrpcClientCertificateIssuer{
+			Certificate: caCertificate,
+			Key:         caKey,
+			Clock:       utility.NewClockWall(),
+		},
+	}
+}
+
+// counts the answers passed on by the server
+type countingAssetAcceptHandler struct {
+	count int
+}
+
+func (h *countingAssetAcceptHandler) HandleAssetAccept(
+	ctx context.Context,
+	ackId string,
+	isAcceptedOrRejected bool,
+	message string,
+) {
+	h.count++
+}
+
+// every outbound request was sent to the peer of peerPublicKey
+type staticOutboundRequestPeerResolver struct {
+	peerPublicKey string
+}
+
+func (r *staticOutboundRequestPeerResolver) GetPeerPublicKeyByAckId(ctx context.Context, ackId string) (string, error) {
+	return r.peerPublicKey, nil
+}
+
+// address of a gRPC server requiring client certificates of tlsOptions
+func startTlsTestServer(
+	t *testing.T,
+	tlsOptions *utility.GrpcTlsOptions,
+	handler AssetTransferHandlerI,
+	acceptHandler AssetAcceptHandlerI,
+	outboundRequestPeers OutboundRequestPeerResolverI,
+) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewAssetTransferServerGrpc(
+		handler,
+		acceptHandler,
+		listener.Addr().String(),
+		utility.NewHashedIdGeneratorService(),
+		utility.NewClockWall(),
+		&clockSkewTestLimits,
+		service_sig_graph.NewNodeIdValidator([]string{clockSkewTestGraphName}, []model.ENodeIdFormat{model.ENodeIdFormatUuidV4}),
+		tlsOptions,
+		outboundRequestPeers,
+	)
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsOptions.ServerConfig())))
+	sig_graph_grpc.RegisterTransferAssetServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	return listener.Addr().String()
+}
+
+// client presenting a certificate of keyPair, or none if keyPair is nil
+func newTlsTestClient(t *testing.T, address string, tlsOptions *utility.GrpcTlsOptions, keyPair *model_sig_graph.UserKeyPair) sig_graph_grpc.TransferAssetClient {
+	t.Helper()
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    tlsOptions.CaCertificates,
+	}
+	if keyPair != nil {
+		var err error
+		config, err = tlsOptions.ClientConfig(keyPair.Public, keyPair.Private)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return sig_graph_grpc.NewTransferAssetClient(conn)
+}
+
+func TestRequestToAcceptAssetTlsOwner(t *testing.T) {
+	owner := newClockSkewTestKeyPair(t)
+	other := newClockSkewTestKeyPair(t)
+	recipient := newClockSkewTestKeyPair(t)
+	tlsOptions := newTlsTestOptions(t)
+
+	cases := []struct {
+		name       string
+		clientKey  *model_sig_graph.UserKeyPair
+		isAccepted bool
+	}{
+		{"certificate of the owner", owner, true},
+		{"certificate of another key", other, false},
+		{"no client certificate", nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			handler := &countingAssetTransferHandler{}
+			address := startTlsTestServer(t, tlsOptions, handler, nil, nil)
+			client := newTlsTestClient(t, address, tlsOptions, c.clientKey)
+			asset := newClockSkewTestAsset(t, owner)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			response, err := client.RequestToAcceptAsset(ctx, &sig_graph_grpc.RequestToAcceptAssetRequest{
+				TimeMs:            uint64(time.Now().UnixMilli()),
+				AssetId:           asset.Id,
+				OwnerPublicKey:    owner.Public,
+				NewOwnerPublicKey: recipient.Public,
+			})
+
+			if c.isAccepted {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if response.Error != nil {
+					t.Fatalf("expected the request to be handled, got %v", utility_asset_transfer.WrapGrpcError(response.Error))
+				}
+				if handler.count != 1 {
+					t.Fatalf("expected the request to be handled once, got %d times", handler.count)
+				}
+				return
+			}
+
+			// without a client certificate the handshake fails
+			if err == nil && !errors.Is(utility_asset_transfer.WrapGrpcError(response.Error), utility.ErrPermissionDenied) {
+				t.Fatalf("expected ErrPermissionDenied, got %v", utility_asset_transfer.WrapGrpcError(response.Error))
+			}
+			if handler.count != 0 {
+				t.Fatal("expected the request not to be handled")
+			}
+		})
+	}
+}
+
+func TestAcceptAssetTlsAcceptingPeer(t *testing.T) {
+	recipient := newClockSkewTestKeyPair(t)
+	other := newClockSkewTestKeyPair(t)
+	tlsOptions := newTlsTestOptions(t)
+
+	cases := []struct {
+		name       string
+		clientKey  *model_sig_graph.UserKeyPair
+		isAccepted bool
+	}{
+		{"certificate of the peer of the request", recipient, true},
+		{"certificate of another key", other, false},
+		{"no client certificate", nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			acceptHandler := &countingAssetAcceptHandler{}
+			address := startTlsTestServer(t, tlsOptions, nil, acceptHandler, &staticOutboundRequestPeerResolver{peerPublicKey: recipient.Public})
+			client := newTlsTestClient(t, address, tlsOptions, c.clientKey)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			response, err := client.AcceptAsset(ctx, &sig_graph_grpc.AcceptAssetRequest{
+				AckId:    "ack",
+				Accepted: true,
+			})
+
+			if c.isAccepted {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if response.Error != nil {
+					t.Fatalf("expected the answer to be handled, got %v", utility_asset_transfer.WrapGrpcError(response.Error))
+				}
+				if acceptHandler.count != 1 {
+					t.Fatalf("expected the answer to be handled once, got %d times", acceptHandler.count)
+				}
+				return
+			}
+
+			// without a client certificate the handshake fails
+			if err == nil && !errors.Is(utility_asset_transfer.WrapGrpcError(response.Error), utility.ErrPermissionDenied) {
+				t.Fatalf("expected ErrPermissionDenied, got %v", utility_asset_transfer.WrapGrpcError(response.Error))
+			}
+			if acceptHandler.count != 0 {
+				t.Fatal("expected the answer not to be handled")
+			}
+		})
+	}
+}
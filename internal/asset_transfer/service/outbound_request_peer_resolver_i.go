@@ -0,0 +1,9 @@
+package service_asset_transfer
+
+import "context"
+
+// peers our requests were sent to, only they may accept or reject them
+type OutboundRequestPeerResolverI interface {
+	// pem public key of the peer the outbound request of ackId was sent to
+	GetPeerPublicKeyByAckId(ctx context.Context, ackId string) (string, error)
+}
@@ -0,0 +1,62 @@
+package service_asset_transfer
+
+import (
+	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	"sig_graph_scp/pkg/model"
+)
+
+// tells request signatures apart from node signatures of the same key
+const requestToAcceptAssetPurpose = "sgp-request-to-accept-asset"
+
+// what the owner of the asset signs in a request to accept it, signed and
+// verified like a node according to its signature scheme
+type requestToAcceptAssetSigningPayload struct {
+	Purpose           string                                       `json:"purpose"`
+	TimeMs            uint64                                       `json:"time_ms"`
+	AssetId           string                                       `json:"asset_id"`
+	OwnerPublicKey    string                                       `json:"owner_public_key"`
+	NewOwnerPublicKey string                                       `json:"new_owner_public_key"`
+	Candidates        []model_asset_transfer.CandidateId           `json:"candidates"`
+	SecretIds         map[string]requestToAcceptAssetSigningSecret `json:"secret_ids"`
+	SignatureScheme   model.ESignatureScheme                       `json:"signature_scheme"`
+	Signature         string                                       `json:"signature"`
+}
+
+// the exposed secret id as sent, without the hashes computed by the recipient
+type requestToAcceptAssetSigningSecret struct {
+	ThisId      string `json:"this_id"`
+	ThisSecret  string `json:"this_secret"`
+	OtherId     string `json:"other_id"`
+	OtherSecret string `json:"other_secret"`
+}
+
+func newRequestToAcceptAssetSigningPayload(
+	timeMs uint64,
+	assetId string,
+	ownerPublicKey string,
+	newOwnerPublicKey string,
+	candidates []model_asset_transfer.CandidateId,
+	secretIds map[string]model_asset_transfer.PrivateId,
+	signatureScheme model.ESignatureScheme,
+) *requestToAcceptAssetSigningPayload {
+	payload := &requestToAcceptAssetSigningPayload{
+		Purpose:           requestToAcceptAssetPurpose,
+		TimeMs:            timeMs,
+		AssetId:           assetId,
+		OwnerPublicKey:    ownerPublicKey,
+		NewOwnerPublicKey: newOwnerPublicKey,
+		// the same json for no candidates whether they are nil or empty
+		Candidates:      append([]model_asset_transfer.CandidateId{}, candidates...),
+		SecretIds:       map[string]requestToAcceptAssetSigningSecret{},
+		SignatureScheme: signatureScheme,
+	}
+	for hash, id := range secretIds {
+		payload.SecretIds[hash] = requestToAcceptAssetSigningSecret{
+			ThisId:      id.ThisId,
+			ThisSecret:  id.ThisSecret,
+			OtherId:     id.OtherId,
+			OtherSecret: id.OtherSecret,
+		}
+	}
+	return payload
+}
@@ -21,6 +21,12 @@ func WrapGrpcError(err *sig_graph_grpc.Error) error {
 		return fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.ErrorMessage)
 	case sig_graph_grpc.ErrorCode_NOT_FOUND:
 		return fmt.Errorf("%w: %s", utility.ErrNotFound, err.ErrorMessage)
+	case sig_graph_grpc.ErrorCode_CLOCK_SKEW:
+		return fmt.Errorf("%w: %s", utility.ErrClockSkew, err.ErrorMessage)
+	case sig_graph_grpc.ErrorCode_PERMISSION_DENIED:
+		return fmt.Errorf("%w: %s", utility.ErrPermissionDenied, err.ErrorMessage)
+	case sig_graph_grpc.ErrorCode_INVALID_SIGNATURE:
+		return fmt.Errorf("%w: %s", utility.ErrInvalidSignature, err.ErrorMessage)
 	case sig_graph_grpc.ErrorCode_GENERAL_ERROR:
 		return fmt.Errorf("%w: %s", ErrPeerGeneralError, err.ErrorMessage)
 
@@ -31,27 +37,43 @@ func WrapGrpcError(err *sig_graph_grpc.Error) error {
 }
 
 func ToGrpcError(err error) *sig_graph_grpc.Error {
-	switch err {
-	case nil:
+	switch {
+	case err == nil:
 		return &sig_graph_grpc.Error{
 			Code:         sig_graph_grpc.ErrorCode_SUCCESS,
 			ErrorMessage: "success",
 		}
-	case utility.ErrNotFound:
+	case errors.Is(err, utility.ErrNotFound):
 		return &sig_graph_grpc.Error{
 			Code:         sig_graph_grpc.ErrorCode_NOT_FOUND,
 			ErrorMessage: err.Error(),
 		}
-	case utility.ErrAlreadyExists:
+	case errors.Is(err, utility.ErrAlreadyExists):
 		return &sig_graph_grpc.Error{
 			Code:         sig_graph_grpc.ErrorCode_ALREADY_EXISTS,
 			ErrorMessage: err.Error(),
 		}
-	case utility.ErrInvalidArgument:
+	// before ErrInvalidArgument, which it also matches
+	case errors.Is(err, utility.ErrClockSkew):
+		return &sig_graph_grpc.Error{
+			Code:         sig_graph_grpc.ErrorCode_CLOCK_SKEW,
+			ErrorMessage: err.Error(),
+		}
+	case errors.Is(err, utility.ErrInvalidArgument):
 		return &sig_graph_grpc.Error{
 			Code:         sig_graph_grpc.ErrorCode_INVALID_ARGUMENT,
 			ErrorMessage: err.Error(),
 		}
+	case errors.Is(err, utility.ErrPermissionDenied):
+		return &sig_graph_grpc.Error{
+			Code:         sig_graph_grpc.ErrorCode_PERMISSION_DENIED,
+			ErrorMessage: err.Error(),
+		}
+	case errors.Is(err, utility.ErrInvalidSignature):
+		return &sig_graph_grpc.Error{
+			Code:         sig_graph_grpc.ErrorCode_INVALID_SIGNATURE,
+			ErrorMessage: err.Error(),
+		}
 	default:
 		return &sig_graph_grpc.Error{
 			Code:         sig_graph_grpc.ErrorCode_GENERAL_ERROR,
@@ -23,11 +23,14 @@ const (
 type ErrorCode int32
 
 const (
-	ErrorCode_SUCCESS          ErrorCode = 0
-	ErrorCode_NOT_FOUND        ErrorCode = 1
-	ErrorCode_INVALID_ARGUMENT ErrorCode = 2
-	ErrorCode_ALREADY_EXISTS   ErrorCode = 3
-	ErrorCode_GENERAL_ERROR    ErrorCode = 4
+	ErrorCode_SUCCESS           ErrorCode = 0
+	ErrorCode_NOT_FOUND         ErrorCode = 1
+	ErrorCode_INVALID_ARGUMENT  ErrorCode = 2
+	ErrorCode_ALREADY_EXISTS    ErrorCode = 3
+	ErrorCode_GENERAL_ERROR     ErrorCode = 4
+	ErrorCode_CLOCK_SKEW        ErrorCode = 5
+	ErrorCode_PERMISSION_DENIED ErrorCode = 6
+	ErrorCode_INVALID_SIGNATURE ErrorCode = 7
 )
 
 // Enum value maps for ErrorCode.
@@ -38,13 +41,19 @@ var (
 		2: "INVALID_ARGUMENT",
 		3: "ALREADY_EXISTS",
 		4: "GENERAL_ERROR",
+		5: "CLOCK_SKEW",
+		6: "PERMISSION_DENIED",
+		7: "INVALID_SIGNATURE",
 	}
 	ErrorCode_value = map[string]int32{
-		"SUCCESS":          0,
-		"NOT_FOUND":        1,
-		"INVALID_ARGUMENT": 2,
-		"ALREADY_EXISTS":   3,
-		"GENERAL_ERROR":    4,
+		"SUCCESS":           0,
+		"NOT_FOUND":         1,
+		"INVALID_ARGUMENT":  2,
+		"ALREADY_EXISTS":    3,
+		"GENERAL_ERROR":     4,
+		"CLOCK_SKEW":        5,
+		"PERMISSION_DENIED": 6,
+		"INVALID_SIGNATURE": 7,
 	}
 )
 
@@ -140,15 +149,19 @@ var file_error_proto_rawDesc = []byte{
 	0x5f, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x52,
 	0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d,
 	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2a, 0x64, 0x0a, 0x09, 0x45, 0x72,
-	0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x55, 0x43, 0x43, 0x45,
-	0x53, 0x53, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e,
-	0x44, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x41,
-	0x52, 0x47, 0x55, 0x4d, 0x45, 0x4e, 0x54, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x4c, 0x52,
-	0x45, 0x41, 0x44, 0x59, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0x03, 0x12, 0x11, 0x0a,
-	0x0d, 0x47, 0x45, 0x4e, 0x45, 0x52, 0x41, 0x4c, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x04,
-	0x42, 0x12, 0x5a, 0x10, 0x2e, 0x2f, 0x73, 0x69, 0x67, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f,
-	0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2a, 0xa2, 0x01, 0x0a, 0x09, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x55, 0x43, 0x43,
+	0x45, 0x53, 0x53, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55,
+	0x4e, 0x44, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f,
+	0x41, 0x52, 0x47, 0x55, 0x4d, 0x45, 0x4e, 0x54, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x4c,
+	0x52, 0x45, 0x41, 0x44, 0x59, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0x03, 0x12, 0x11,
+	0x0a, 0x0d, 0x47, 0x45, 0x4e, 0x45, 0x52, 0x41, 0x4c, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10,
+	0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x53, 0x4b, 0x45, 0x57, 0x10,
+	0x05, 0x12, 0x15, 0x0a, 0x11, 0x50, 0x45, 0x52, 0x4d, 0x49, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f,
+	0x44, 0x45, 0x4e, 0x49, 0x45, 0x44, 0x10, 0x06, 0x12, 0x15, 0x0a, 0x11, 0x49, 0x4e, 0x56, 0x41,
+	0x4c, 0x49, 0x44, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x41, 0x54, 0x55, 0x52, 0x45, 0x10, 0x07, 0x42,
+	0x12, 0x5a, 0x10, 0x2e, 0x2f, 0x73, 0x69, 0x67, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f, 0x67,
+	0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -3,6 +3,7 @@ package service_sig_graph
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sig_graph_scp/pkg/model"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 	"sig_graph_scp/pkg/utility"
@@ -17,8 +18,11 @@ type assetService struct {
 	signingService       NodeSigningServiceI
 	hashGeneratorService utility.HashedIdGeneratorServiceI
 	cloner               utility.ClonerI
+	unitRegistry         UnitRegistryI
+	allowUnknownUnits    bool
 }
 
+// unknown units are rejected with ErrInvalidArgument unless allowUnknownUnits
 func NewAssetService(
 	smartContractService SmartContractServiceI,
 	clock utility.ClockI,
@@ -26,6 +30,8 @@ func NewAssetService(
 	signingService NodeSigningServiceI,
 	hashGeneratorService utility.HashedIdGeneratorServiceI,
 	cloner utility.ClonerI,
+	unitRegistry UnitRegistryI,
+	allowUnknownUnits bool,
 ) AssetServiceI {
 	return &assetService{
 		smartContractService: smartContractService,
@@ -34,9 +40,20 @@ func NewAssetService(
 		signingService:       signingService,
 		hashGeneratorService: hashGeneratorService,
 		cloner:               cloner,
+		unitRegistry:         unitRegistry,
+		allowUnknownUnits:    allowUnknownUnits,
 	}
 }
 
+// symbol that assets of unit are stored with
+func (s *assetService) normalizeUnit(unit string) (string, error) {
+	normalized, err := s.unitRegistry.Normalize(unit)
+	if err != nil && s.allowUnknownUnits {
+		return unit, nil
+	}
+	return normalized, err
+}
+
 type createAssetRequest struct {
 	Time                 uint64   `json:"time"`
 	Id                   string   `json:"id"`
@@ -44,6 +61,7 @@ type createAssetRequest struct {
 	Quantity             string   `json:"quantity"`
 	Unit                 string   `json:"unit"`
 	Signature            string   `json:"signature"`
+	SignatureScheme      string   `json:"signature_scheme,omitempty"` // also used by ingredient signatures
 	OwnerPublicKey       string   `json:"owner_public_key"`
 	IngredientIds        []string `json:"ingredient_ids"`
 	IngredientSecretIds  []string `json:"ingredient_secret_ids"`
@@ -62,6 +80,187 @@ func (s *assetService) CreateAsset(
 	secretIds []string,
 	ingredientSignatures []string,
 ) (*model_sig_graph.Asset, error) {
+	asset, transaction, err := s.CreateAssetAsync(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		ownerKey,
+		ingredients,
+		ingredientSecretIds,
+		secretIds,
+		ingredientSignatures,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = transaction.WaitForCommit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+func (s *assetService) CreateAssetAsync(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientSecretIds []string,
+	secretIds []string,
+	ingredientSignatures []string,
+) (*model_sig_graph.Asset, LedgerTransactionI, error) {
+	return s.createAssetAsync(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		ownerKey,
+		ingredients,
+		ingredientSecretIds,
+		secretIds,
+		func(asset *model_sig_graph.Asset) ([]string, error) {
+			return ingredientSignatures, nil
+		},
+	)
+}
+
+func (s *assetService) ManufactureAsset(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientKeys []*model_sig_graph.UserKeyPair,
+	ingredientSecretIds []string,
+	secretIds []string,
+) (*model_sig_graph.Asset, []model_sig_graph.Asset, error) {
+	asset, updatedIngredients, transaction, err := s.ManufactureAssetAsync(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		ownerKey,
+		ingredients,
+		ingredientKeys,
+		ingredientSecretIds,
+		secretIds,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = transaction.WaitForCommit(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return asset, updatedIngredients, nil
+}
+
+func (s *assetService) ManufactureAssetAsync(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientKeys []*model_sig_graph.UserKeyPair,
+	ingredientSecretIds []string,
+	secretIds []string,
+) (*model_sig_graph.Asset, []model_sig_graph.Asset, LedgerTransactionI, error) {
+	if len(ingredientKeys) != len(ingredients) || len(secretIds) != len(ingredients) {
+		return nil, nil, nil, fmt.Errorf("%w: mismatch ingredient keys or secret ids length", utility.ErrInvalidArgument)
+	}
+
+	for i := range ingredients {
+		if ingredients[i].OwnerPublicKey != ingredientKeys[i].Public {
+			return nil, nil, nil, fmt.Errorf("%w: ingredient %s is not owned by its key", utility.ErrPermissionDenied, ingredients[i].Id)
+		}
+		if ingredients[i].IsFinalized {
+			return nil, nil, nil, fmt.Errorf("%w: ingredient %s", utility.ErrAlreadyFinalized, ingredients[i].Id)
+		}
+	}
+
+	// the ingredients are signed as the smart contract finalizes them,
+	// with an edge toward the new asset
+	updatedIngredients := make([]model_sig_graph.Asset, len(ingredients))
+	signIngredients := func(asset *model_sig_graph.Asset) ([]string, error) {
+		signatures := make([]string, 0, len(ingredients))
+		for i := range ingredients {
+			updatedIngredient := &updatedIngredients[i]
+			err := s.cloner.Clone(ctx, &ingredients[i], updatedIngredient)
+			if err != nil {
+				return nil, err
+			}
+
+			if secretIds[i] != "" {
+				hash, err := s.hashGeneratorService.GenerateHashedId(ctx, asset.Id, secretIds[i])
+				if err != nil {
+					return nil, err
+				}
+				updatedIngredient.PrivateChildrenHashedIds[hash] = true
+			} else {
+				updatedIngredient.PublicChildrenIds[asset.Id] = true
+			}
+			updatedIngredient.IsFinalized = true
+			updatedIngredient.UpdatedTime = asset.CreatedTime
+			updatedIngredient.SignatureScheme = asset.SignatureScheme
+
+			signature, err := s.signingService.Sign(ctx, ingredientKeys[i], updatedIngredient)
+			if err != nil {
+				return nil, err
+			}
+			updatedIngredient.Signature = signature
+			signatures = append(signatures, signature)
+		}
+		return signatures, nil
+	}
+
+	asset, transaction, err := s.createAssetAsync(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		ownerKey,
+		ingredients,
+		ingredientSecretIds,
+		secretIds,
+		signIngredients,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return asset, updatedIngredients, transaction, nil
+}
+
+// signIngredients returns the signatures of the finalized ingredients,
+// it is called once the new asset is built
+func (s *assetService) createAssetAsync(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientSecretIds []string,
+	secretIds []string,
+	signIngredients func(asset *model_sig_graph.Asset) ([]string, error),
+) (*model_sig_graph.Asset, LedgerTransactionI, error) {
+	unit, err := s.normalizeUnit(unit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !quantity.IsPositive() {
+		return nil, nil, fmt.Errorf("%w: invalid quantity %s", utility.ErrInvalidArgument, quantity)
+	}
+
 	ingredientIds := []string{}
 	for i := range ingredients {
 		ingredientIds = append(ingredientIds, string(ingredients[i].Id))
@@ -71,7 +270,7 @@ func (s *assetService) CreateAsset(
 	time_ms := time.UnixMilli()
 	id, err := s.idGenerateService.NewFullId(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// generate signature
@@ -90,10 +289,34 @@ func (s *assetService) CreateAsset(
 		quantity,
 		materialName,
 	)
+	asset.SignatureScheme = s.signingService.Scheme()
+
+	// the smart contract adds the parent edges, they are signed as well so
+	// that the signature covers the asset as stored. Legacy signatures did
+	// not cover them, see nodeVerifyingPayload
+	if len(ingredientSecretIds) != len(ingredients) {
+		return nil, nil, fmt.Errorf("%w: mismatch ingredient secret ids length", utility.ErrInvalidArgument)
+	}
+	for i := range ingredients {
+		if ingredientSecretIds[i] != "" {
+			hash, err := s.hashGeneratorService.GenerateHashedId(ctx, ingredients[i].Id, ingredientSecretIds[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			asset.PrivateParentsHashedIds[hash] = true
+		} else {
+			asset.PublicParentsIds[ingredients[i].Id] = true
+		}
+	}
 
 	signature, err := s.signingService.Sign(ctx, ownerKey, asset)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	ingredientSignatures, err := signIngredients(&asset)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	request := createAssetRequest{
@@ -103,6 +326,7 @@ func (s *assetService) CreateAsset(
 		Quantity:             quantity.String(),
 		Unit:                 unit,
 		Signature:            signature,
+		SignatureScheme:      asset.SignatureScheme,
 		OwnerPublicKey:       ownerKey.Public,
 		IngredientIds:        ingredientIds,
 		IngredientSecretIds:  ingredientSecretIds,
@@ -112,24 +336,24 @@ func (s *assetService) CreateAsset(
 
 	requestJson, err := json.Marshal(request)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	assetStr, err := s.smartContractService.CreateTransaction("CreateAsset", string(requestJson))
+	assetStr, transaction, err := s.smartContractService.SubmitTransaction(ctx, "CreateAsset", string(requestJson))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	assetSigGraph := model_sig_graph.Asset{}
 	err = json.Unmarshal([]byte(assetStr), &assetSigGraph)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return &assetSigGraph, nil
+	return &assetSigGraph, transaction, nil
 }
 
 func (s *assetService) GetAssetById(ctx context.Context, id string) (*model_sig_graph.Asset, error) {
-	data, err := s.smartContractService.Query("GetAsset", string(id))
+	data, err := s.smartContractService.Query(ctx, "GetAsset", string(id))
 	if err != nil {
 		return nil, err
 	}
@@ -146,13 +370,15 @@ func (s *assetService) GetAssetById(ctx context.Context, id string) (*model_sig_
 type transefrAssetRequest struct {
 	TimeMs uint64 `json:"time_ms"`
 
-	CurrentId        string `json:"current_id"`
-	CurrentSignature string `json:"current_signature"`
-	CurrentSecret    string `json:"current_secret"`
+	CurrentId              string `json:"current_id"`
+	CurrentSignature       string `json:"current_signature"`
+	CurrentSignatureScheme string `json:"current_signature_scheme,omitempty"`
+	CurrentSecret          string `json:"current_secret"`
 
-	NewId        string `json:"new_id"`
-	NewSignature string `json:"new_signature"`
-	NewSecret    string `json:"new_secret"`
+	NewId              string `json:"new_id"`
+	NewSignature       string `json:"new_signature"`
+	NewSignatureScheme string `json:"new_signature_scheme,omitempty"`
+	NewSecret          string `json:"new_secret"`
 
 	NewOwnerPublicKey string `json:"new_owner_public_key"`
 }
@@ -166,10 +392,51 @@ func (s *assetService) TransferAsset(
 	newSecret string,
 	currentSecret string,
 	currentSignature string,
+	currentSignatureScheme model.ESignatureScheme,
 ) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, err error) {
+	updatedCurrentAsset, newAsset, transaction, err := s.TransferAssetAsync(
+		ctx,
+		time_ms,
+		asset,
+		newOwnerKey,
+		newId,
+		newSecret,
+		currentSecret,
+		currentSignature,
+		currentSignatureScheme,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = transaction.WaitForCommit(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updatedCurrentAsset, newAsset, nil
+}
+
+func (s *assetService) TransferAssetAsync(
+	ctx context.Context,
+	time_ms uint64,
+	asset *model_sig_graph.Asset,
+	newOwnerKey *model_sig_graph.UserKeyPair,
+	newId string,
+	newSecret string,
+	currentSecret string,
+	currentSignature string,
+	currentSignatureScheme model.ESignatureScheme,
+) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, transaction LedgerTransactionI, err error) {
 	currentHash := ""
 	newHash := ""
 
+	// the ledger copies the unit of the current asset, whatever it is
+	if !asset.Quantity.IsPositive() {
+		err = fmt.Errorf("%w: invalid quantity %s", utility.ErrInvalidArgument, asset.Quantity)
+		return
+	}
+
 	updatedCurrentAsset = &model_sig_graph.Asset{}
 	err = s.cloner.Clone(ctx, asset, updatedCurrentAsset)
 	if err != nil {
@@ -185,6 +452,10 @@ func (s *assetService) TransferAsset(
 	} else {
 		updatedCurrentAsset.PublicChildrenIds[newId] = true
 	}
+	updatedCurrentAsset.IsFinalized = true
+	updatedCurrentAsset.UpdatedTime = time_ms
+	updatedCurrentAsset.Signature = currentSignature
+	updatedCurrentAsset.SignatureScheme = currentSignatureScheme
 
 	newAsset = &model_sig_graph.Asset{}
 	err = s.cloner.Clone(ctx, asset, newAsset)
@@ -198,6 +469,8 @@ func (s *assetService) TransferAsset(
 	newAsset.ClearEdges()
 
 	newAsset.OwnerPublicKey = newOwnerKey.Public
+	newAsset.Signature = ""
+	newAsset.SignatureScheme = s.signingService.Scheme()
 	if currentSecret != "" {
 		currentHash, err = s.hashGeneratorService.GenerateHashedId(ctx, updatedCurrentAsset.Id, currentSecret)
 		if err != nil {
@@ -219,14 +492,16 @@ func (s *assetService) TransferAsset(
 	}
 
 	request := transefrAssetRequest{
-		TimeMs:           time_ms,
-		CurrentId:        updatedCurrentAsset.Id,
-		CurrentSignature: currentSignature,
-		CurrentSecret:    currentSecret,
+		TimeMs:                 time_ms,
+		CurrentId:              updatedCurrentAsset.Id,
+		CurrentSignature:       currentSignature,
+		CurrentSignatureScheme: currentSignatureScheme,
+		CurrentSecret:          currentSecret,
 
-		NewId:        newId,
-		NewSignature: signature,
-		NewSecret:    newSecret,
+		NewId:              newId,
+		NewSignature:       signature,
+		NewSignatureScheme: newAsset.SignatureScheme,
+		NewSecret:          newSecret,
 
 		NewOwnerPublicKey: newOwnerKey.Public,
 	}
@@ -236,7 +511,7 @@ func (s *assetService) TransferAsset(
 		return
 	}
 
-	assetStr, err := s.smartContractService.CreateTransaction("TransferAsset", string(requestJson))
+	assetStr, transaction, err := s.smartContractService.SubmitTransaction(ctx, "TransferAsset", string(requestJson))
 	if err != nil {
 		return
 	}
@@ -248,3 +523,200 @@ func (s *assetService) TransferAsset(
 
 	return
 }
+
+type splitAssetChildRequest struct {
+	Id              string `json:"id"`
+	Quantity        string `json:"quantity"`
+	Signature       string `json:"signature"`
+	SignatureScheme string `json:"signature_scheme,omitempty"`
+	// hides the child in the parent
+	Secret string `json:"secret"`
+	// hides the parent in the child
+	ParentSecret string `json:"parent_secret"`
+}
+
+type splitAssetRequest struct {
+	TimeMs uint64 `json:"time_ms"`
+
+	ParentId              string `json:"parent_id"`
+	ParentSignature       string `json:"parent_signature"`
+	ParentSignatureScheme string `json:"parent_signature_scheme,omitempty"`
+
+	Children []splitAssetChildRequest `json:"children"`
+}
+
+func (s *assetService) SplitAsset(
+	ctx context.Context,
+	asset *model_sig_graph.Asset,
+	ownerKey *model_sig_graph.UserKeyPair,
+	quantities []decimal.Decimal,
+	childrenSecretIds []string,
+	parentSecretIds []string,
+) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, err error) {
+	updatedAsset, children, transaction, err := s.SplitAssetAsync(
+		ctx,
+		asset,
+		ownerKey,
+		quantities,
+		childrenSecretIds,
+		parentSecretIds,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = transaction.WaitForCommit(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updatedAsset, children, nil
+}
+
+func (s *assetService) SplitAssetAsync(
+	ctx context.Context,
+	asset *model_sig_graph.Asset,
+	ownerKey *model_sig_graph.UserKeyPair,
+	quantities []decimal.Decimal,
+	childrenSecretIds []string,
+	parentSecretIds []string,
+) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, transaction LedgerTransactionI, err error) {
+	if len(quantities) < 2 {
+		err = fmt.Errorf("%w: an asset is split into at least 2 children", utility.ErrInvalidArgument)
+		return
+	}
+	if len(childrenSecretIds) != len(quantities) || len(parentSecretIds) != len(quantities) {
+		err = fmt.Errorf("%w: mismatch secret ids length", utility.ErrInvalidArgument)
+		return
+	}
+	if asset.OwnerPublicKey != ownerKey.Public {
+		err = fmt.Errorf("%w: asset %s is not owned by the key", utility.ErrPermissionDenied, asset.Id)
+		return
+	}
+	if asset.IsFinalized {
+		err = fmt.Errorf("%w: asset %s", utility.ErrAlreadyFinalized, asset.Id)
+		return
+	}
+	// the ledger copies the unit of the parent to the children, whatever it is
+	total := decimal.Zero
+	for i := range quantities {
+		if !quantities[i].IsPositive() {
+			err = fmt.Errorf("%w: invalid quantity %s", utility.ErrInvalidArgument, quantities[i])
+			return
+		}
+		total = total.Add(quantities[i])
+	}
+	if !total.Equal(asset.Quantity) {
+		err = fmt.Errorf("%w: quantities add up to %s instead of %s", utility.ErrInvalidArgument, total, asset.Quantity)
+		return
+	}
+
+	time_ms := uint64(s.clock.Now().UnixMilli())
+
+	updatedAsset = &model_sig_graph.Asset{}
+	err = s.cloner.Clone(ctx, asset, updatedAsset)
+	if err != nil {
+		return
+	}
+	updatedAsset.NodeType = model.ENodeTypeAsset
+	updatedAsset.IsFinalized = true
+	updatedAsset.UpdatedTime = time_ms
+	updatedAsset.SignatureScheme = s.signingService.Scheme()
+
+	parentHashes := make([]string, len(quantities))
+	for i := range parentSecretIds {
+		if parentSecretIds[i] == "" {
+			continue
+		}
+		parentHashes[i], err = s.hashGeneratorService.GenerateHashedId(ctx, asset.Id, parentSecretIds[i])
+		if err != nil {
+			return
+		}
+	}
+
+	request := splitAssetRequest{
+		TimeMs:   time_ms,
+		ParentId: asset.Id,
+	}
+	for i := range quantities {
+		var id string
+		id, err = s.idGenerateService.NewFullId(ctx)
+		if err != nil {
+			return
+		}
+
+		node := model_sig_graph.NewDefaultNode(
+			id,
+			model.ENodeTypeAsset,
+			time_ms,
+			time_ms,
+			"",
+			ownerKey.Public,
+		)
+		child := model_sig_graph.NewAsset(
+			node,
+			model.ECreationProcessSplit,
+			asset.Unit,
+			quantities[i],
+			asset.MaterialName,
+		)
+		child.SignatureScheme = s.signingService.Scheme()
+
+		if parentHashes[i] != "" {
+			child.PrivateParentsHashedIds[parentHashes[i]] = true
+		} else {
+			child.PublicParentsIds[asset.Id] = true
+		}
+
+		if childrenSecretIds[i] != "" {
+			var hash string
+			hash, err = s.hashGeneratorService.GenerateHashedId(ctx, id, childrenSecretIds[i])
+			if err != nil {
+				return
+			}
+			updatedAsset.PrivateChildrenHashedIds[hash] = true
+		} else {
+			updatedAsset.PublicChildrenIds[id] = true
+		}
+
+		var signature string
+		signature, err = s.signingService.Sign(ctx, ownerKey, child)
+		if err != nil {
+			return
+		}
+
+		request.Children = append(request.Children, splitAssetChildRequest{
+			Id:              id,
+			Quantity:        quantities[i].String(),
+			Signature:       signature,
+			SignatureScheme: child.SignatureScheme,
+			Secret:          childrenSecretIds[i],
+			ParentSecret:    parentSecretIds[i],
+		})
+	}
+
+	// the parent is signed once all children edges are added
+	updatedAsset.Signature, err = s.signingService.Sign(ctx, ownerKey, updatedAsset)
+	if err != nil {
+		return
+	}
+	request.ParentSignature = updatedAsset.Signature
+	request.ParentSignatureScheme = updatedAsset.SignatureScheme
+
+	requestJson, err := json.Marshal(request)
+	if err != nil {
+		return
+	}
+
+	childrenStr, transaction, err := s.smartContractService.SubmitTransaction(ctx, "SplitAsset", string(requestJson))
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal([]byte(childrenStr), &children)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -2,6 +2,7 @@ package service_sig_graph
 
 import (
 	"context"
+	"sig_graph_scp/pkg/model"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 
 	"github.com/shopspring/decimal"
@@ -19,6 +20,44 @@ type AssetServiceI interface {
 		secretIds []string,
 		ingredientSignatures []string,
 	) (*model_sig_graph.Asset, error)
+	// same as CreateAsset but return once the transaction is endorsed,
+	// the asset is on the ledger when the transaction is committed
+	CreateAssetAsync(
+		ctx context.Context,
+		materialName string,
+		unit string,
+		quantity decimal.Decimal,
+		ownerKey *model_sig_graph.UserKeyPair,
+		ingredients []model_sig_graph.Asset,
+		ingredientSecretIds []string,
+		secretIds []string,
+		ingredientSignatures []string,
+	) (*model_sig_graph.Asset, LedgerTransactionI, error)
+	// same as CreateAsset but the finalized ingredients are signed with
+	// ingredientKeys. Also return the ingredients as finalized by the
+	// transaction
+	ManufactureAsset(
+		ctx context.Context,
+		materialName string,
+		unit string,
+		quantity decimal.Decimal,
+		ownerKey *model_sig_graph.UserKeyPair,
+		ingredients []model_sig_graph.Asset,
+		ingredientKeys []*model_sig_graph.UserKeyPair,
+		ingredientSecretIds []string,
+		secretIds []string,
+	) (asset *model_sig_graph.Asset, updatedIngredients []model_sig_graph.Asset, err error)
+	ManufactureAssetAsync(
+		ctx context.Context,
+		materialName string,
+		unit string,
+		quantity decimal.Decimal,
+		ownerKey *model_sig_graph.UserKeyPair,
+		ingredients []model_sig_graph.Asset,
+		ingredientKeys []*model_sig_graph.UserKeyPair,
+		ingredientSecretIds []string,
+		secretIds []string,
+	) (asset *model_sig_graph.Asset, updatedIngredients []model_sig_graph.Asset, transaction LedgerTransactionI, err error)
 	GetAssetById(ctx context.Context, Id string) (*model_sig_graph.Asset, error)
 
 	TransferAsset(
@@ -30,5 +69,40 @@ type AssetServiceI interface {
 		newSecret string,
 		currentSecret string,
 		currentSignature string,
+		currentSignatureScheme model.ESignatureScheme,
 	) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, err error)
+
+	TransferAssetAsync(
+		ctx context.Context,
+		time_ms uint64,
+		asset *model_sig_graph.Asset,
+		newOwnerKey *model_sig_graph.UserKeyPair,
+		newId string,
+		newSecret string,
+		currentSecret string,
+		currentSignature string,
+		currentSignatureScheme model.ESignatureScheme,
+	) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, transaction LedgerTransactionI, err error)
+
+	// finalize asset and create one child per quantity, owned by ownerKey
+	// as well. The quantities must add up to the quantity of asset.
+	// childrenSecretIds hide the children in asset and parentSecretIds
+	// hide asset in each child, an empty secret makes a public edge
+	SplitAsset(
+		ctx context.Context,
+		asset *model_sig_graph.Asset,
+		ownerKey *model_sig_graph.UserKeyPair,
+		quantities []decimal.Decimal,
+		childrenSecretIds []string,
+		parentSecretIds []string,
+	) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, err error)
+
+	SplitAssetAsync(
+		ctx context.Context,
+		asset *model_sig_graph.Asset,
+		ownerKey *model_sig_graph.UserKeyPair,
+		quantities []decimal.Decimal,
+		childrenSecretIds []string,
+		parentSecretIds []string,
+	) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, transaction LedgerTransactionI, err error)
 }
@@ -0,0 +1,208 @@
+package service_sig_graph
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func newAssetServiceTestKeyPair(t *testing.T) *model_sig_graph.UserKeyPair {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &model_sig_graph.UserKeyPair{
+		Public:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+		Private: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDer})),
+	}
+}
+
+func newAssetServiceTest(
+	t *testing.T,
+	ledger *smartContractServiceMemory,
+	unitRegistry UnitRegistryI,
+	allowUnknownUnits bool,
+) AssetServiceI {
+	t.Helper()
+
+	return NewAssetService(
+		ledger,
+		utility.NewClockWall(),
+		NewIdGenerateServiceUuid("sgp://memory:[]:public"),
+		NewNodeSigningService(),
+		utility.NewHashedIdGeneratorService(),
+		utility.NewCloner(),
+		unitRegistry,
+		allowUnknownUnits,
+	)
+}
+
+// asset stored with unit by a service that did not know any unit, as the
+// assets created before the unit registry
+func newLegacyUnitAsset(
+	t *testing.T,
+	ledger *smartContractServiceMemory,
+	unit string,
+	ownerKey *model_sig_graph.UserKeyPair,
+) *model_sig_graph.Asset {
+	t.Helper()
+
+	legacyService := newAssetServiceTest(t, ledger, &unitRegistry{units: map[string]*unitDefinition{}}, true)
+	asset, err := legacyService.CreateAsset(
+		context.Background(),
+		"flour",
+		unit,
+		decimal.NewFromInt(10),
+		ownerKey,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.Unit != unit {
+		t.Fatalf("expected legacy unit %s, got %s", unit, asset.Unit)
+	}
+	return asset
+}
+
+// the asset on the ledger must have unit and verify against its signature
+func assertLedgerAsset(t *testing.T, service AssetServiceI, id string, unit string) {
+	t.Helper()
+
+	asset, err := service.GetAssetById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.Unit != unit {
+		t.Fatalf("expected unit %s on the ledger, got %s", unit, asset.Unit)
+	}
+	err = NewNodeVerifyingService().VerifyNode(context.Background(), asset)
+	if err != nil {
+		t.Fatalf("asset %s does not verify: %s", id, err)
+	}
+}
+
+func TestCreateAssetNormalizesUnit(t *testing.T) {
+	ledger := NewSmartContractServiceMemory(utility.NewHashedIdGeneratorService())
+	service := newAssetServiceTest(t, ledger, NewUnitRegistry(), false)
+
+	asset, err := service.CreateAsset(
+		context.Background(),
+		"flour",
+		"KG",
+		decimal.NewFromInt(10),
+		newAssetServiceTestKeyPair(t),
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.Unit != "kg" {
+		t.Fatalf("expected unit kg, got %s", asset.Unit)
+	}
+	assertLedgerAsset(t, service, asset.Id, "kg")
+
+	_, err = service.CreateAsset(
+		context.Background(),
+		"flour",
+		"crate",
+		decimal.NewFromInt(10),
+		newAssetServiceTestKeyPair(t),
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	if !errors.Is(err, utility.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for an unknown unit, got %v", err)
+	}
+}
+
+func TestTransferAssetKeepsLegacyUnit(t *testing.T) {
+	for _, unit := range []string{"KG", "crate"} {
+		t.Run(unit, func(t *testing.T) {
+			ledger := NewSmartContractServiceMemory(utility.NewHashedIdGeneratorService())
+			ownerKey := newAssetServiceTestKeyPair(t)
+			asset := newLegacyUnitAsset(t, ledger, unit, ownerKey)
+
+			service := newAssetServiceTest(t, ledger, NewUnitRegistry(), false)
+			_, newAsset, err := service.TransferAsset(
+				context.Background(),
+				asset.UpdatedTime+1,
+				asset,
+				newAssetServiceTestKeyPair(t),
+				"sgp://memory:[]:public:transferred",
+				"",
+				"",
+				"",
+				"",
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if newAsset.Unit != unit {
+				t.Fatalf("expected unit %s, got %s", unit, newAsset.Unit)
+			}
+			assertLedgerAsset(t, service, newAsset.Id, unit)
+		})
+	}
+}
+
+func TestSplitAssetKeepsLegacyUnit(t *testing.T) {
+	for _, unit := range []string{"KG", "crate"} {
+		t.Run(unit, func(t *testing.T) {
+			ledger := NewSmartContractServiceMemory(utility.NewHashedIdGeneratorService())
+			ownerKey := newAssetServiceTestKeyPair(t)
+			asset := newLegacyUnitAsset(t, ledger, unit, ownerKey)
+
+			service := newAssetServiceTest(t, ledger, NewUnitRegistry(), false)
+			updatedAsset, children, err := service.SplitAsset(
+				context.Background(),
+				asset,
+				ownerKey,
+				[]decimal.Decimal{decimal.NewFromInt(4), decimal.NewFromInt(6)},
+				[]string{"", ""},
+				[]string{"", ""},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertLedgerAsset(t, service, updatedAsset.Id, unit)
+			if len(children) != 2 {
+				t.Fatalf("expected 2 children, got %d", len(children))
+			}
+			for _, child := range children {
+				if child.Unit != unit {
+					t.Fatalf("expected unit %s, got %s", unit, child.Unit)
+				}
+				assertLedgerAsset(t, service, child.Id, unit)
+			}
+		})
+	}
+}
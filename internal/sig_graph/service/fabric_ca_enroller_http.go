@@ -0,0 +1,160 @@
+package service_sig_graph
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"strings"
+	"time"
+)
+
+// enroll through the REST api of a Hyperledger Fabric CA
+type fabricCaEnrollerHttp struct {
+	caUrl string
+	// empty for the default CA of the server
+	caName     string
+	mspId      string
+	httpClient *http.Client
+}
+
+// tlsCertificate is the CA certificate of the TLS server, the system
+// roots are used if it is nil
+func NewFabricCaEnrollerHttp(
+	caUrl string,
+	caName string,
+	mspId string,
+	tlsCertificate *x509.Certificate,
+) *fabricCaEnrollerHttp {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsCertificate != nil {
+		certPool := x509.NewCertPool()
+		certPool.AddCert(tlsCertificate)
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool}
+	}
+
+	return &fabricCaEnrollerHttp{
+		caUrl:  strings.TrimSuffix(caUrl, "/"),
+		caName: caName,
+		mspId:  mspId,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+	}
+}
+
+type fabricCaEnrollRequest struct {
+	CertificateRequest string `json:"certificate_request"`
+	CaName             string `json:"caname,omitempty"`
+}
+
+type fabricCaEnrollResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		// base64 of the pem encoded certificate
+		Cert string `json:"Cert"`
+	} `json:"result"`
+	Errors []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (e *fabricCaEnrollerHttp) Enroll(
+	ctx context.Context,
+	enrollmentId string,
+	enrollmentSecret string,
+) (*model_sig_graph.FabricIdentity, error) {
+	if enrollmentId == "" {
+		return nil, fmt.Errorf("%w: missing enrollment id", utility.ErrInvalidArgument)
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	certificateRequest, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: enrollmentId},
+	}, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	requestJson, err := json.Marshal(fabricCaEnrollRequest{
+		CertificateRequest: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: certificateRequest})),
+		CaName:             e.caName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.caUrl+"/api/v1/enroll", bytes.NewReader(requestJson))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.SetBasicAuth(enrollmentId, enrollmentSecret)
+
+	response, err := e.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: fabric ca: %s", utility.ErrTransient, err.Error())
+	}
+	defer response.Body.Close()
+
+	responseJson, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: fabric ca: %s", utility.ErrTransient, err.Error())
+	}
+
+	enrollResponse := fabricCaEnrollResponse{}
+	_ = json.Unmarshal(responseJson, &enrollResponse)
+	if (response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated) || !enrollResponse.Success {
+		message := response.Status
+		if len(enrollResponse.Errors) > 0 {
+			message = enrollResponse.Errors[0].Message
+		}
+
+		switch {
+		case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+			return nil, fmt.Errorf("%w: fabric ca: %s", utility.ErrPermissionDenied, message)
+		case response.StatusCode >= http.StatusInternalServerError:
+			return nil, fmt.Errorf("%w: fabric ca: %s", utility.ErrTransient, message)
+		}
+		return nil, fmt.Errorf("%w: fabric ca: %s", utility.ErrInvalidArgument, message)
+	}
+
+	certificate, err := base64.StdEncoding.DecodeString(enrollResponse.Result.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("%w: fabric ca returned an invalid certificate", utility.ErrInvalidState)
+	}
+
+	privateKeyDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fabricIdentity := &model_sig_graph.FabricIdentity{
+		MspId:       e.mspId,
+		Certificate: string(certificate),
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDer})),
+	}
+	_, _, err = NewFabricIdentitySign(fabricIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("%w: fabric ca returned an invalid certificate: %s", utility.ErrInvalidState, err.Error())
+	}
+	return fabricIdentity, nil
+}
@@ -0,0 +1,210 @@
+package service_sig_graph
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+	"time"
+)
+
+// REST api of a Fabric CA enrolling user with secret
+type fakeFabricCa struct {
+	t      *testing.T
+	key    *ecdsa.PrivateKey
+	caName string
+	// answers every enrollment with this status and error if set
+	status int
+	// certifies another key than the key of the request
+	isKeyReplaced bool
+}
+
+func newFakeFabricCa(t *testing.T) *fakeFabricCa {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeFabricCa{t: t, key: key}
+}
+
+func (ca *fakeFabricCa) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	writeErr := func(status int, message string) {
+		writer.WriteHeader(status)
+		json.NewEncoder(writer).Encode(map[string]any{
+			"success": false,
+			"errors":  []map[string]any{{"code": status, "message": message}},
+		})
+	}
+
+	if request.Method != http.MethodPost || request.URL.Path != "/api/v1/enroll" {
+		writeErr(http.StatusNotFound, "not found")
+		return
+	}
+	if ca.status != 0 {
+		writeErr(ca.status, "fake ca error")
+		return
+	}
+	enrollmentId, secret, ok := request.BasicAuth()
+	if !ok || enrollmentId != "user" || secret != "secret" {
+		writeErr(http.StatusUnauthorized, "authentication failure")
+		return
+	}
+
+	enrollRequest := fabricCaEnrollRequest{}
+	err := json.NewDecoder(request.Body).Decode(&enrollRequest)
+	if err != nil {
+		writeErr(http.StatusBadRequest, err.Error())
+		return
+	}
+	if enrollRequest.CaName != ca.caName {
+		writeErr(http.StatusBadRequest, "unknown ca "+enrollRequest.CaName)
+		return
+	}
+	block, _ := pem.Decode([]byte(enrollRequest.CertificateRequest))
+	if block == nil {
+		writeErr(http.StatusBadRequest, "invalid certificate request")
+		return
+	}
+	certificateRequest, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil || certificateRequest.CheckSignature() != nil {
+		writeErr(http.StatusBadRequest, "invalid certificate request")
+		return
+	}
+
+	publicKey := certificateRequest.PublicKey
+	if ca.isKeyReplaced {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			ca.t.Error(err)
+			return
+		}
+		publicKey = &otherKey.PublicKey
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      certificateRequest.Subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	issuer := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "fake ca"},
+	}
+	certificateDer, err := x509.CreateCertificate(rand.Reader, template, issuer, publicKey, ca.key)
+	if err != nil {
+		ca.t.Error(err)
+		return
+	}
+
+	json.NewEncoder(writer).Encode(map[string]any{
+		"success": true,
+		"result": map[string]any{
+			"Cert": base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDer})),
+		},
+	})
+}
+
+func TestFabricCaEnrollerHttpEnroll(t *testing.T) {
+	ca := newFakeFabricCa(t)
+	ca.caName = "ca-org1"
+	server := httptest.NewTLSServer(ca)
+	t.Cleanup(server.Close)
+
+	enroller := NewFabricCaEnrollerHttp(server.URL+"/", "ca-org1", "Org1MSP", server.Certificate())
+	fabricIdentity, err := enroller.Enroll(context.Background(), "user", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fabricIdentity.MspId != "Org1MSP" {
+		t.Fatalf("expected msp id Org1MSP, got %s", fabricIdentity.MspId)
+	}
+	block, _ := pem.Decode([]byte(fabricIdentity.Certificate))
+	if block == nil {
+		t.Fatal("expected a pem certificate")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if certificate.Subject.CommonName != "user" {
+		t.Fatalf("expected the certificate of user, got %s", certificate.Subject.CommonName)
+	}
+	_, _, err = NewFabricIdentitySign(fabricIdentity)
+	if err != nil {
+		t.Fatalf("expected a usable identity, got %v", err)
+	}
+}
+
+func TestFabricCaEnrollerHttpUntrustedServer(t *testing.T) {
+	server := httptest.NewTLSServer(newFakeFabricCa(t))
+	t.Cleanup(server.Close)
+
+	// the certificate of the server is not among the system roots
+	_, err := NewFabricCaEnrollerHttp(server.URL, "", "Org1MSP", nil).Enroll(context.Background(), "user", "secret")
+	if !errors.Is(err, utility.ErrTransient) {
+		t.Fatalf("expected ErrTransient, got %v", err)
+	}
+}
+
+func TestFabricCaEnrollerHttpErrors(t *testing.T) {
+	cases := []struct {
+		name         string
+		enrollmentId string
+		secret       string
+		setUp        func(ca *fakeFabricCa)
+		err          error
+	}{
+		{"missing enrollment id", "", "secret", nil, utility.ErrInvalidArgument},
+		{"wrong secret", "user", "wrong", nil, utility.ErrPermissionDenied},
+		{"unknown ca name", "user", "secret", func(ca *fakeFabricCa) {
+			ca.caName = "ca-org2"
+		}, utility.ErrInvalidArgument},
+		{"ca unavailable", "user", "secret", func(ca *fakeFabricCa) {
+			ca.status = http.StatusServiceUnavailable
+		}, utility.ErrTransient},
+		{"certificate of another key", "user", "secret", func(ca *fakeFabricCa) {
+			ca.isKeyReplaced = true
+		}, utility.ErrInvalidState},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ca := newFakeFabricCa(t)
+			if c.setUp != nil {
+				c.setUp(ca)
+			}
+			server := httptest.NewServer(ca)
+			t.Cleanup(server.Close)
+
+			_, err := NewFabricCaEnrollerHttp(server.URL, "", "Org1MSP", nil).Enroll(context.Background(), c.enrollmentId, c.secret)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+		})
+	}
+
+	t.Run("ca unreachable", func(t *testing.T) {
+		server := httptest.NewServer(newFakeFabricCa(t))
+		server.Close()
+
+		_, err := NewFabricCaEnrollerHttp(server.URL, "", "Org1MSP", nil).Enroll(context.Background(), "user", "secret")
+		if !errors.Is(err, utility.ErrTransient) {
+			t.Fatalf("expected ErrTransient, got %v", err)
+		}
+	})
+}
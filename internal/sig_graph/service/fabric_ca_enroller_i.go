@@ -0,0 +1,12 @@
+package service_sig_graph
+
+import (
+	"context"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+)
+
+type FabricCaEnrollerI interface {
+	// enroll an identity registered with the CA. The key pair is generated
+	// locally, only its certificate request is sent
+	Enroll(ctx context.Context, enrollmentId string, enrollmentSecret string) (*model_sig_graph.FabricIdentity, error)
+}
@@ -0,0 +1,73 @@
+package service_sig_graph
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+type fabricIdentityCtxKeyType struct{}
+
+var fabricIdentityCtxKey = fabricIdentityCtxKeyType{}
+
+// chaincode calls made with ctx are signed by fabricIdentity
+func WithFabricIdentity(ctx context.Context, fabricIdentity *model_sig_graph.FabricIdentity) context.Context {
+	return context.WithValue(ctx, fabricIdentityCtxKey, fabricIdentity)
+}
+
+// nil if calls are signed by the identity of the settings
+func FabricIdentityFromContext(ctx context.Context) *model_sig_graph.FabricIdentity {
+	fabricIdentity, _ := ctx.Value(fabricIdentityCtxKey).(*model_sig_graph.FabricIdentity)
+	return fabricIdentity
+}
+
+// return ErrInvalidArgument if the certificate or the key cannot be parsed
+// or if the key is not the key of the certificate
+func NewFabricIdentitySign(fabricIdentity *model_sig_graph.FabricIdentity) (identity.Identity, identity.Sign, error) {
+	if fabricIdentity.MspId == "" {
+		return nil, nil, fmt.Errorf("%w: missing msp id", utility.ErrInvalidArgument)
+	}
+
+	certificate, err := identity.CertificateFromPEM([]byte(fabricIdentity.Certificate))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid certificate: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	privateKey, err := identity.PrivateKeyFromPEM([]byte(fabricIdentity.PrivateKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid private key: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unsupported private key", utility.ErrInvalidArgument)
+	}
+	publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(certificate.PublicKey) {
+		return nil, nil, fmt.Errorf("%w: private key does not match the certificate", utility.ErrInvalidArgument)
+	}
+
+	id, err := identity.NewX509Identity(fabricIdentity.MspId, certificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	return id, sign, nil
+}
+
+// gateways are kept per identity under this key
+func fabricIdentityKey(fabricIdentity *model_sig_graph.FabricIdentity) string {
+	hash := sha256.Sum256([]byte(fabricIdentity.MspId + "\n" + fabricIdentity.Certificate + "\n" + fabricIdentity.PrivateKey))
+	return hex.EncodeToString(hash[:])
+}
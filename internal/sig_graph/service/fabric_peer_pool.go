@@ -0,0 +1,347 @@
+package service_sig_graph
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+type EPeerSelectionStrategy = string
+
+const (
+	// use the first healthy peer in the configured order
+	EPeerSelectionStrategyFailover EPeerSelectionStrategy = "failover"
+	// spread calls over all healthy peers
+	EPeerSelectionStrategyRoundRobin EPeerSelectionStrategy = "round_robin"
+)
+
+type FabricPeerPoolOptions struct {
+	Addresses []string
+	// transport credentials and any other option used to dial every peer
+	DialOptions []grpc.DialOption
+	Identity    identity.Identity
+	Sign        identity.Sign
+	Strategy    EPeerSelectionStrategy
+	// interval of the background connectivity check, 0 disables it
+	HealthCheckInterval time.Duration
+	// options of single peers by address, appended to DialOptions, e.g.
+	// transport credentials checking the server name of the peer
+	PeerDialOptions map[string][]grpc.DialOption
+	// gateways of the identities of the calls kept open per peer, the least
+	// recently used are closed. 0 keeps defaultMaxIdentityGateways
+	MaxIdentityGateways int
+}
+
+const defaultMaxIdentityGateways = 64
+
+// gateway of the identity of calls, closed once evicted and no call uses it
+type fabricIdentityGateway struct {
+	key       string
+	gateway   *client.Gateway
+	calls     int
+	isEvicted bool
+}
+
+type fabricPeer struct {
+	address    string
+	connection *grpc.ClientConn
+	// signs with the identity of the pool
+	gateway *client.Gateway
+	// gateways of the identities of the calls, front is the most recently
+	// used
+	identityGateways *list.List
+	// by fabricIdentityKey
+	identityGatewaysByKey map[string]*list.Element
+	isHealthy             bool
+}
+
+// keeps one gateway connection per peer. Calls go to healthy peers first and
+// move on to the next peer when a peer is unreachable.
+type fabricPeerPool struct {
+	mtx                 sync.Mutex
+	peers               []*fabricPeer
+	strategy            EPeerSelectionStrategy
+	maxIdentityGateways int
+	nextIndex           int
+	stop                chan struct{}
+	closeOnce           sync.Once
+}
+
+func NewFabricPeerPool(options FabricPeerPoolOptions) (*fabricPeerPool, error) {
+	if len(options.Addresses) == 0 {
+		return nil, fmt.Errorf("%w: no peer address", utility.ErrInvalidArgument)
+	}
+
+	strategy := options.Strategy
+	if strategy == "" {
+		strategy = EPeerSelectionStrategyFailover
+	}
+	if strategy != EPeerSelectionStrategyFailover && strategy != EPeerSelectionStrategyRoundRobin {
+		return nil, fmt.Errorf("%w: unknown peer selection strategy %s", utility.ErrInvalidArgument, strategy)
+	}
+
+	maxIdentityGateways := options.MaxIdentityGateways
+	if maxIdentityGateways == 0 {
+		maxIdentityGateways = defaultMaxIdentityGateways
+	}
+	if maxIdentityGateways < 0 {
+		return nil, fmt.Errorf("%w: negative max identity gateways %d", utility.ErrInvalidArgument, maxIdentityGateways)
+	}
+
+	pool := &fabricPeerPool{
+		peers:               []*fabricPeer{},
+		strategy:            strategy,
+		maxIdentityGateways: maxIdentityGateways,
+		stop:                make(chan struct{}),
+	}
+
+	for _, address := range options.Addresses {
+		// dial does not block, unreachable peers are detected on use
+		dialOptions := append([]grpc.DialOption{}, options.DialOptions...)
+		dialOptions = append(dialOptions, options.PeerDialOptions[address]...)
+		connection, err := grpc.Dial(address, dialOptions...)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+
+		gateway, err := client.Connect(
+			options.Identity,
+			client.WithSign(options.Sign),
+			client.WithClientConnection(connection),
+		)
+		if err != nil {
+			connection.Close()
+			pool.Close()
+			return nil, err
+		}
+
+		pool.peers = append(pool.peers, &fabricPeer{
+			address:               address,
+			connection:            connection,
+			gateway:               gateway,
+			identityGateways:      list.New(),
+			identityGatewaysByKey: map[string]*list.Element{},
+			isHealthy:             true,
+		})
+	}
+
+	if options.HealthCheckInterval > 0 {
+		go pool.runHealthCheck(options.HealthCheckInterval)
+	}
+
+	return pool, nil
+}
+
+func (p *fabricPeerPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+
+		p.mtx.Lock()
+		defer p.mtx.Unlock()
+		for _, peer := range p.peers {
+			for element := peer.identityGateways.Front(); element != nil; element = element.Next() {
+				element.Value.(*fabricIdentityGateway).gateway.Close()
+			}
+			peer.gateway.Close()
+			peer.connection.Close()
+		}
+	})
+}
+
+// addresses of the peers currently considered healthy
+func (p *fabricPeerPool) HealthyPeers() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	addresses := []string{}
+	for _, peer := range p.peers {
+		if peer.isHealthy {
+			addresses = append(addresses, peer.address)
+		}
+	}
+	return addresses
+}
+
+func (p *fabricPeerPool) runHealthCheck(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+func (p *fabricPeerPool) checkHealth() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for _, peer := range p.peers {
+		switch peer.connection.GetState() {
+		case connectivity.Ready:
+			peer.isHealthy = true
+		case connectivity.Idle:
+			// reconnect so that the next check knows whether the peer is back
+			peer.connection.Connect()
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			peer.isHealthy = false
+		}
+	}
+}
+
+// healthy peers in the order they should be tried, followed by the unhealthy
+// ones as a last resort
+func (p *fabricPeerPool) orderedPeers() []*fabricPeer {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	start := 0
+	if p.strategy == EPeerSelectionStrategyRoundRobin {
+		start = p.nextIndex
+		p.nextIndex = (p.nextIndex + 1) % len(p.peers)
+	}
+
+	healthy := []*fabricPeer{}
+	unhealthy := []*fabricPeer{}
+	for i := range p.peers {
+		peer := p.peers[(start+i)%len(p.peers)]
+		if peer.isHealthy {
+			healthy = append(healthy, peer)
+		} else {
+			unhealthy = append(unhealthy, peer)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+func (p *fabricPeerPool) setHealthy(peer *fabricPeer, isHealthy bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	peer.isHealthy = isHealthy
+}
+
+// gateway of the peer signing with fabricIdentity, or with the identity of
+// the pool if it is nil. It shares the connection to the peer. release must
+// be called once the call is done, closing a gateway cancels its calls
+func (p *fabricPeerPool) gatewayOf(peer *fabricPeer, fabricIdentity *model_sig_graph.FabricIdentity) (gateway *client.Gateway, release func(), err error) {
+	if fabricIdentity == nil {
+		return peer.gateway, func() {}, nil
+	}
+
+	key := fabricIdentityKey(fabricIdentity)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	element, ok := peer.identityGatewaysByKey[key]
+	if ok {
+		peer.identityGateways.MoveToFront(element)
+	} else {
+		id, sign, err := NewFabricIdentitySign(fabricIdentity)
+		if err != nil {
+			return nil, nil, err
+		}
+		gateway, err := client.Connect(
+			id,
+			client.WithSign(sign),
+			client.WithClientConnection(peer.connection),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		element = peer.identityGateways.PushFront(&fabricIdentityGateway{key: key, gateway: gateway})
+		peer.identityGatewaysByKey[key] = element
+		for peer.identityGateways.Len() > p.maxIdentityGateways {
+			p.evictIdentityGateway(peer, peer.identityGateways.Back())
+		}
+	}
+
+	identityGateway := element.Value.(*fabricIdentityGateway)
+	identityGateway.calls++
+	return identityGateway.gateway, func() {
+		p.mtx.Lock()
+		defer p.mtx.Unlock()
+
+		identityGateway.calls--
+		if identityGateway.isEvicted && identityGateway.calls == 0 {
+			identityGateway.gateway.Close()
+		}
+	}, nil
+}
+
+// the gateway is closed now or by the release of its last call
+func (p *fabricPeerPool) evictIdentityGateway(peer *fabricPeer, element *list.Element) {
+	identityGateway := element.Value.(*fabricIdentityGateway)
+	peer.identityGateways.Remove(element)
+	delete(peer.identityGatewaysByKey, identityGateway.key)
+
+	identityGateway.isEvicted = true
+	if identityGateway.calls == 0 {
+		identityGateway.gateway.Close()
+	}
+}
+
+// call fn on peers until one succeeds or fails with an error that
+// canTryNextPeer does not accept. Calls are signed by fabricIdentity if
+// it is not nil
+func (p *fabricPeerPool) do(
+	fabricIdentity *model_sig_graph.FabricIdentity,
+	canTryNextPeer func(err error) bool,
+	fn func(gateway *client.Gateway) ([]byte, error),
+) ([]byte, error) {
+	var err error
+	for _, peer := range p.orderedPeers() {
+		var gateway *client.Gateway
+		var release func()
+		gateway, release, err = p.gatewayOf(peer, fabricIdentity)
+		if err != nil {
+			return nil, err
+		}
+
+		var result []byte
+		result, err = fn(gateway)
+		release()
+		if err == nil {
+			p.setHealthy(peer, true)
+			return result, nil
+		}
+
+		if !canTryNextPeer(err) {
+			return nil, err
+		}
+		p.setHealthy(peer, false)
+	}
+
+	return nil, err
+}
+
+// queries do not change the ledger, so they can be sent to another peer
+// whenever the peer could not answer
+func canEvaluateOnNextPeer(err error) bool {
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// a transaction is only sent to another peer when it was never endorsed,
+// otherwise it might already be on its way to the orderer
+func canSubmitOnNextPeer(err error) bool {
+	endorseErr := &client.EndorseError{}
+	return errors.As(err, &endorseErr) && status.Code(err) == codes.Unavailable
+}
@@ -0,0 +1,316 @@
+package service_sig_graph
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// answers every evaluation with its name, or with err if it is set
+type fakeGatewayServer struct {
+	gateway.UnimplementedGatewayServer
+	name string
+	err  error
+}
+
+func (s *fakeGatewayServer) Evaluate(ctx context.Context, request *gateway.EvaluateRequest) (*gateway.EvaluateResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &gateway.EvaluateResponse{
+		Result: &peer.Response{Status: 200, Payload: []byte(s.name)},
+	}, nil
+}
+
+type fakePeer struct {
+	address string
+	server  *grpc.Server
+	gateway *fakeGatewayServer
+}
+
+func startFakePeers(t *testing.T, count int) []*fakePeer {
+	t.Helper()
+
+	peers := []*fakePeer{}
+	for i := 0; i < count; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		peer := &fakePeer{
+			address: listener.Addr().String(),
+			server:  grpc.NewServer(),
+			gateway: &fakeGatewayServer{name: fmt.Sprintf("peer%d", i)},
+		}
+		gateway.RegisterGatewayServer(peer.server, peer.gateway)
+		go peer.server.Serve(listener)
+		t.Cleanup(peer.server.Stop)
+
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+type fakeIdentity struct{}
+
+func (fakeIdentity) MspID() string {
+	return "Org1MSP"
+}
+
+func (fakeIdentity) Credentials() []byte {
+	return []byte("certificate")
+}
+
+func newFakePeerPool(t *testing.T, peers []*fakePeer, strategy EPeerSelectionStrategy) *fabricPeerPool {
+	t.Helper()
+
+	addresses := []string{}
+	for _, peer := range peers {
+		addresses = append(addresses, peer.address)
+	}
+
+	pool, err := NewFabricPeerPool(FabricPeerPoolOptions{
+		Addresses:   addresses,
+		DialOptions: []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		Identity:    fakeIdentity{},
+		Sign: func(digest []byte) ([]byte, error) {
+			return []byte("signature"), nil
+		},
+		Strategy: strategy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// names of the peers answering count queries
+func queryFakePeers(t *testing.T, pool *fabricPeerPool, count int) []string {
+	t.Helper()
+
+	service := NewSmartContractServiceHyperledger(pool, "channel", "chaincode", "contract")
+	names := []string{}
+	for i := 0; i < count; i++ {
+		name, err := service.Query(context.Background(), "GetNode")
+		if err != nil {
+			t.Fatalf("query %d: %v", i, err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func assertPeerNames(t *testing.T, actual []string, expected ...string) {
+	t.Helper()
+
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Fatalf("expected answers from %v, got %v", expected, actual)
+	}
+}
+
+func TestFabricPeerPoolFailover(t *testing.T) {
+	peers := startFakePeers(t, 3)
+	pool := newFakePeerPool(t, peers, EPeerSelectionStrategyFailover)
+
+	assertPeerNames(t, queryFakePeers(t, pool, 2), "peer0", "peer0")
+
+	peers[0].server.Stop()
+	assertPeerNames(t, queryFakePeers(t, pool, 2), "peer1", "peer1")
+	assertPeerNames(t, pool.HealthyPeers(), peers[1].address, peers[2].address)
+
+	peers[1].server.Stop()
+	assertPeerNames(t, queryFakePeers(t, pool, 1), "peer2")
+
+	peers[2].server.Stop()
+	_, err := NewSmartContractServiceHyperledger(pool, "channel", "chaincode", "contract").Query(context.Background(), "GetNode")
+	if err == nil {
+		t.Fatal("expected an error without reachable peers")
+	}
+}
+
+func TestFabricPeerPoolRoundRobin(t *testing.T) {
+	peers := startFakePeers(t, 3)
+	pool := newFakePeerPool(t, peers, EPeerSelectionStrategyRoundRobin)
+
+	assertPeerNames(t, queryFakePeers(t, pool, 4), "peer0", "peer1", "peer2", "peer0")
+
+	// the turn of peer1 goes to the next healthy peer
+	peers[1].server.Stop()
+	assertPeerNames(t, queryFakePeers(t, pool, 4), "peer2", "peer2", "peer0", "peer2")
+	assertPeerNames(t, pool.HealthyPeers(), peers[0].address, peers[2].address)
+}
+
+func TestFabricPeerPoolDoesNotFailOverOnChaincodeError(t *testing.T) {
+	peers := startFakePeers(t, 2)
+	peers[0].gateway.err = status.Error(codes.Aborted, "chaincode error")
+	pool := newFakePeerPool(t, peers, EPeerSelectionStrategyFailover)
+
+	_, err := NewSmartContractServiceHyperledger(pool, "channel", "chaincode", "contract").Query(context.Background(), "GetNode")
+	if err == nil {
+		t.Fatal("expected the error of peer0")
+	}
+	assertPeerNames(t, pool.HealthyPeers(), peers[0].address, peers[1].address)
+}
+
+// self-signed fabric identity of a member of Org1MSP
+func newFakeFabricIdentity(t *testing.T, name string) *model_sig_graph.FabricIdentity {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	certificateDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &model_sig_graph.FabricIdentity{
+		MspId:       "Org1MSP",
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDer})),
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})),
+	}
+}
+
+func newFakeIdentityPeerPool(t *testing.T, peer *fakePeer, maxIdentityGateways int) *fabricPeerPool {
+	t.Helper()
+
+	pool, err := NewFabricPeerPool(FabricPeerPoolOptions{
+		Addresses:   []string{peer.address},
+		DialOptions: []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		Identity:    fakeIdentity{},
+		Sign: func(digest []byte) ([]byte, error) {
+			return []byte("signature"), nil
+		},
+		MaxIdentityGateways: maxIdentityGateways,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// keys of the open gateways of call identities, most recently used first
+func identityGatewayKeys(pool *fabricPeerPool) []string {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	keys := []string{}
+	for element := pool.peers[0].identityGateways.Front(); element != nil; element = element.Next() {
+		keys = append(keys, element.Value.(*fabricIdentityGateway).key)
+	}
+	return keys
+}
+
+func evaluateOnGateway(gateway *client.Gateway) error {
+	_, err := gateway.GetNetwork("channel").GetContract("chaincode").EvaluateTransaction("GetNode")
+	return err
+}
+
+func TestFabricPeerPoolEvictsIdentityGateways(t *testing.T) {
+	peers := startFakePeers(t, 1)
+	pool := newFakeIdentityPeerPool(t, peers[0], 2)
+	service := NewSmartContractServiceHyperledger(pool, "channel", "chaincode", "contract")
+	identities := []*model_sig_graph.FabricIdentity{
+		newFakeFabricIdentity(t, "user0"),
+		newFakeFabricIdentity(t, "user1"),
+		newFakeFabricIdentity(t, "user2"),
+	}
+
+	query := func(fabricIdentity *model_sig_graph.FabricIdentity) {
+		t.Helper()
+		_, err := service.Query(WithFabricIdentity(context.Background(), fabricIdentity), "GetNode")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query(identities[0])
+	query(identities[1])
+	query(identities[0])
+	evicted := pool.peers[0].identityGatewaysByKey[fabricIdentityKey(identities[1])].Value.(*fabricIdentityGateway).gateway
+
+	// user1 is the least recently used
+	query(identities[2])
+	assertPeerNames(t, identityGatewayKeys(pool), fabricIdentityKey(identities[2]), fabricIdentityKey(identities[0]))
+	if err := evaluateOnGateway(evicted); status.Code(err) != codes.Canceled {
+		t.Fatalf("expected the evicted gateway to be closed, got %v", err)
+	}
+
+	// calls without identity do not use these gateways
+	queryFakePeers(t, pool, 1)
+	query(identities[1])
+	assertPeerNames(t, identityGatewayKeys(pool), fabricIdentityKey(identities[1]), fabricIdentityKey(identities[2]))
+}
+
+func TestFabricPeerPoolClosesEvictedGatewayAfterItsCalls(t *testing.T) {
+	peers := startFakePeers(t, 1)
+	pool := newFakeIdentityPeerPool(t, peers[0], 1)
+
+	inUse, release, err := pool.gatewayOf(pool.peers[0], newFakeFabricIdentity(t, "user0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, releaseOther, err := pool.gatewayOf(pool.peers[0], newFakeFabricIdentity(t, "user1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer releaseOther()
+
+	// evicted while in use
+	err = evaluateOnGateway(inUse)
+	if err != nil {
+		t.Fatalf("expected the gateway to stay open during its call, got %v", err)
+	}
+
+	release()
+	if err := evaluateOnGateway(inUse); status.Code(err) != codes.Canceled {
+		t.Fatalf("expected the gateway to be closed after its call, got %v", err)
+	}
+}
+
+func TestFabricPeerPoolRejectsNegativeMaxIdentityGateways(t *testing.T) {
+	_, err := NewFabricPeerPool(FabricPeerPoolOptions{
+		Addresses:           []string{"127.0.0.1:1"},
+		DialOptions:         []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		Identity:            fakeIdentity{},
+		MaxIdentityGateways: -1,
+	})
+	if !errors.Is(err, utility.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
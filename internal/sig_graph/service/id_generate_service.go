@@ -0,0 +1,20 @@
+package service_sig_graph
+
+import (
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	"sig_graph_scp/pkg/utility"
+)
+
+// generator of the ids of the given format, uuid v4 if format is empty
+func NewIdGenerateService(format model.ENodeIdFormat, graphName string, clock utility.ClockI) (IdGenerateServiceI, error) {
+	switch format {
+	case "", model.ENodeIdFormatUuidV4:
+		return NewIdGenerateServiceUuid(graphName), nil
+	case model.ENodeIdFormatUuidV7:
+		return NewIdGenerateServiceUuid7(graphName, clock), nil
+	case model.ENodeIdFormatUlid:
+		return NewIdGenerateServiceUlid(graphName, clock), nil
+	}
+	return nil, fmt.Errorf("%w: unknown node id format %s", utility.ErrInvalidArgument, format)
+}
@@ -6,4 +6,6 @@ import (
 
 type IdGenerateServiceI interface {
 	NewFullId(ctx context.Context) (string, error)
+	// id of a node of another graph than the one of the generator
+	NewFullIdOfGraph(ctx context.Context, graphName string) (string, error)
 }
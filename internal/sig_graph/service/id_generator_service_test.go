@@ -0,0 +1,170 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/binary"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const idGeneratorTestGraphName = "sgp://memory:[]:public"
+
+var idGeneratorTestTime = time.UnixMilli(1700000000123)
+
+func TestIdGenerateServiceUuid7Bits(t *testing.T) {
+	generator := NewIdGenerateServiceUuid7(idGeneratorTestGraphName, utility.NewClockFake(idGeneratorTestTime))
+
+	for i := 0; i < 100; i++ {
+		fullId, err := generator.NewFullId(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodeId, err := model_sig_graph.ParseNodeId(fullId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nodeId.GraphName != idGeneratorTestGraphName || nodeId.Format != model.ENodeIdFormatUuidV7 {
+			t.Fatalf("expected an uuid v7 of %s, got %+v", idGeneratorTestGraphName, nodeId)
+		}
+
+		id, err := uuid.Parse(nodeId.LocalId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.Version() != 7 {
+			t.Fatalf("expected version 7, got %d in %s", id.Version(), id)
+		}
+		if id.Variant() != uuid.RFC4122 {
+			t.Fatalf("expected the rfc 4122 variant, got %s in %s", id.Variant(), id)
+		}
+		ms := uint64(binary.BigEndian.Uint16(id[0:2]))<<32 | uint64(binary.BigEndian.Uint32(id[2:6]))
+		if ms != uint64(idGeneratorTestTime.UnixMilli()) {
+			t.Fatalf("expected the time %d, got %d in %s", idGeneratorTestTime.UnixMilli(), ms, id)
+		}
+	}
+}
+
+func TestIdGenerateServiceUlidTime(t *testing.T) {
+	generator := NewIdGenerateServiceUlid(idGeneratorTestGraphName, utility.NewClockFake(idGeneratorTestTime))
+
+	fullId, err := generator.NewFullId(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeId, err := model_sig_graph.ParseNodeId(fullId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodeId.Format != model.ENodeIdFormatUlid {
+		t.Fatalf("expected an ulid, got %+v", nodeId)
+	}
+
+	// the first 10 characters encode the 48 bits of milliseconds
+	id := [16]byte{}
+	binary.BigEndian.PutUint16(id[0:2], uint16(idGeneratorTestTime.UnixMilli()>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(idGeneratorTestTime.UnixMilli()))
+	expected := model_sig_graph.EncodeUlid(id)[:10]
+	if !strings.HasPrefix(nodeId.LocalId, expected) {
+		t.Fatalf("expected the time %s, got %s", expected, nodeId.LocalId)
+	}
+}
+
+func TestIdGenerateServiceMonotonic(t *testing.T) {
+	cases := []struct {
+		name      string
+		generator func(clock utility.ClockI) IdGenerateServiceI
+	}{
+		{"uuid v7", func(clock utility.ClockI) IdGenerateServiceI {
+			return NewIdGenerateServiceUuid7(idGeneratorTestGraphName, clock)
+		}},
+		{"ulid", func(clock utility.ClockI) IdGenerateServiceI {
+			return NewIdGenerateServiceUlid(idGeneratorTestGraphName, clock)
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clock := utility.NewClockFake(idGeneratorTestTime)
+			generator := c.generator(clock)
+
+			last := ""
+			for i := 0; i < 1000; i++ {
+				// the same millisecond, then a clock going back
+				if i == 500 {
+					clock.Advance(-time.Second)
+				}
+				id, err := generator.NewFullId(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if id <= last {
+					t.Fatalf("expected %s after %s", id, last)
+				}
+				last = id
+			}
+		})
+	}
+}
+
+func TestMonotonicEntropyIncrement(t *testing.T) {
+	cases := []struct {
+		name       string
+		randomBits uint
+		last       [10]byte
+		expected   [10]byte
+		isOk       bool
+	}{
+		{"no carry", 80, [10]byte{9: 0x01}, [10]byte{9: 0x02}, true},
+		{"carry", 80, [10]byte{8: 0x01, 9: 0xff}, [10]byte{8: 0x02}, true},
+		{"overflow of 80 bits", 80,
+			[10]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			[10]byte{}, false},
+		{"carry up to the top random bits", 74,
+			[10]byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			[10]byte{0: 0x02}, true},
+		{"overflow of 74 bits", 74,
+			[10]byte{0x03, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			[10]byte{}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entropy := newMonotonicEntropy(utility.NewClockFake(idGeneratorTestTime), c.randomBits)
+			entropy.last = c.last
+
+			isOk := entropy.increment()
+			if isOk != c.isOk {
+				t.Fatalf("expected %t, got %t", c.isOk, isOk)
+			}
+			if isOk && entropy.last != c.expected {
+				t.Fatalf("expected %x, got %x", c.expected, entropy.last)
+			}
+		})
+	}
+}
+
+func TestMonotonicEntropyOverflowBorrowsNextMs(t *testing.T) {
+	entropy := newMonotonicEntropy(utility.NewClockFake(idGeneratorTestTime), 74)
+	ms, _, err := entropy.next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entropy.last = [10]byte{0x03, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	nextMs, random, err := entropy.next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextMs != ms+1 {
+		t.Fatalf("expected the time %d, got %d", ms+1, nextMs)
+	}
+	if random[0]&0xfc != 0 {
+		t.Fatalf("expected 74 random bits, got %x", random)
+	}
+}
@@ -0,0 +1,39 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+)
+
+// ids sorted by creation time, 48 bits of milliseconds then 80 random bits
+type idGenerateServiceUlid struct {
+	graphName string
+	entropy   *monotonicEntropy
+}
+
+func NewIdGenerateServiceUlid(graphName string, clock utility.ClockI) *idGenerateServiceUlid {
+	return &idGenerateServiceUlid{
+		graphName: graphName,
+		entropy:   newMonotonicEntropy(clock, 80),
+	}
+}
+
+func (s *idGenerateServiceUlid) NewFullId(ctx context.Context) (string, error) {
+	return s.NewFullIdOfGraph(ctx, s.graphName)
+}
+
+func (s *idGenerateServiceUlid) NewFullIdOfGraph(ctx context.Context, graphName string) (string, error) {
+	ms, random, err := s.entropy.next()
+	if err != nil {
+		return "", err
+	}
+
+	id := [16]byte{}
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], random[:])
+	return fmt.Sprintf("%s:%s", graphName, model_sig_graph.EncodeUlid(id)), nil
+}
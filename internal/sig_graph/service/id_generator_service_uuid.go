@@ -16,5 +16,9 @@ func NewIdGenerateServiceUuid(graphName string) *idGenerateServiceUuid {
 }
 
 func (s *idGenerateServiceUuid) NewFullId(ctx context.Context) (string, error) {
-	return fmt.Sprintf("%s:%s", s.graphName, uuid.New().String()), nil
+	return s.NewFullIdOfGraph(ctx, s.graphName)
+}
+
+func (s *idGenerateServiceUuid) NewFullIdOfGraph(ctx context.Context, graphName string) (string, error) {
+	return fmt.Sprintf("%s:%s", graphName, uuid.New().String()), nil
 }
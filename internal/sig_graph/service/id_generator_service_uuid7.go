@@ -0,0 +1,47 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sig_graph_scp/pkg/utility"
+
+	"github.com/google/uuid"
+)
+
+// uuid v7 ids, sorted by creation time
+type idGenerateServiceUuid7 struct {
+	graphName string
+	entropy   *monotonicEntropy
+}
+
+func NewIdGenerateServiceUuid7(graphName string, clock utility.ClockI) *idGenerateServiceUuid7 {
+	return &idGenerateServiceUuid7{
+		graphName: graphName,
+		// 12 bits of rand_a and 62 bits of rand_b
+		entropy: newMonotonicEntropy(clock, 74),
+	}
+}
+
+func (s *idGenerateServiceUuid7) NewFullId(ctx context.Context) (string, error) {
+	return s.NewFullIdOfGraph(ctx, s.graphName)
+}
+
+func (s *idGenerateServiceUuid7) NewFullIdOfGraph(ctx context.Context, graphName string) (string, error) {
+	ms, random, err := s.entropy.next()
+	if err != nil {
+		return "", err
+	}
+
+	high := uint64(binary.BigEndian.Uint16(random[0:2]))
+	low := binary.BigEndian.Uint64(random[2:10])
+	randA := (high<<2 | low>>62) & 0xfff
+	randB := low & (1<<62 - 1)
+
+	id := uuid.UUID{}
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	binary.BigEndian.PutUint16(id[6:8], 0x7000|uint16(randA))
+	binary.BigEndian.PutUint64(id[8:16], 0x8000000000000000|randB)
+	return fmt.Sprintf("%s:%s", graphName, id.String()), nil
+}
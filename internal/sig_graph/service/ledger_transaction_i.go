@@ -0,0 +1,19 @@
+package service_sig_graph
+
+import "context"
+
+// transaction sent to the ledger whose commit may still be pending
+type LedgerTransactionI interface {
+	Id() string
+	// block until the transaction is committed. Return ErrTransactionFailed
+	// if the ledger marked it as invalid
+	WaitForCommit(ctx context.Context) error
+}
+
+// commit status of a transaction by its id, e.g. of a transaction submitted
+// before a restart
+type LedgerTransactionStatusServiceI interface {
+	// nil if the transaction is committed, ErrTransactionFailed if the ledger
+	// marked it as invalid and ErrNotFound if it is not on the ledger (yet)
+	GetTransactionStatus(ctx context.Context, transactionId string) error
+}
@@ -0,0 +1,73 @@
+package service_sig_graph
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sig_graph_scp/pkg/utility"
+	"sync"
+)
+
+// millisecond and random bits of time sortable ids. Within the same
+// millisecond the random bits of the last id are incremented instead of drawn
+// again, so the ids of a generator stay sorted by creation
+type monotonicEntropy struct {
+	mtx   sync.Mutex
+	clock utility.ClockI
+	// random bits in the low bits of the 80 bits of random
+	randomBits uint
+	lastMs     uint64
+	last       [10]byte
+}
+
+func newMonotonicEntropy(clock utility.ClockI, randomBits uint) *monotonicEntropy {
+	return &monotonicEntropy{
+		clock:      clock,
+		randomBits: randomBits,
+	}
+}
+
+func (e *monotonicEntropy) next() (uint64, [10]byte, error) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	ms := uint64(e.clock.Now().UnixMilli())
+	if ms >= 1<<48 {
+		return 0, [10]byte{}, fmt.Errorf("%w: time %d ms does not fit in 48 bits", utility.ErrInvalidState, ms)
+	}
+
+	// also when the clock went back
+	if ms <= e.lastMs && e.lastMs != 0 {
+		if e.increment() {
+			return e.lastMs, e.last, nil
+		}
+		// out of random bits in this millisecond, borrow the next one
+		ms = e.lastMs + 1
+	}
+
+	_, err := rand.Read(e.last[:])
+	if err != nil {
+		return 0, [10]byte{}, err
+	}
+	e.mask()
+	e.lastMs = ms
+	return ms, e.last, nil
+}
+
+// false if the random bits overflow
+func (e *monotonicEntropy) increment() bool {
+	for i := len(e.last) - 1; i >= 0; i-- {
+		e.last[i]++
+		if e.last[i] != 0 {
+			break
+		}
+	}
+	overflown := e.last
+	e.mask()
+	return e.last == overflown && e.last != [10]byte{}
+}
+
+func (e *monotonicEntropy) mask() {
+	for bit := e.randomBits; bit < 80; bit++ {
+		e.last[9-bit/8] &^= 1 << (bit % 8)
+	}
+}
@@ -0,0 +1,15 @@
+package service_sig_graph
+
+import model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+
+// cache of the ledger json of nodes
+type NodeCacheI interface {
+	// false if the node is not cached or expired
+	Get(id string) (nodeJson []byte, ok bool)
+	// finalized nodes never change and do not expire
+	Put(id string, nodeJson []byte, isFinalized bool)
+	Invalidate(id string)
+	// count a ledger query saved by waiting for another caller
+	AddCollapsedLookups(count uint64)
+	Stats() model_sig_graph.NodeCacheStats
+}
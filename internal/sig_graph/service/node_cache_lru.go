@@ -0,0 +1,116 @@
+package service_sig_graph
+
+import (
+	"container/list"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sync"
+	"time"
+)
+
+type nodeCacheEntry struct {
+	id       string
+	nodeJson []byte
+	// zero for finalized nodes
+	expiresAt time.Time
+}
+
+// least recently used nodes are evicted once capacity is reached
+type nodeCacheLru struct {
+	mtx      sync.Mutex
+	clock    utility.ClockI
+	capacity int
+	ttl      time.Duration
+	// front is the most recently used
+	entries *list.List
+	byId    map[string]*list.Element
+	stats   model_sig_graph.NodeCacheStats
+}
+
+func NewNodeCacheLru(clock utility.ClockI, capacity int, ttl time.Duration) *nodeCacheLru {
+	return &nodeCacheLru{
+		clock:    clock,
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  list.New(),
+		byId:     map[string]*list.Element{},
+	}
+}
+
+func (c *nodeCacheLru) Get(id string) ([]byte, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	element, ok := c.byId[id]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	entry := element.Value.(*nodeCacheEntry)
+	if !entry.expiresAt.IsZero() && !c.clock.Now().Before(entry.expiresAt) {
+		c.remove(element)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.entries.MoveToFront(element)
+	c.stats.Hits++
+	return entry.nodeJson, true
+}
+
+func (c *nodeCacheLru) Put(id string, nodeJson []byte, isFinalized bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entry := &nodeCacheEntry{
+		id:       id,
+		nodeJson: nodeJson,
+	}
+	if !isFinalized {
+		entry.expiresAt = c.clock.Now().Add(c.ttl)
+	}
+
+	if element, ok := c.byId[id]; ok {
+		element.Value = entry
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.byId[id] = c.entries.PushFront(entry)
+	for c.entries.Len() > c.capacity {
+		c.remove(c.entries.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *nodeCacheLru) Invalidate(id string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if element, ok := c.byId[id]; ok {
+		c.remove(element)
+	}
+}
+
+func (c *nodeCacheLru) AddCollapsedLookups(count uint64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.stats.CollapsedLookups += count
+}
+
+func (c *nodeCacheLru) Stats() model_sig_graph.NodeCacheStats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	stats := c.stats
+	stats.Size = c.entries.Len()
+	return stats
+}
+
+func (c *nodeCacheLru) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.byId, element.Value.(*nodeCacheEntry).id)
+}
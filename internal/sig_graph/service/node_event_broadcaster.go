@@ -0,0 +1,94 @@
+package service_sig_graph
+
+import (
+	"context"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sync"
+)
+
+type nodeEventSubscriber struct {
+	mtx    sync.Mutex
+	queue  []model_sig_graph.NodeEvent
+	signal chan struct{}
+}
+
+func (s *nodeEventSubscriber) push(events []model_sig_graph.NodeEvent) {
+	s.mtx.Lock()
+	s.queue = append(s.queue, events...)
+	s.mtx.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (s *nodeEventSubscriber) take() []model_sig_graph.NodeEvent {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	events := s.queue
+	s.queue = nil
+	return events
+}
+
+// fans events out to every subscriber. Publishing never blocks, events
+// are queued until the subscriber reads them.
+type nodeEventBroadcaster struct {
+	mtx         sync.Mutex
+	subscribers map[*nodeEventSubscriber]bool
+}
+
+var _ NodeEventSourceI = (*nodeEventBroadcaster)(nil)
+
+func newNodeEventBroadcaster() *nodeEventBroadcaster {
+	return &nodeEventBroadcaster{
+		subscribers: map[*nodeEventSubscriber]bool{},
+	}
+}
+
+func (b *nodeEventBroadcaster) Subscribe(ctx context.Context) (<-chan model_sig_graph.NodeEvent, error) {
+	subscriber := &nodeEventSubscriber{
+		signal: make(chan struct{}, 1),
+	}
+
+	b.mtx.Lock()
+	b.subscribers[subscriber] = true
+	b.mtx.Unlock()
+
+	events := make(chan model_sig_graph.NodeEvent)
+	go func() {
+		defer close(events)
+		defer func() {
+			b.mtx.Lock()
+			delete(b.subscribers, subscriber)
+			b.mtx.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-subscriber.signal:
+			}
+
+			for _, event := range subscriber.take() {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (b *nodeEventBroadcaster) publish(events ...model_sig_graph.NodeEvent) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for subscriber := range b.subscribers {
+		subscriber.push(events)
+	}
+}
@@ -0,0 +1,134 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	utility_sig_graph "sig_graph_scp/internal/sig_graph/utility"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// name of the chaincode event emitted by every transaction that changes
+// nodes. Its payload is a json array of {"event_type", "node_id"}. The
+// chaincode must set it in CreateAsset, TransferAsset and SplitAsset,
+// without it the source delivers nothing
+const NodeEventsChaincodeEventName = "NodeEvents"
+
+const nodeEventReconnectDelay = time.Second
+
+// streams the chaincode events of SigGraph from one peer of the pool. When
+// the stream breaks, it resumes on the next peer after the last delivered
+// transaction.
+type nodeEventSourceHyperledger struct {
+	peerPool      *fabricPeerPool
+	channelName   string
+	chaincodeName string
+}
+
+var _ NodeEventSourceI = (*nodeEventSourceHyperledger)(nil)
+
+func NewNodeEventSourceHyperledger(peerPool *fabricPeerPool, settings utility_sig_graph.SettingsI) *nodeEventSourceHyperledger {
+	return &nodeEventSourceHyperledger{
+		peerPool:      peerPool,
+		channelName:   settings.ChannelName(),
+		chaincodeName: settings.ContractName(),
+	}
+}
+
+func (s *nodeEventSourceHyperledger) Subscribe(ctx context.Context) (<-chan model_sig_graph.NodeEvent, error) {
+	checkpointer := new(client.InMemoryCheckpointer)
+
+	chaincodeEvents, peer, err := s.connect(ctx, checkpointer)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan model_sig_graph.NodeEvent)
+	go func() {
+		defer close(events)
+
+		for {
+			for chaincodeEvent := range chaincodeEvents {
+				nodeEvents, err := parseNodeEvents(chaincodeEvent)
+				if err != nil {
+					// not a SigGraph event
+					checkpointer.CheckpointChaincodeEvent(chaincodeEvent)
+					continue
+				}
+
+				for i := range nodeEvents {
+					select {
+					case events <- nodeEvents[i]:
+					case <-ctx.Done():
+						return
+					}
+				}
+				checkpointer.CheckpointChaincodeEvent(chaincodeEvent)
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+			s.peerPool.setHealthy(peer, false)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(nodeEventReconnectDelay):
+				}
+
+				chaincodeEvents, peer, err = s.connect(ctx, checkpointer)
+				if err == nil {
+					break
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// open the event stream on the first peer that accepts it
+func (s *nodeEventSourceHyperledger) connect(
+	ctx context.Context,
+	checkpointer *client.InMemoryCheckpointer,
+) (<-chan *client.ChaincodeEvent, *fabricPeer, error) {
+	var err error
+	for _, peer := range s.peerPool.orderedPeers() {
+		var chaincodeEvents <-chan *client.ChaincodeEvent
+		chaincodeEvents, err = peer.gateway.GetNetwork(s.channelName).ChaincodeEvents(
+			ctx,
+			s.chaincodeName,
+			client.WithCheckpoint(checkpointer),
+		)
+		if err == nil {
+			return chaincodeEvents, peer, nil
+		}
+		s.peerPool.setHealthy(peer, false)
+	}
+
+	return nil, nil, fmt.Errorf("failed to subscribe to chaincode events: %w", err)
+}
+
+func parseNodeEvents(chaincodeEvent *client.ChaincodeEvent) ([]model_sig_graph.NodeEvent, error) {
+	if chaincodeEvent.EventName != NodeEventsChaincodeEventName {
+		return nil, fmt.Errorf("%w: unknown event %s", utility.ErrInvalidArgument, chaincodeEvent.EventName)
+	}
+
+	nodeEvents := []model_sig_graph.NodeEvent{}
+	err := json.Unmarshal(chaincodeEvent.Payload, &nodeEvents)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	for i := range nodeEvents {
+		nodeEvents[i].TransactionId = chaincodeEvent.TransactionID
+		nodeEvents[i].BlockNumber = chaincodeEvent.BlockNumber
+	}
+	return nodeEvents, nil
+}
@@ -0,0 +1,12 @@
+package service_sig_graph
+
+import (
+	"context"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+)
+
+type NodeEventSourceI interface {
+	// events of transactions committed after the call, in commit order.
+	// The channel is closed once ctx is done
+	Subscribe(ctx context.Context) (<-chan model_sig_graph.NodeEvent, error)
+}
@@ -0,0 +1,60 @@
+package service_sig_graph
+
+import (
+	"context"
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+)
+
+type nodeIdValidator struct {
+	// any graph if empty
+	graphNames map[string]bool
+	// any format if empty
+	formats map[model.ENodeIdFormat]bool
+}
+
+func NewNodeIdValidator(graphNames []string, formats []model.ENodeIdFormat) *nodeIdValidator {
+	validator := &nodeIdValidator{
+		graphNames: map[string]bool{},
+		formats:    map[model.ENodeIdFormat]bool{},
+	}
+	for _, graphName := range graphNames {
+		validator.graphNames[graphName] = true
+	}
+	for _, format := range formats {
+		validator.formats[format] = true
+	}
+	return validator
+}
+
+func (v *nodeIdValidator) ValidateNodeId(ctx context.Context, id string) error {
+	_, err := v.parse(id)
+	return err
+}
+
+func (v *nodeIdValidator) ValidateNodeIdOfGraph(ctx context.Context, id string, graphName string) error {
+	nodeId, err := v.parse(id)
+	if err != nil {
+		return err
+	}
+	if nodeId.GraphName != graphName {
+		return fmt.Errorf("%w: node id %s is not on graph %s", utility.ErrInvalidArgument, id, graphName)
+	}
+	return nil
+}
+
+func (v *nodeIdValidator) parse(id string) (model_sig_graph.NodeId, error) {
+	nodeId, err := model_sig_graph.ParseNodeId(id)
+	if err != nil {
+		return nodeId, err
+	}
+	if len(v.formats) > 0 && !v.formats[nodeId.Format] {
+		return nodeId, fmt.Errorf("%w: node id %s has format %s, which is not accepted", utility.ErrInvalidArgument, id, nodeId.Format)
+	}
+	if len(v.graphNames) > 0 && !v.graphNames[nodeId.GraphName] {
+		return nodeId, fmt.Errorf("%w: node id %s is on unknown graph %s", utility.ErrInvalidArgument, id, nodeId.GraphName)
+	}
+	return nodeId, nil
+}
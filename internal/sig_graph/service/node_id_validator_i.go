@@ -0,0 +1,13 @@
+package service_sig_graph
+
+import (
+	"context"
+)
+
+type NodeIdValidatorI interface {
+	// return ErrInvalidArgument if id is malformed, has a format that is not
+	// accepted or is not on one of the graphs of the validator
+	ValidateNodeId(ctx context.Context, id string) error
+	// same as ValidateNodeId, and the id must be on graphName
+	ValidateNodeIdOfGraph(ctx context.Context, id string, graphName string) error
+}
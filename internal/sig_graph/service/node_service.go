@@ -3,18 +3,19 @@ package service_sig_graph
 import (
 	"context"
 	"encoding/json"
-	"sig_graph_scp/pkg/model"
-	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"fmt"
 	"sig_graph_scp/pkg/utility"
 )
 
 type nodeService struct {
 	smartContractService SmartContractServiceI
+	nodeTypes            NodeTypeRegistryI
 }
 
-func NewNodeService(smartContractService SmartContractServiceI) NodeServiceI {
+func NewNodeService(smartContractService SmartContractServiceI, nodeTypes NodeTypeRegistryI) NodeServiceI {
 	return &nodeService{
 		smartContractService: smartContractService,
+		nodeTypes:            nodeTypes,
 	}
 }
 
@@ -27,7 +28,7 @@ func (s *nodeService) DoNodeIdsExists(
 		return nil, err
 	}
 
-	data, err := s.smartContractService.Query("DoNodeIdsExist", string(idsJson))
+	data, err := s.smartContractService.Query(ctx, "DoNodeIdsExist", string(idsJson))
 	if err != nil {
 		return nil, err
 	}
@@ -47,59 +48,67 @@ type getNodesByIdRequest struct {
 
 // return NotFound if any one id is not found
 func (s *nodeService) FetchNodesByIds(ctx context.Context, ids map[string]bool) (map[string]any, error) {
-	request := getNodesByIdRequest{}
-	request.Ids = ids
+	nodesJson, err := s.FetchNodesJsonByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
 
-	requestJson, err := json.Marshal(request)
+	parsedNodes, err := s.parseNodeType(
+		ctx,
+		nodesJson,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	responseJson, err := s.smartContractService.Query("GetNodesById", string(requestJson))
+	return parsedNodes, nil
+}
+
+func (s *nodeService) FetchNodesJsonByIds(ctx context.Context, ids map[string]bool) (map[string]json.RawMessage, error) {
+	request := getNodesByIdRequest{}
+	request.Ids = ids
+
+	requestJson, err := json.Marshal(request)
 	if err != nil {
 		return nil, err
 	}
 
-	response := map[string]map[string]any{}
-	err = json.Unmarshal([]byte(responseJson), &response)
+	responseJson, err := s.smartContractService.Query(ctx, "GetNodesById", string(requestJson))
 	if err != nil {
 		return nil, err
 	}
 
-	parsedNodes, err := s.parseNodeType(
-		ctx,
-		response,
-	)
+	response := map[string]json.RawMessage{}
+	err = json.Unmarshal([]byte(responseJson), &response)
 	if err != nil {
 		return nil, err
 	}
 
-	return parsedNodes, nil
+	return response, nil
 }
 
 func (s *nodeService) parseNodeType(
 	ctx context.Context,
-	nodes map[string]map[string]any,
+	nodes map[string]json.RawMessage,
 ) (map[string]any, error) {
 	ret := map[string]any{}
 	for id := range nodes {
-		nodeJson, err := json.Marshal(nodes[id])
+		header := struct {
+			Type string `json:"type"`
+		}{}
+		err := json.Unmarshal(nodes[id], &header)
 		if err != nil {
 			return nil, err
 		}
 
-		nodeType := nodes[id]["type"]
-		switch nodeType {
-		case model.ENodeTypeAsset:
-			asset := model_sig_graph.Asset{}
-			err := json.Unmarshal(nodeJson, &asset)
-			if err != nil {
-				return nil, err
-			}
-
-			ret[id] = asset
-		default:
-			return nil, utility.ErrInvalidState
+		nodeType, err := s.nodeTypes.Get(header.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%w: node %s: %s", utility.ErrInvalidState, id, err.Error())
+		}
+
+		ret[id], err = nodeType.Decode(nodes[id])
+		if err != nil {
+			return nil, err
 		}
 	}
 
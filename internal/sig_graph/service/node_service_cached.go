@@ -0,0 +1,134 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sort"
+	"strings"
+)
+
+// read-through cache in front of a NodeServiceI. Concurrent fetches of the
+// same ids are collapsed into one ledger query. Callers get their own copy
+// of the nodes, decoded from the cached json. The cache holds the nodes as
+// read by the identity of the settings, calls with a fabric identity in ctx
+// go to the ledger
+type nodeServiceCached struct {
+	nodeService  NodeServiceI
+	cache        NodeCacheI
+	nodeTypes    NodeTypeRegistryI
+	singleFlight utility.SingleFlightI
+}
+
+func NewNodeServiceCached(
+	nodeService NodeServiceI,
+	cache NodeCacheI,
+	nodeTypes NodeTypeRegistryI,
+	singleFlight utility.SingleFlightI,
+) NodeServiceI {
+	return &nodeServiceCached{
+		nodeService:  nodeService,
+		cache:        cache,
+		nodeTypes:    nodeTypes,
+		singleFlight: singleFlight,
+	}
+}
+
+func (s *nodeServiceCached) DoNodeIdsExists(ctx context.Context, ids map[string]bool) (map[string]bool, error) {
+	return s.nodeService.DoNodeIdsExists(ctx, ids)
+}
+
+func (s *nodeServiceCached) FetchNodesByIds(ctx context.Context, ids map[string]bool) (map[string]any, error) {
+	// the chaincode may restrict what an identity reads
+	if FabricIdentityFromContext(ctx) != nil {
+		return s.nodeService.FetchNodesByIds(ctx, ids)
+	}
+
+	nodesJson := map[string][]byte{}
+	missingIds := []string{}
+	for id := range ids {
+		if nodeJson, ok := s.cache.Get(id); ok {
+			nodesJson[id] = nodeJson
+		} else {
+			missingIds = append(missingIds, id)
+		}
+	}
+
+	if len(missingIds) > 0 {
+		sort.Strings(missingIds)
+		// the callers waiting for the fetch do not fail if this one is cancelled
+		fetchCtx := utility.DetachedContext(ctx)
+		fetched, waited, err := s.singleFlight.Do(strings.Join(missingIds, "\n"), func() (any, error) {
+			return s.fetchAndCache(fetchCtx, missingIds)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if waited {
+			s.cache.AddCollapsedLookups(1)
+		}
+
+		for id, nodeJson := range fetched.(map[string][]byte) {
+			nodesJson[id] = nodeJson
+		}
+	}
+
+	ret := map[string]any{}
+	for id, nodeJson := range nodesJson {
+		node, err := s.decode(id, nodeJson)
+		if err != nil {
+			return nil, err
+		}
+		ret[id] = node
+	}
+	return ret, nil
+}
+
+// the cache holds the nodes encoded again, the ledger json is always read
+func (s *nodeServiceCached) FetchNodesJsonByIds(ctx context.Context, ids map[string]bool) (map[string]json.RawMessage, error) {
+	return s.nodeService.FetchNodesJsonByIds(ctx, ids)
+}
+
+func (s *nodeServiceCached) fetchAndCache(ctx context.Context, ids []string) (map[string][]byte, error) {
+	idsMap := map[string]bool{}
+	for _, id := range ids {
+		idsMap[id] = true
+	}
+
+	nodes, err := s.nodeService.FetchNodesByIds(ctx, idsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string][]byte{}
+	for id, node := range nodes {
+		nodeJson, err := json.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+
+		isFinalized := false
+		if node, ok := node.(model_sig_graph.NodeI); ok {
+			isFinalized = node.BaseNode().IsFinalized
+		}
+		s.cache.Put(id, nodeJson, isFinalized)
+		ret[id] = nodeJson
+	}
+	return ret, nil
+}
+
+func (s *nodeServiceCached) decode(id string, nodeJson []byte) (any, error) {
+	node := model_sig_graph.Node{}
+	err := json.Unmarshal(nodeJson, &node)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeType, err := s.nodeTypes.Get(node.NodeType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: node %s: %s", utility.ErrInvalidState, id, err.Error())
+	}
+	return nodeType.Decode(nodeJson)
+}
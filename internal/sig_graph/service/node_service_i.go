@@ -1,9 +1,14 @@
 package service_sig_graph
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 type NodeServiceI interface {
 	DoNodeIdsExists(ctx context.Context, ids map[string]bool) (map[string]bool, error)
 	// return NotFound if any one id is not found
 	FetchNodesByIds(ctx context.Context, ids map[string]bool) (map[string]any, error)
+	// ledger json of the nodes byte for byte, as signed by their owners
+	FetchNodesJsonByIds(ctx context.Context, ids map[string]bool) (map[string]json.RawMessage, error)
 }
@@ -0,0 +1,231 @@
+package service_sig_graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sig_graph_scp/pkg/model"
+	"sig_graph_scp/pkg/utility"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// the data that is signed, built from the json of node according to its
+// signature_scheme field. NodeSigningServiceI uses this function and
+// NodeVerifyingServiceI uses it through nodeVerifyingPayload.
+func nodeSigningPayload(node any) (string, error) {
+	nodeJson, err := json.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+
+	schemeHolder := struct {
+		SignatureScheme model.ESignatureScheme `json:"signature_scheme"`
+	}{}
+	err = json.Unmarshal(nodeJson, &schemeHolder)
+	if err != nil {
+		return "", err
+	}
+
+	switch schemeHolder.SignatureScheme {
+	case model.ESignatureSchemeLegacy:
+		return legacySigningPayload(nodeJson)
+	case model.ESignatureSchemeCanonicalJsonV1:
+		return canonicalJsonV1SigningPayload(nodeJson)
+	default:
+		return "", fmt.Errorf("%w: unsupported signature scheme %s", utility.ErrInvalidArgument, schemeHolder.SignatureScheme)
+	}
+}
+
+// the data whose signature is verified. Since sgp-canonical-json-v1 the
+// owner of a created asset signs the parent edges that the smart contract
+// adds. Older releases signed it without them, so a legacy asset that is
+// still as it was created is verified without its parent edges
+func nodeVerifyingPayload(node any) (string, error) {
+	nodeMap, err := nodeToMap(node)
+	if err != nil {
+		return "", err
+	}
+
+	scheme, _ := nodeMap["signature_scheme"].(string)
+	creationProcess, _ := nodeMap["creation_process"].(string)
+	isFinalized, _ := nodeMap["is_finalized"].(bool)
+	if scheme == model.ESignatureSchemeLegacy && creationProcess == model.ECreationProcessCreate && !isFinalized {
+		nodeMap["public_parents_ids"] = map[string]any{}
+		nodeMap["private_parents_hashed_ids"] = map[string]any{}
+		return nodeSigningPayload(nodeMap)
+	}
+
+	return nodeSigningPayload(node)
+}
+
+// node json re-encoded through a map[string]any, numbers become float64
+func legacySigningPayload(nodeJson []byte) (string, error) {
+	nodeMap := map[string]any{}
+	err := json.Unmarshal(nodeJson, &nodeMap)
+	if err != nil {
+		return "", err
+	}
+
+	delete(nodeMap, "signature")
+	nodeWithoutSignatureJson, err := json.Marshal(nodeMap)
+	if err != nil {
+		return "", err
+	}
+
+	return string(nodeWithoutSignatureJson), nil
+}
+
+// canonical json of the node without its signature field:
+//   - no insignificant whitespace
+//   - object keys are sorted by their UTF-16 code units, as in RFC 8785
+//   - strings must be valid UTF-8. Only '"', '\' and control characters are
+//     escaped, using \b \t \n \f \r or \u00xx (lower case hex), as in RFC 8785
+//   - numbers must be integers, written in base 10 without exponent, leading
+//     zeros or '+'. Non integer values such as quantities are encoded as strings
+func canonicalJsonV1SigningPayload(nodeJson []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(nodeJson))
+	decoder.UseNumber()
+
+	nodeMap := map[string]any{}
+	err := decoder.Decode(&nodeMap)
+	if err != nil {
+		return "", err
+	}
+
+	delete(nodeMap, "signature")
+
+	buffer := bytes.Buffer{}
+	err = writeCanonicalJson(&buffer, nodeMap)
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+var canonicalIntegerRegex = regexp.MustCompile(`^(0|-?[1-9][0-9]*)$`)
+
+func writeCanonicalJson(buffer *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		if v {
+			buffer.WriteString("true")
+		} else {
+			buffer.WriteString("false")
+		}
+	case json.Number:
+		if !canonicalIntegerRegex.MatchString(string(v)) {
+			return fmt.Errorf("%w: number %s is not an integer", utility.ErrInvalidArgument, v)
+		}
+		buffer.WriteString(string(v))
+	case string:
+		return writeCanonicalJsonString(buffer, v)
+	case []any:
+		buffer.WriteByte('[')
+		for i := range v {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			err := writeCanonicalJson(buffer, v[i])
+			if err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUtf16(keys[i], keys[j])
+		})
+
+		buffer.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			err := writeCanonicalJsonString(buffer, key)
+			if err != nil {
+				return err
+			}
+			buffer.WriteByte(':')
+			err = writeCanonicalJson(buffer, v[key])
+			if err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	default:
+		return fmt.Errorf("%w: unsupported json value %T", utility.ErrInvalidArgument, value)
+	}
+
+	return nil
+}
+
+func writeCanonicalJsonString(buffer *bytes.Buffer, value string) error {
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%w: string is not valid utf-8", utility.ErrInvalidArgument)
+	}
+
+	buffer.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buffer, `\u%04x`, r)
+			} else {
+				buffer.WriteRune(r)
+			}
+		}
+	}
+	buffer.WriteByte('"')
+
+	return nil
+}
+
+func lessUtf16(a string, b string) bool {
+	aUnits := utf16.Encode([]rune(a))
+	bUnits := utf16.Encode([]rune(b))
+	for i := 0; i < len(aUnits) && i < len(bUnits); i++ {
+		if aUnits[i] != bUnits[i] {
+			return aUnits[i] < bUnits[i]
+		}
+	}
+	return len(aUnits) < len(bUnits)
+}
+
+func nodeToMap(node any) (map[string]any, error) {
+	nodeMap := map[string]any{}
+	nodeJson, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(nodeJson, &nodeMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeMap, nil
+}
@@ -4,44 +4,39 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha512"
-	"crypto/x509"
 	"encoding/base64"
-	"encoding/json"
-	"encoding/pem"
 	"fmt"
+	"sig_graph_scp/pkg/model"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 	"sig_graph_scp/pkg/utility"
 )
 
 type nodeSigningService struct {
+	scheme model.ESignatureScheme
 }
 
+// new nodes are signed with ESignatureSchemeCanonicalJsonV1
 func NewNodeSigningService() *nodeSigningService {
-	return &nodeSigningService{}
-}
-
-func (s *nodeSigningService) Sign(ctx context.Context, userKeyPair *model_sig_graph.UserKeyPair, node any) (string, error) {
-	nodeMap := map[string]any{}
-	nodeJson, err := json.Marshal(node)
-	if err != nil {
-		return "", err
+	return &nodeSigningService{
+		scheme: model.ESignatureSchemeCanonicalJsonV1,
 	}
+}
 
-	err = json.Unmarshal(nodeJson, &nodeMap)
-	if err != nil {
-		return "", err
-	}
+func (s *nodeSigningService) Scheme() model.ESignatureScheme {
+	return s.scheme
+}
 
-	delete(nodeMap, "signature")
-	nodeWithoutSignatureJson, err := json.Marshal(nodeMap)
+func (s *nodeSigningService) Sign(ctx context.Context, userKeyPair *model_sig_graph.UserKeyPair, node any) (string, error) {
+	nodeWithoutSignatureJson, err := nodeSigningPayload(node)
 	if err != nil {
 		return "", err
 	}
 
-	signature, err := s.sign(string(nodeWithoutSignatureJson), userKeyPair.Private)
+	signature, err := s.sign(nodeWithoutSignatureJson, userKeyPair.Private)
 	if err != nil {
 		return "", err
 	}
@@ -50,30 +45,33 @@ func (s *nodeSigningService) Sign(ctx context.Context, userKeyPair *model_sig_gr
 	return base64Signature, nil
 }
 
+// RSA and ECDSA keys sign the SHA-512 digest of data, Ed25519 keys sign data directly
 func (s *nodeSigningService) sign(data string, privateKey string) (string, error) {
-	block, _ := pem.Decode([]byte(privateKey))
-	privateKeyParsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	privateKeyParsed, _, err := utility.ParsePemPrivateKey(privateKey)
 	if err != nil {
 		return "", err
 	}
 
 	hash := sha512.Sum512([]byte(data))
 
-	if rsaKey, ok := privateKeyParsed.(*rsa.PrivateKey); ok {
-		signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA512, hash[:])
+	switch key := privateKeyParsed.(type) {
+	case *rsa.PrivateKey:
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA512, hash[:])
 		if err != nil {
 			return "", err
 		}
 
 		return string(signature), nil
-	} else if ecdsaKey, ok := privateKeyParsed.(*ecdsa.PrivateKey); ok {
-		signature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, hash[:])
+	case *ecdsa.PrivateKey:
+		signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
 		if err != nil {
 			return "", err
 		}
 
 		return string(signature), nil
-	} else {
+	case ed25519.PrivateKey:
+		return string(ed25519.Sign(key, []byte(data))), nil
+	default:
 		return "", fmt.Errorf("%w: unsupported signature algorithm", utility.ErrInvalidArgument)
 	}
 }
@@ -2,10 +2,14 @@ package service_sig_graph
 
 import (
 	"context"
+	"sig_graph_scp/pkg/model"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 )
 
-// return base64 encoded signature
 type NodeSigningServiceI interface {
+	// return base64 encoded signature. The payload is built according to
+	// the signature_scheme field of node, which is part of the signed data
 	Sign(ctx context.Context, userKeyPair *model_sig_graph.UserKeyPair, node any) (string, error)
+	// scheme that new nodes should be signed with
+	Scheme() model.ESignatureScheme
 }
@@ -0,0 +1,192 @@
+package service_sig_graph
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+type signingVector struct {
+	Name          string          `json:"name"`
+	Node          json.RawMessage `json:"node"`
+	Payload       string          `json:"payload"`
+	PayloadSha512 string          `json:"payload_sha512"`
+	PrivateKey    string          `json:"private_key"`
+	PublicKey     string          `json:"public_key"`
+	Scheme        string          `json:"scheme"`
+	Signature     string          `json:"signature"`
+}
+
+func readSigningVectors(t *testing.T) []signingVector {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/signing_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vectors := struct {
+		Vectors []signingVector `json:"vectors"`
+	}{}
+	err = json.Unmarshal(data, &vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors.Vectors) == 0 {
+		t.Fatal("no signing vector")
+	}
+	return vectors.Vectors
+}
+
+// the node of vector as a generic map keeping its numbers, and as the asset
+// the client signs
+func decodeSigningVectorNode(t *testing.T, vector signingVector) (map[string]any, *model_sig_graph.Asset) {
+	t.Helper()
+
+	decoder := json.NewDecoder(bytes.NewReader(vector.Node))
+	decoder.UseNumber()
+	nodeMap := map[string]any{}
+	err := decoder.Decode(&nodeMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset := &model_sig_graph.Asset{}
+	err = json.Unmarshal(vector.Node, asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return nodeMap, asset
+}
+
+func TestNodeSigningServiceSchemeIsCanonicalJsonV1(t *testing.T) {
+	if scheme := NewNodeSigningService().Scheme(); scheme != model.ESignatureSchemeCanonicalJsonV1 {
+		t.Fatalf("expected scheme %s, got %s", model.ESignatureSchemeCanonicalJsonV1, scheme)
+	}
+}
+
+func TestNodeSigningPayloadMatchesVectors(t *testing.T) {
+	for _, vector := range readSigningVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			nodeMap, asset := decodeSigningVectorNode(t, vector)
+			if nodeMap["signature_scheme"] != nil && nodeMap["signature_scheme"] != vector.Scheme {
+				t.Fatalf("node has scheme %v, vector %s", nodeMap["signature_scheme"], vector.Scheme)
+			}
+
+			for name, node := range map[string]any{"map": nodeMap, "asset": asset} {
+				payload, err := nodeSigningPayload(node)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if payload != vector.Payload {
+					t.Fatalf("%s: payload\n%s\ndoes not match\n%s", name, payload, vector.Payload)
+				}
+			}
+
+			digest := sha512.Sum512([]byte(vector.Payload))
+			if hex.EncodeToString(digest[:]) != vector.PayloadSha512 {
+				t.Fatal("payload_sha512 does not match payload")
+			}
+		})
+	}
+}
+
+func TestNodeSigningServiceMatchesVectors(t *testing.T) {
+	signingService := NewNodeSigningService()
+	verifyingService := NewNodeVerifyingService()
+
+	for _, vector := range readSigningVectors(t) {
+		t.Run(vector.Name, func(t *testing.T) {
+			_, asset := decodeSigningVectorNode(t, vector)
+
+			err := verifyingService.Verify(context.Background(), vector.PublicKey, asset, vector.Signature)
+			if err != nil {
+				t.Fatalf("signature of the vector: %v", err)
+			}
+
+			// ECDSA signatures are randomized
+			if vector.PrivateKey == "" {
+				return
+			}
+
+			signature, err := signingService.Sign(
+				context.Background(),
+				&model_sig_graph.UserKeyPair{Public: vector.PublicKey, Private: vector.PrivateKey},
+				asset,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signature != vector.Signature {
+				t.Fatalf("signature\n%s\ndoes not match\n%s", signature, vector.Signature)
+			}
+		})
+	}
+}
+
+// an asset created with an ingredient. sign is called before the smart
+// contract adds the parent edge, as older releases did
+func newLegacyCreatedAsset(t *testing.T, scheme model.ESignatureScheme, owner *model_sig_graph.UserKeyPair) *model_sig_graph.Asset {
+	t.Helper()
+
+	asset := model_sig_graph.NewAsset(
+		model_sig_graph.NewDefaultNode("sgp://memory:[]:public:bread", model.ENodeTypeAsset, 1, 1, "", owner.Public),
+		model.ECreationProcessCreate,
+		"kg",
+		decimal.NewFromInt(1),
+		"bread",
+	)
+	asset.SignatureScheme = scheme
+	signature, err := NewNodeSigningService().Sign(context.Background(), owner, asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asset.Signature = signature
+	asset.PublicParentsIds["sgp://memory:[]:public:flour"] = true
+	asset.PrivateParentsHashedIds["hash of water"] = true
+	return &asset
+}
+
+func TestNodeVerifyingServiceLegacyCreatedAsset(t *testing.T) {
+	owner := newAssetServiceTestKeyPair(t)
+	verifyingService := NewNodeVerifyingService()
+
+	legacy := newLegacyCreatedAsset(t, model.ESignatureSchemeLegacy, owner)
+	err := verifyingService.VerifyNode(context.Background(), legacy)
+	if err != nil {
+		t.Fatalf("expected the legacy signature to stay valid, got %v", err)
+	}
+
+	// the other fields are still covered
+	legacy.Quantity = decimal.NewFromInt(2)
+	err = verifyingService.VerifyNode(context.Background(), legacy)
+	if !errors.Is(err, utility.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for a changed quantity, got %v", err)
+	}
+
+	// a finalized asset carries the signature of its finalization
+	legacy = newLegacyCreatedAsset(t, model.ESignatureSchemeLegacy, owner)
+	legacy.IsFinalized = true
+	err = verifyingService.VerifyNode(context.Background(), legacy)
+	if !errors.Is(err, utility.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for a finalized asset, got %v", err)
+	}
+
+	// sgp-canonical-json-v1 signatures cover the parent edges
+	canonical := newLegacyCreatedAsset(t, model.ESignatureSchemeCanonicalJsonV1, owner)
+	err = verifyingService.VerifyNode(context.Background(), canonical)
+	if !errors.Is(err, utility.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature without signed parent edges, got %v", err)
+	}
+}
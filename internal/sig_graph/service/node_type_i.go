@@ -0,0 +1,20 @@
+package service_sig_graph
+
+import (
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+)
+
+// kind of node stored on the ledger, selected by the "type" field of the node
+type NodeTypeI interface {
+	NodeType() model.ENodeType
+	// decode the ledger json of a node of this type
+	Decode(nodeJson []byte) (model_sig_graph.NodeI, error)
+}
+
+type NodeTypeRegistryI interface {
+	// return ErrAlreadyExists if a node type with the same name is registered
+	Register(nodeType NodeTypeI) error
+	// return ErrNotFound if no such node type is registered
+	Get(nodeType model.ENodeType) (NodeTypeI, error)
+}
@@ -0,0 +1,68 @@
+package service_sig_graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sync"
+)
+
+type nodeTypeRegistry struct {
+	mtx       sync.RWMutex
+	nodeTypes map[model.ENodeType]NodeTypeI
+}
+
+var _ NodeTypeRegistryI = (*nodeTypeRegistry)(nil)
+
+// registry of the built-in node types
+func NewNodeTypeRegistry() *nodeTypeRegistry {
+	return &nodeTypeRegistry{
+		nodeTypes: map[model.ENodeType]NodeTypeI{
+			model.ENodeTypeAsset: NewAssetNodeType(),
+		},
+	}
+}
+
+func (r *nodeTypeRegistry) Register(nodeType NodeTypeI) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.nodeTypes[nodeType.NodeType()]; ok {
+		return fmt.Errorf("%w: node type %s", utility.ErrAlreadyExists, nodeType.NodeType())
+	}
+	r.nodeTypes[nodeType.NodeType()] = nodeType
+	return nil
+}
+
+func (r *nodeTypeRegistry) Get(nodeType model.ENodeType) (NodeTypeI, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	ret, ok := r.nodeTypes[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: node type %s", utility.ErrNotFound, nodeType)
+	}
+	return ret, nil
+}
+
+type assetNodeType struct {
+}
+
+func NewAssetNodeType() *assetNodeType {
+	return &assetNodeType{}
+}
+
+func (t *assetNodeType) NodeType() model.ENodeType {
+	return model.ENodeTypeAsset
+}
+
+func (t *assetNodeType) Decode(nodeJson []byte) (model_sig_graph.NodeI, error) {
+	asset := model_sig_graph.Asset{}
+	err := json.Unmarshal(nodeJson, &asset)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
@@ -0,0 +1,83 @@
+package service_sig_graph
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"sig_graph_scp/pkg/utility"
+)
+
+type nodeVerifyingService struct {
+}
+
+func NewNodeVerifyingService() *nodeVerifyingService {
+	return &nodeVerifyingService{}
+}
+
+func (s *nodeVerifyingService) Verify(ctx context.Context, publicKey string, node any, signature string) error {
+	payload, err := nodeVerifyingPayload(node)
+	if err != nil {
+		return err
+	}
+
+	return s.verify(payload, publicKey, signature)
+}
+
+func (s *nodeVerifyingService) VerifyNode(ctx context.Context, node any) error {
+	nodeMap, err := nodeToMap(node)
+	if err != nil {
+		return err
+	}
+
+	signature, _ := nodeMap["signature"].(string)
+	ownerPublicKey, _ := nodeMap["owner_public_key"].(string)
+	if signature == "" {
+		return fmt.Errorf("%w: node %v is not signed", utility.ErrInvalidSignature, nodeMap["id"])
+	}
+
+	err = s.Verify(ctx, ownerPublicKey, node, signature)
+	if err != nil {
+		return fmt.Errorf("node %v: %w", nodeMap["id"], err)
+	}
+
+	return nil
+}
+
+func (s *nodeVerifyingService) verify(data string, publicKey string, signature string) error {
+	publicKeyParsed, _, err := utility.ParsePemPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64 encoded", utility.ErrInvalidSignature)
+	}
+
+	hash := sha512.Sum512([]byte(data))
+
+	switch key := publicKeyParsed.(type) {
+	case *rsa.PublicKey:
+		err := rsa.VerifyPKCS1v15(key, crypto.SHA512, hash[:], signatureBytes)
+		if err != nil {
+			return utility.ErrInvalidSignature
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], signatureBytes) {
+			return utility.ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, []byte(data), signatureBytes) {
+			return utility.ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("%w: unsupported signature algorithm", utility.ErrInvalidArgument)
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package service_sig_graph
+
+import "context"
+
+// verify signatures created by NodeSigningServiceI
+// return ErrInvalidSignature if the signature does not match
+type NodeVerifyingServiceI interface {
+	// signature is base64 encoded, publicKey is pem encoded
+	Verify(ctx context.Context, publicKey string, node any, signature string) error
+	// verify node against its own signature and owner public key
+	VerifyNode(ctx context.Context, node any) error
+}
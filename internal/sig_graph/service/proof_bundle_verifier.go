@@ -0,0 +1,222 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sort"
+)
+
+type proofBundleVerifier struct {
+	verifyingService NodeVerifyingServiceI
+	hashGenerator    utility.HashedIdGeneratorServiceI
+}
+
+func NewProofBundleVerifier(
+	verifyingService NodeVerifyingServiceI,
+	hashGenerator utility.HashedIdGeneratorServiceI,
+) *proofBundleVerifier {
+	return &proofBundleVerifier{
+		verifyingService: verifyingService,
+		hashGenerator:    hashGenerator,
+	}
+}
+
+func (v *proofBundleVerifier) Verify(
+	ctx context.Context,
+	bundle *model_sig_graph.ProofBundle,
+	trustedPublicKeys []string,
+) (*model_sig_graph.ProofBundleReport, error) {
+	if bundle.Version != model_sig_graph.ProofBundleVersionV1 {
+		return nil, fmt.Errorf("%w: unsupported proof bundle version %q", utility.ErrInvalidArgument, bundle.Version)
+	}
+
+	// the keys of the bundle are not trusted, anyone can sign nodes with them
+	trustedKeys := map[string]bool{}
+	for _, publicKey := range trustedPublicKeys {
+		der, err := utility.PkixOfPemPublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		trustedKeys[string(der)] = true
+	}
+	if len(trustedKeys) == 0 {
+		return nil, fmt.Errorf("%w: no trusted public key", utility.ErrInvalidArgument)
+	}
+
+	// every node is signed by a trusted owner
+	nodes := map[string]model_sig_graph.Node{}
+	for _, rawNode := range bundle.Nodes {
+		node := model_sig_graph.Node{}
+		err := json.Unmarshal(rawNode, &node)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not decode node: %s", utility.ErrInvalidArgument, err.Error())
+		}
+		if node.Id == "" {
+			return nil, fmt.Errorf("%w: node without id", utility.ErrInvalidArgument)
+		}
+		if _, ok := nodes[node.Id]; ok {
+			return nil, fmt.Errorf("%w: duplicated node %s", utility.ErrInvalidArgument, node.Id)
+		}
+		ownerDer, err := utility.PkixOfPemPublicKey(node.OwnerPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: owner of node %s is not a public key", utility.ErrInvalidArgument, node.Id)
+		}
+		if !trustedKeys[string(ownerDer)] {
+			return nil, fmt.Errorf("%w: owner of node %s is not trusted", utility.ErrPermissionDenied, node.Id)
+		}
+
+		err = v.verifyingService.VerifyNode(ctx, rawNode)
+		if err != nil {
+			return nil, err
+		}
+		nodes[node.Id] = node
+	}
+
+	if _, ok := nodes[bundle.RootId]; !ok {
+		return nil, fmt.Errorf("%w: root %s is not in the bundle", utility.ErrInvalidArgument, bundle.RootId)
+	}
+
+	// hashed ids of the nodes opened by their secrets
+	openedHashes := map[string]map[string]bool{}
+	secretHashes := make([]string, 0, len(bundle.Secrets))
+	for _, secret := range bundle.Secrets {
+		if _, ok := nodes[secret.NodeId]; !ok {
+			return nil, fmt.Errorf("%w: secret of node %s which is not in the bundle", utility.ErrInvalidArgument, secret.NodeId)
+		}
+
+		hash, err := v.hashGenerator.GenerateHashedId(ctx, secret.NodeId, secret.Secret)
+		if err != nil {
+			return nil, err
+		}
+		if openedHashes[secret.NodeId] == nil {
+			openedHashes[secret.NodeId] = map[string]bool{}
+		}
+		openedHashes[secret.NodeId][hash] = true
+		secretHashes = append(secretHashes, hash)
+	}
+
+	// the edge is on the parent, publicly or behind an opened hash of the child
+	isOnParent := func(parentId string, childId string) bool {
+		if nodes[parentId].PublicChildrenIds[childId] {
+			return true
+		}
+		for hash := range openedHashes[childId] {
+			if nodes[parentId].PrivateChildrenHashedIds[hash] {
+				return true
+			}
+		}
+		return false
+	}
+	isOnChild := func(parentId string, childId string) bool {
+		if nodes[childId].PublicParentsIds[parentId] {
+			return true
+		}
+		for hash := range openedHashes[parentId] {
+			if nodes[childId].PrivateParentsHashedIds[hash] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// a secret opens the private edges whose hash is the one of its node,
+	// the node at the other end must have the edge too
+	privateEdges := map[model_sig_graph.ProofBundleEdge]bool{}
+	for i, secret := range bundle.Secrets {
+		hash := secretHashes[i]
+		isOpened := false
+		for id, node := range nodes {
+			if node.PrivateParentsHashedIds[hash] {
+				if !isOnParent(secret.NodeId, id) {
+					return nil, fmt.Errorf("%w: edge from %s to %s is not on %s", utility.ErrInvalidArgument, secret.NodeId, id, secret.NodeId)
+				}
+				privateEdges[model_sig_graph.ProofBundleEdge{ParentId: secret.NodeId, ChildId: id, IsPrivate: true}] = true
+				isOpened = true
+			}
+			if node.PrivateChildrenHashedIds[hash] {
+				if !isOnChild(id, secret.NodeId) {
+					return nil, fmt.Errorf("%w: edge from %s to %s is not on %s", utility.ErrInvalidArgument, id, secret.NodeId, secret.NodeId)
+				}
+				privateEdges[model_sig_graph.ProofBundleEdge{ParentId: id, ChildId: secret.NodeId, IsPrivate: true}] = true
+				isOpened = true
+			}
+		}
+		if !isOpened {
+			return nil, fmt.Errorf("%w: secret of node %s opens no edge", utility.ErrInvalidArgument, secret.NodeId)
+		}
+	}
+
+	// a public edge between two nodes of the bundle is on both nodes, the
+	// other side may be private
+	publicEdges := map[model_sig_graph.ProofBundleEdge]bool{}
+	for id, node := range nodes {
+		for childId := range node.PublicChildrenIds {
+			if _, ok := nodes[childId]; !ok {
+				continue
+			}
+			if !isOnChild(id, childId) {
+				return nil, fmt.Errorf("%w: edge from %s to %s is not on %s", utility.ErrInvalidArgument, id, childId, childId)
+			}
+			publicEdges[model_sig_graph.ProofBundleEdge{ParentId: id, ChildId: childId}] = true
+		}
+
+		for parentId := range node.PublicParentsIds {
+			if _, ok := nodes[parentId]; !ok {
+				continue
+			}
+			if !isOnParent(parentId, id) {
+				return nil, fmt.Errorf("%w: edge from %s to %s is not on %s", utility.ErrInvalidArgument, parentId, id, parentId)
+			}
+			publicEdges[model_sig_graph.ProofBundleEdge{ParentId: parentId, ChildId: id}] = true
+		}
+	}
+
+	report := &model_sig_graph.ProofBundleReport{
+		RootId:  bundle.RootId,
+		NodeIds: []string{},
+		Edges:   []model_sig_graph.ProofBundleEdge{},
+	}
+	neighbours := map[string][]string{}
+	for _, edges := range []map[model_sig_graph.ProofBundleEdge]bool{publicEdges, privateEdges} {
+		for edge := range edges {
+			report.Edges = append(report.Edges, edge)
+			neighbours[edge.ParentId] = append(neighbours[edge.ParentId], edge.ChildId)
+			neighbours[edge.ChildId] = append(neighbours[edge.ChildId], edge.ParentId)
+		}
+	}
+
+	// every node is connected to the root
+	connected := map[string]bool{bundle.RootId: true}
+	toVisit := []string{bundle.RootId}
+	for len(toVisit) > 0 {
+		id := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+		for _, neighbour := range neighbours[id] {
+			if !connected[neighbour] {
+				connected[neighbour] = true
+				toVisit = append(toVisit, neighbour)
+			}
+		}
+	}
+	for id := range nodes {
+		if !connected[id] {
+			return nil, fmt.Errorf("%w: node %s is not connected to the root %s", utility.ErrInvalidArgument, id, bundle.RootId)
+		}
+		report.NodeIds = append(report.NodeIds, id)
+	}
+
+	sort.Strings(report.NodeIds)
+	sort.Slice(report.Edges, func(i, j int) bool {
+		if report.Edges[i].ParentId != report.Edges[j].ParentId {
+			return report.Edges[i].ParentId < report.Edges[j].ParentId
+		}
+		if report.Edges[i].ChildId != report.Edges[j].ChildId {
+			return report.Edges[i].ChildId < report.Edges[j].ChildId
+		}
+		return !report.Edges[i].IsPrivate && report.Edges[j].IsPrivate
+	})
+	return report, nil
+}
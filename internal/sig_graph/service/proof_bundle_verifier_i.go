@@ -0,0 +1,18 @@
+package service_sig_graph
+
+import (
+	"context"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+)
+
+// verify a proof bundle without access to the ledger
+type ProofBundleVerifierI interface {
+	// return ErrInvalidSignature if a node signature does not match,
+	// ErrPermissionDenied if a node is not owned by one of the pem encoded
+	// trustedPublicKeys and ErrInvalidArgument if the bundle is not consistent
+	Verify(
+		ctx context.Context,
+		bundle *model_sig_graph.ProofBundle,
+		trustedPublicKeys []string,
+	) (*model_sig_graph.ProofBundleReport, error)
+}
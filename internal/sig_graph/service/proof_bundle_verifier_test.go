@@ -0,0 +1,260 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	proofBundleTestParentId = "sgp://memory:[]:public:parent"
+	proofBundleTestChildId  = "sgp://memory:[]:public:child"
+	proofBundleTestHiddenId = "sgp://memory:[]:public:hidden"
+)
+
+// ledger json of an asset as a map, so that fields unknown to the model can
+// be added before signing
+func newProofBundleTestNode(t *testing.T, id string, owner *model_sig_graph.UserKeyPair) map[string]any {
+	t.Helper()
+
+	asset := model_sig_graph.NewAsset(
+		model_sig_graph.NewDefaultNode(id, model.ENodeTypeAsset, 1, 1, "", owner.Public),
+		model.ECreationProcessCreate,
+		"kg",
+		decimal.NewFromInt(10),
+		"flour",
+	)
+	assetJson, err := json.Marshal(asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := map[string]any{}
+	err = json.Unmarshal(assetJson, &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func signProofBundleTestNode(t *testing.T, node map[string]any, owner *model_sig_graph.UserKeyPair) json.RawMessage {
+	t.Helper()
+
+	signingService := NewNodeSigningService()
+	node["signature_scheme"] = signingService.Scheme()
+	signature, err := signingService.Sign(context.Background(), owner, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node["signature"] = signature
+
+	nodeJson, err := json.Marshal(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nodeJson
+}
+
+type proofBundleTest struct {
+	bundle      *model_sig_graph.ProofBundle
+	parentOwner *model_sig_graph.UserKeyPair
+	childOwner  *model_sig_graph.UserKeyPair
+}
+
+// parent with a public edge to child, child with a private edge to hidden.
+// The parent carries a field written by the chaincode that the model does
+// not know. tamper changes the nodes before they are signed
+func newProofBundleTest(t *testing.T, tamper func(nodes map[string]map[string]any)) *proofBundleTest {
+	t.Helper()
+
+	ctx := context.Background()
+	parentOwner := newAssetServiceTestKeyPair(t)
+	childOwner := newAssetServiceTestKeyPair(t)
+	hashGenerator := utility.NewHashedIdGeneratorService()
+	childHash, err := hashGenerator.GenerateHashedId(ctx, proofBundleTestChildId, "child secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hiddenHash, err := hashGenerator.GenerateHashedId(ctx, proofBundleTestHiddenId, "hidden secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := newProofBundleTestNode(t, proofBundleTestParentId, parentOwner)
+	parent["public_children_ids"] = map[string]bool{proofBundleTestChildId: true}
+	parent["chaincode_field"] = "kept"
+	child := newProofBundleTestNode(t, proofBundleTestChildId, childOwner)
+	child["public_parents_ids"] = map[string]bool{proofBundleTestParentId: true}
+	child["private_children_hashed_ids"] = map[string]bool{hiddenHash: true}
+	hidden := newProofBundleTestNode(t, proofBundleTestHiddenId, childOwner)
+	hidden["private_parents_hashed_ids"] = map[string]bool{childHash: true}
+
+	nodes := map[string]map[string]any{
+		proofBundleTestParentId: parent,
+		proofBundleTestChildId:  child,
+		proofBundleTestHiddenId: hidden,
+	}
+	if tamper != nil {
+		tamper(nodes)
+	}
+
+	return &proofBundleTest{
+		bundle: &model_sig_graph.ProofBundle{
+			Version:   model_sig_graph.ProofBundleVersionV1,
+			GraphName: "memory",
+			RootId:    proofBundleTestChildId,
+			Nodes: []json.RawMessage{
+				signProofBundleTestNode(t, parent, parentOwner),
+				signProofBundleTestNode(t, child, childOwner),
+				signProofBundleTestNode(t, hidden, childOwner),
+			},
+			Secrets: []model_sig_graph.ProofBundleSecret{
+				{NodeId: proofBundleTestChildId, Secret: "child secret"},
+				{NodeId: proofBundleTestHiddenId, Secret: "hidden secret"},
+			},
+			PublicKeys: []string{parentOwner.Public, childOwner.Public},
+		},
+		parentOwner: parentOwner,
+		childOwner:  childOwner,
+	}
+}
+
+func (b *proofBundleTest) verify(trustedPublicKeys []string) (*model_sig_graph.ProofBundleReport, error) {
+	return NewProofBundleVerifier(NewNodeVerifyingService(), utility.NewHashedIdGeneratorService()).Verify(
+		context.Background(),
+		b.bundle,
+		trustedPublicKeys,
+	)
+}
+
+func TestProofBundleVerifierValid(t *testing.T) {
+	test := newProofBundleTest(t, nil)
+
+	report, err := test.verify([]string{test.parentOwner.Public, test.childOwner.Public})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &model_sig_graph.ProofBundleReport{
+		RootId:  proofBundleTestChildId,
+		NodeIds: []string{proofBundleTestChildId, proofBundleTestHiddenId, proofBundleTestParentId},
+		Edges: []model_sig_graph.ProofBundleEdge{
+			{ParentId: proofBundleTestChildId, ChildId: proofBundleTestHiddenId, IsPrivate: true},
+			{ParentId: proofBundleTestParentId, ChildId: proofBundleTestChildId},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Fatalf("expected report %+v, got %+v", expected, report)
+	}
+}
+
+func TestProofBundleVerifierNeedsLedgerJson(t *testing.T) {
+	test := newProofBundleTest(t, nil)
+
+	// the model drops the fields it does not know, the signature covers them
+	asset := model_sig_graph.Asset{}
+	err := json.Unmarshal(test.bundle.Nodes[0], &asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.bundle.Nodes[0], err = json.Marshal(asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = test.verify([]string{test.parentOwner.Public, test.childOwner.Public})
+	if !errors.Is(err, utility.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for a node encoded again, got %v", err)
+	}
+}
+
+func TestProofBundleVerifierInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		// changes the nodes before they are signed
+		tamperNodes func(nodes map[string]map[string]any)
+		// changes the signed bundle
+		tamperBundle func(test *proofBundleTest)
+		// trusts only the owner of the child if set
+		isParentUntrusted bool
+		err               error
+	}{
+		{"unsupported version", nil, func(test *proofBundleTest) {
+			test.bundle.Version = "sgp-proof-bundle-v0"
+		}, false, utility.ErrInvalidArgument},
+		{"changed node", nil, func(test *proofBundleTest) {
+			node := map[string]any{}
+			err := json.Unmarshal(test.bundle.Nodes[0], &node)
+			if err != nil {
+				panic(err)
+			}
+			node["quantity"] = "11"
+			test.bundle.Nodes[0], err = json.Marshal(node)
+			if err != nil {
+				panic(err)
+			}
+		}, false, utility.ErrInvalidSignature},
+		{"owner not trusted", nil, nil, true, utility.ErrPermissionDenied},
+		{"duplicated node", nil, func(test *proofBundleTest) {
+			test.bundle.Nodes = append(test.bundle.Nodes, test.bundle.Nodes[0])
+		}, false, utility.ErrInvalidArgument},
+		{"root not in the bundle", nil, func(test *proofBundleTest) {
+			test.bundle.RootId = "sgp://memory:[]:public:missing"
+		}, false, utility.ErrInvalidArgument},
+		{"secret of a node not in the bundle", nil, func(test *proofBundleTest) {
+			test.bundle.Secrets = append(test.bundle.Secrets, model_sig_graph.ProofBundleSecret{
+				NodeId: "sgp://memory:[]:public:missing",
+				Secret: "secret",
+			})
+		}, false, utility.ErrInvalidArgument},
+		{"secret opening no edge", nil, func(test *proofBundleTest) {
+			test.bundle.Secrets[1].Secret = "wrong secret"
+		}, false, utility.ErrInvalidArgument},
+		{"private edge without secrets", nil, func(test *proofBundleTest) {
+			test.bundle.Secrets = []model_sig_graph.ProofBundleSecret{}
+		}, false, utility.ErrInvalidArgument},
+		{"public edge only on the parent", func(nodes map[string]map[string]any) {
+			nodes[proofBundleTestChildId]["public_parents_ids"] = map[string]bool{}
+		}, nil, false, utility.ErrInvalidArgument},
+		{"private edge only on the parent", func(nodes map[string]map[string]any) {
+			nodes[proofBundleTestHiddenId]["private_parents_hashed_ids"] = map[string]bool{}
+		}, nil, false, utility.ErrInvalidArgument},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			test := newProofBundleTest(t, c.tamperNodes)
+			if c.tamperBundle != nil {
+				c.tamperBundle(test)
+			}
+			trustedPublicKeys := []string{test.parentOwner.Public, test.childOwner.Public}
+			if c.isParentUntrusted {
+				trustedPublicKeys = []string{test.childOwner.Public}
+			}
+
+			report, err := test.verify(trustedPublicKeys)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+			if report != nil {
+				t.Fatal("expected no report")
+			}
+		})
+	}
+}
+
+func TestProofBundleVerifierWithoutTrustedKeys(t *testing.T) {
+	test := newProofBundleTest(t, nil)
+
+	// the keys of the bundle are not trusted by themselves
+	_, err := test.verify(nil)
+	if !errors.Is(err, utility.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package service_sig_graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sig_graph_scp/pkg/utility"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ESmartContractErrorCode string
+
+// codes returned by the SigGraph chaincode
+const (
+	ESmartContractErrorCodeNotFound         ESmartContractErrorCode = "NOT_FOUND"
+	ESmartContractErrorCodeAlreadyExists    ESmartContractErrorCode = "ALREADY_EXISTS"
+	ESmartContractErrorCodeInvalidArgument  ESmartContractErrorCode = "INVALID_ARGUMENT"
+	ESmartContractErrorCodeInvalidSignature ESmartContractErrorCode = "INVALID_SIGNATURE"
+	ESmartContractErrorCodeAlreadyFinalized ESmartContractErrorCode = "ALREADY_FINALIZED"
+	ESmartContractErrorCodePermissionDenied ESmartContractErrorCode = "PERMISSION_DENIED"
+	ESmartContractErrorCodeTransient        ESmartContractErrorCode = "TRANSIENT"
+)
+
+var smartContractErrorCodes = map[ESmartContractErrorCode]error{
+	ESmartContractErrorCodeNotFound:         utility.ErrNotFound,
+	ESmartContractErrorCodeAlreadyExists:    utility.ErrAlreadyExists,
+	ESmartContractErrorCodeInvalidArgument:  utility.ErrInvalidArgument,
+	ESmartContractErrorCodeInvalidSignature: utility.ErrInvalidSignature,
+	ESmartContractErrorCodeAlreadyFinalized: utility.ErrAlreadyFinalized,
+	ESmartContractErrorCodePermissionDenied: utility.ErrPermissionDenied,
+	ESmartContractErrorCodeTransient:        utility.ErrTransient,
+}
+
+// failure of a smart contract function. errors.Is matches
+// utility.ErrSmartContractError and the error of Code, if any.
+type SmartContractError struct {
+	FunctionName string
+	// empty when the chaincode did not return a known code
+	Code    ESmartContractErrorCode
+	Message string
+	cause   error
+}
+
+func (e *SmartContractError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%s for function %s: %s", utility.ErrSmartContractError, e.FunctionName, e.Message)
+	}
+	return fmt.Sprintf("%s for function %s: %s: %s", utility.ErrSmartContractError, e.FunctionName, e.Code, e.Message)
+}
+
+func (e *SmartContractError) Is(target error) bool {
+	if target == utility.ErrSmartContractError {
+		return true
+	}
+
+	codeErr, ok := smartContractErrorCodes[e.Code]
+	return ok && errors.Is(codeErr, target)
+}
+
+func (e *SmartContractError) Unwrap() error {
+	return e.cause
+}
+
+// the chaincode reports errors as json {"code": "...", "message": "..."}.
+// The peer may prefix it, e.g. "chaincode response 500, {...}".
+type chaincodeError struct {
+	Code    ESmartContractErrorCode `json:"code"`
+	Message string                  `json:"message"`
+}
+
+func parseChaincodeError(message string) (ESmartContractErrorCode, string) {
+	if start := strings.Index(message, "{"); start >= 0 {
+		ret := chaincodeError{}
+		err := json.Unmarshal([]byte(message[start:]), &ret)
+		if err == nil && ret.Code != "" {
+			if _, ok := smartContractErrorCodes[ret.Code]; !ok {
+				return "", message
+			}
+			return ret.Code, ret.Message
+		}
+	}
+
+	// chaincodes without error codes
+	if strings.Contains(message, "not found") {
+		return ESmartContractErrorCodeNotFound, message
+	}
+	return "", message
+}
+
+func wrapError(functionName string, err error) error {
+	statusErr := status.Convert(err)
+	ret := &SmartContractError{
+		FunctionName: functionName,
+		Message:      statusErr.Message(),
+		cause:        err,
+	}
+
+	switch statusErr.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		ret.Code = ESmartContractErrorCodeTransient
+	}
+
+	// one detail per endorsing peer, keep the first with a code
+	for _, detail := range statusErr.Details() {
+		switch detail := detail.(type) {
+		case *gateway.ErrorDetail:
+			code, message := parseChaincodeError(detail.Message)
+			if code != "" {
+				ret.Code, ret.Message = code, message
+				return ret
+			}
+			ret.Message = message
+		}
+	}
+	return ret
+}
+
+// validation codes for which submitting the transaction again may succeed
+var transientValidationCodes = map[peer.TxValidationCode]bool{
+	peer.TxValidationCode_MVCC_READ_CONFLICT:    true,
+	peer.TxValidationCode_PHANTOM_READ_CONFLICT: true,
+}
+
+func wrapCommitStatus(functionName string, status *client.Status) error {
+	ret := &SmartContractError{
+		FunctionName: functionName,
+		Message:      fmt.Sprintf("transaction %s is invalid with code %s", status.TransactionID, status.Code.String()),
+		cause:        utility.ErrTransactionFailed,
+	}
+	if transientValidationCodes[status.Code] {
+		ret.Code = ESmartContractErrorCodeTransient
+	}
+	return ret
+}
@@ -1,165 +1,142 @@
 package service_sig_graph
 
 import (
+	"context"
 	"crypto/x509"
 	"fmt"
 	utility_sig_graph "sig_graph_scp/internal/sig_graph/utility"
-	"sig_graph_scp/pkg/utility"
 	"strings"
+	"time"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 	"github.com/hyperledger/fabric-gateway/pkg/identity"
-	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
-	"go.uber.org/multierr"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type smartContractServiceHyperledger struct {
-	contract         *client.Contract
-	clientConnection *grpc.ClientConn
+	peerPool      *fabricPeerPool
+	channelName   string
+	chaincodeName string
+	contractName  string
 }
 
-// newGrpcConnection creates a gRPC connection to the Gateway server.
-func newGrpcConnection() (*grpc.ClientConn, error) {
-	settings := utility_sig_graph.GetGlobalSettings()
-	certificate := settings.TlsX509Certificate()
-	gatewayPeer := settings.GatewayPeer()
-	peerEndpoint := settings.PeerAddresses()[0]
-
+// connect to every peer of settings, each peer is verified against its own
+// server name
+func NewFabricPeerPoolFromSettings(settings utility_sig_graph.SettingsI) (*fabricPeerPool, error) {
 	certPool := x509.NewCertPool()
-	certPool.AddCert(certificate)
-	transportCredentials := credentials.NewClientTLSFromCert(certPool, gatewayPeer)
-
-	connection, err := grpc.Dial(peerEndpoint, grpc.WithTransportCredentials(transportCredentials))
-	if err != nil {
-		return nil, err
-	}
-
-	return connection, nil
-}
-
-func newSign() identity.Sign {
-	privateKey := utility_sig_graph.GetGlobalSettings().IdentityEDCSAKey()
+	certPool.AddCert(settings.TlsX509Certificate())
 
-	sign, err := identity.NewPrivateKeySign(privateKey)
-	if err != nil {
-		panic(err)
+	peerDialOptions := map[string][]grpc.DialOption{}
+	for _, address := range settings.PeerAddresses() {
+		transportCredentials := credentials.NewClientTLSFromCert(certPool, settings.PeerServerName(address))
+		peerDialOptions[address] = []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
 	}
 
-	return sign
-}
-
-func NewAssetSmartContractServiceHyperledger() (SmartContractServiceI, error) {
-	settings := utility_sig_graph.GetGlobalSettings()
-
-	certificate := settings.IdentityX509Certificate()
-
-	clientConnection, err := newGrpcConnection()
+	id, err := identity.NewX509Identity(settings.MspId(), settings.IdentityX509Certificate())
 	if err != nil {
 		return nil, err
 	}
 
-	mspId := settings.MspId()
-
-	id, err := identity.NewX509Identity(mspId, certificate)
+	sign, err := identity.NewPrivateKeySign(settings.IdentityEDCSAKey())
 	if err != nil {
-		clientConnection.Close()
 		return nil, err
 	}
 
-	gateway, err := client.Connect(
-		id,
-		client.WithSign(newSign()),
-		client.WithClientConnection(clientConnection),
-	)
-
-	network := gateway.GetNetwork(settings.ChannelName())
-	contract := network.GetContractWithName(settings.ContractName(), "assetView")
+	return NewFabricPeerPool(FabricPeerPoolOptions{
+		Addresses:           settings.PeerAddresses(),
+		PeerDialOptions:     peerDialOptions,
+		Identity:            id,
+		Sign:                sign,
+		Strategy:            EPeerSelectionStrategyRoundRobin,
+		HealthCheckInterval: 10 * time.Second,
+	})
+}
 
-	service := smartContractServiceHyperledger{
-		contract: contract,
+func NewSmartContractServiceHyperledger(
+	peerPool *fabricPeerPool,
+	channelName string,
+	chaincodeName string,
+	contractName string,
+) *smartContractServiceHyperledger {
+	return &smartContractServiceHyperledger{
+		peerPool:      peerPool,
+		channelName:   channelName,
+		chaincodeName: chaincodeName,
+		contractName:  contractName,
 	}
-	service.clientConnection = clientConnection
+}
+
+func NewAssetSmartContractServiceHyperledger(peerPool *fabricPeerPool, settings utility_sig_graph.SettingsI) SmartContractServiceI {
+	return NewSmartContractServiceHyperledger(peerPool, settings.ChannelName(), settings.ContractName(), "assetView")
+}
 
-	return &service, nil
+func NewNodeSmartContractServiceHyperledger(peerPool *fabricPeerPool, settings utility_sig_graph.SettingsI) SmartContractServiceI {
+	return NewSmartContractServiceHyperledger(peerPool, settings.ChannelName(), settings.ContractName(), "nodeView")
 }
 
-func NewNodeSmartContractServiceHyperledger() (SmartContractServiceI, error) {
-	settings := utility_sig_graph.GetGlobalSettings()
+func NewLedgerTransactionStatusServiceHyperledger(peerPool *fabricPeerPool, settings utility_sig_graph.SettingsI) LedgerTransactionStatusServiceI {
+	return NewSmartContractServiceHyperledger(peerPool, settings.ChannelName(), settings.ContractName(), "")
+}
 
-	certificate := settings.IdentityX509Certificate()
+func (s *smartContractServiceHyperledger) contract(gateway *client.Gateway) *client.Contract {
+	return gateway.GetNetwork(s.channelName).GetContractWithName(s.chaincodeName, s.contractName)
+}
 
-	clientConnection, err := newGrpcConnection()
-	if err != nil {
-		return nil, err
-	}
+type ledgerTransactionHyperledger struct {
+	commit       *client.Commit
+	functionName string
+}
 
-	mspId := settings.MspId()
+func (t *ledgerTransactionHyperledger) Id() string {
+	return t.commit.TransactionID()
+}
 
-	id, err := identity.NewX509Identity(mspId, certificate)
+func (t *ledgerTransactionHyperledger) WaitForCommit(ctx context.Context) error {
+	status, err := t.commit.StatusWithContext(ctx)
 	if err != nil {
-		clientConnection.Close()
-		return nil, err
+		return wrapError(t.functionName, err)
 	}
 
-	gateway, err := client.Connect(
-		id,
-		client.WithSign(newSign()),
-		client.WithClientConnection(clientConnection),
-	)
-
-	network := gateway.GetNetwork(settings.ChannelName())
-	contract := network.GetContractWithName(settings.ContractName(), "nodeView")
-
-	service := smartContractServiceHyperledger{
-		contract: contract,
+	if !status.Successful {
+		return wrapCommitStatus(t.functionName, status)
 	}
-	service.clientConnection = clientConnection
-
-	return &service, nil
-}
-
-func (s *smartContractServiceHyperledger) Close() {
-	s.clientConnection.Close()
-}
 
-func wrapError(functionName string, err error) error {
-	statusErr := status.Convert(err)
-	smartContractErr := fmt.Errorf("%w for function %s", utility.ErrSmartContractError, functionName)
-	for _, detail := range statusErr.Details() {
-		switch detail := detail.(type) {
-		case *gateway.ErrorDetail:
-			if strings.Contains(detail.Message, "not found") {
-				err = multierr.Append(err, utility.ErrNotFound)
-			} else {
-				err = fmt.Errorf("%s", detail.Message)
-			}
-
-			err = multierr.Append(smartContractErr, err)
-		}
-	}
-	return err
+	return nil
 }
 
-func (s *smartContractServiceHyperledger) CreateTransaction(
+func (s *smartContractServiceHyperledger) SubmitTransaction(
+	ctx context.Context,
 	functionName string,
 	args ...string,
-) (string, error) {
-	result, err := s.contract.SubmitTransaction(functionName, args...)
+) (string, LedgerTransactionI, error) {
+	var commit *client.Commit
+	result, err := s.peerPool.do(FabricIdentityFromContext(ctx), canSubmitOnNextPeer, func(gateway *client.Gateway) ([]byte, error) {
+		var result []byte
+		var err error
+		result, commit, err = s.contract(gateway).SubmitAsync(functionName, client.WithArguments(args...))
+		return result, err
+	})
 	if err != nil {
-		return "", wrapError(functionName, err)
+		return "", nil, wrapError(functionName, err)
 	}
 
-	return string(result), nil
+	return string(result), &ledgerTransactionHyperledger{
+		commit:       commit,
+		functionName: functionName,
+	}, nil
 }
 
 func (s *smartContractServiceHyperledger) Query(
+	ctx context.Context,
 	functionName string,
 	args ...string,
 ) (string, error) {
-	result, err := s.contract.EvaluateTransaction(functionName, args...)
+	result, err := s.peerPool.do(FabricIdentityFromContext(ctx), canEvaluateOnNextPeer, func(gateway *client.Gateway) ([]byte, error) {
+		return s.contract(gateway).EvaluateTransaction(functionName, args...)
+	})
 	if err != nil {
 		return "", wrapError(functionName, err)
 	}
@@ -167,3 +144,38 @@ func (s *smartContractServiceHyperledger) Query(
 	return string(result), nil
 
 }
+
+// look the transaction up with the query system chaincode of the peer
+func (s *smartContractServiceHyperledger) GetTransactionStatus(
+	ctx context.Context,
+	transactionId string,
+) error {
+	const functionName = "GetTransactionByID"
+	result, err := s.peerPool.do(FabricIdentityFromContext(ctx), canEvaluateOnNextPeer, func(gateway *client.Gateway) ([]byte, error) {
+		return gateway.GetNetwork(s.channelName).GetContract("qscc").EvaluateTransaction(functionName, s.channelName, transactionId)
+	})
+	if err != nil {
+		wrappedErr := wrapError(functionName, err)
+		// the ledger reports unknown ids as "no such transaction ID [...] in index"
+		if smartContractErr, ok := wrappedErr.(*SmartContractError); ok && smartContractErr.Code == "" &&
+			(strings.Contains(smartContractErr.Message, "no such transaction ID") || strings.Contains(err.Error(), "no such transaction ID")) {
+			smartContractErr.Code = ESmartContractErrorCodeNotFound
+		}
+		return wrappedErr
+	}
+
+	processedTransaction := peer.ProcessedTransaction{}
+	err = proto.Unmarshal(result, &processedTransaction)
+	if err != nil {
+		return fmt.Errorf("could not decode transaction %s: %w", transactionId, err)
+	}
+
+	code := peer.TxValidationCode(processedTransaction.ValidationCode)
+	if code != peer.TxValidationCode_VALID {
+		return wrapCommitStatus(functionName, &client.Status{
+			Code:          code,
+			TransactionID: transactionId,
+		})
+	}
+	return nil
+}
@@ -1,12 +1,18 @@
 package service_sig_graph
 
+import "context"
+
 type SmartContractServiceI interface {
-	CreateTransaction(
+	// return once the transaction is endorsed and sent for ordering,
+	// with the result of the endorsement
+	SubmitTransaction(
+		ctx context.Context,
 		iFunctionName string,
 		iArgs ...string,
-	) (string, error)
+	) (string, LedgerTransactionI, error)
 
 	Query(
+		ctx context.Context,
 		iFunctionName string,
 		iArgs ...string,
 	) (string, error)
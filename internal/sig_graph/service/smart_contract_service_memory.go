@@ -0,0 +1,530 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// in-process emulation of the SigGraph smart contract. It serves both
+// the asset and the node contract, so the same instance should be given
+// to the asset service and the node service. It is also the event source
+// of the emulated ledger, every transaction is committed in its own block.
+type smartContractServiceMemory struct {
+	mtx           sync.Mutex
+	nodes         map[string]model_sig_graph.Asset
+	hashGenerator utility.HashedIdGeneratorServiceI
+	events        *nodeEventBroadcaster
+	blockNumber   uint64
+}
+
+var _ NodeEventSourceI = (*smartContractServiceMemory)(nil)
+
+func NewSmartContractServiceMemory(
+	hashGenerator utility.HashedIdGeneratorServiceI,
+) *smartContractServiceMemory {
+	return &smartContractServiceMemory{
+		nodes:         map[string]model_sig_graph.Asset{},
+		hashGenerator: hashGenerator,
+		events:        newNodeEventBroadcaster(),
+	}
+}
+
+func (s *smartContractServiceMemory) Subscribe(ctx context.Context) (<-chan model_sig_graph.NodeEvent, error) {
+	return s.events.Subscribe(ctx)
+}
+
+func memoryTransactionId(blockNumber uint64) string {
+	return fmt.Sprintf("memory-%d", blockNumber)
+}
+
+// must be called with mtx locked, after the nodes are saved
+func (s *smartContractServiceMemory) commit(changes map[string]model.ENodeEventType) {
+	s.blockNumber++
+	transactionId := memoryTransactionId(s.blockNumber)
+
+	ids := make([]string, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	events := make([]model_sig_graph.NodeEvent, 0, len(ids))
+	for _, id := range ids {
+		events = append(events, model_sig_graph.NodeEvent{
+			EventType:     changes[id],
+			NodeId:        id,
+			TransactionId: transactionId,
+			BlockNumber:   s.blockNumber,
+		})
+	}
+	s.events.publish(events...)
+}
+
+// copy of a stored node whose edges can be changed without changing the
+// stored node, so that a failed transaction leaves no partial state
+func cloneMemoryAsset(asset model_sig_graph.Asset) model_sig_graph.Asset {
+	edges := []*map[string]bool{
+		&asset.PublicParentsIds,
+		&asset.PublicChildrenIds,
+		&asset.PrivateParentsHashedIds,
+		&asset.PrivateChildrenHashedIds,
+	}
+	for _, edge := range edges {
+		cloned := make(map[string]bool, len(*edge))
+		for id := range *edge {
+			cloned[id] = true
+		}
+		*edge = cloned
+	}
+	return asset
+}
+
+// the emulated ledger commits synchronously
+type ledgerTransactionMemory struct {
+	id string
+}
+
+func (t *ledgerTransactionMemory) Id() string {
+	return t.id
+}
+
+func (t *ledgerTransactionMemory) WaitForCommit(ctx context.Context) error {
+	return nil
+}
+
+// every transaction up to the current block is committed, failed
+// transactions are never on the emulated ledger
+func (s *smartContractServiceMemory) GetTransactionStatus(ctx context.Context, transactionId string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	blockNumber, err := strconv.ParseUint(strings.TrimPrefix(transactionId, "memory-"), 10, 64)
+	if err == nil && blockNumber > 0 && blockNumber <= s.blockNumber && memoryTransactionId(blockNumber) == transactionId {
+		return nil
+	}
+	return fmt.Errorf("%w: transaction %s", utility.ErrNotFound, transactionId)
+}
+
+func (s *smartContractServiceMemory) SubmitTransaction(
+	ctx context.Context,
+	functionName string,
+	args ...string,
+) (string, LedgerTransactionI, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(args) != 1 {
+		return "", nil, fmt.Errorf("%w: function %s expects 1 argument, got %d", utility.ErrInvalidArgument, functionName, len(args))
+	}
+
+	var result string
+	var err error
+	switch functionName {
+	case "CreateAsset":
+		result, err = s.createAsset(args[0])
+	case "TransferAsset":
+		result, err = s.transferAsset(args[0])
+	case "SplitAsset":
+		result, err = s.splitAsset(args[0])
+	default:
+		return "", nil, fmt.Errorf("%w: unknown function %s", utility.ErrInvalidArgument, functionName)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return result, &ledgerTransactionMemory{
+		id: memoryTransactionId(s.blockNumber),
+	}, nil
+}
+
+func (s *smartContractServiceMemory) Query(
+	ctx context.Context,
+	functionName string,
+	args ...string,
+) (string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(args) != 1 {
+		return "", fmt.Errorf("%w: function %s expects 1 argument, got %d", utility.ErrInvalidArgument, functionName, len(args))
+	}
+
+	switch functionName {
+	case "GetAsset":
+		return s.getAsset(args[0])
+	case "DoNodeIdsExist":
+		return s.doNodeIdsExist(args[0])
+	case "GetNodesById":
+		return s.getNodesById(args[0])
+	default:
+		return "", fmt.Errorf("%w: unknown function %s", utility.ErrInvalidArgument, functionName)
+	}
+}
+
+func (s *smartContractServiceMemory) createAsset(requestJson string) (string, error) {
+	ctx := context.Background()
+	request := createAssetRequest{}
+	err := json.Unmarshal([]byte(requestJson), &request)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	if _, ok := s.nodes[request.Id]; ok {
+		return "", fmt.Errorf("%w: node %s", utility.ErrAlreadyExists, request.Id)
+	}
+
+	quantity, err := decimal.NewFromString(request.Quantity)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid quantity %s", utility.ErrInvalidArgument, request.Quantity)
+	}
+
+	numberOfIngredients := len(request.IngredientIds)
+	if len(request.IngredientSecretIds) != numberOfIngredients ||
+		len(request.SecretIds) != numberOfIngredients ||
+		len(request.IngredientSignatures) != numberOfIngredients {
+		return "", fmt.Errorf("%w: mismatch ingredient length", utility.ErrInvalidArgument)
+	}
+
+	// validate all ingredients before modifying anything
+	ingredients := make([]model_sig_graph.Asset, 0, numberOfIngredients)
+	for _, ingredientId := range request.IngredientIds {
+		ingredient, ok := s.nodes[ingredientId]
+		if !ok {
+			return "", fmt.Errorf("%w: ingredient %s", utility.ErrNotFound, ingredientId)
+		}
+
+		if ingredient.IsFinalized {
+			return "", fmt.Errorf("%w: ingredient %s", utility.ErrAlreadyFinalized, ingredientId)
+		}
+
+		for i := range ingredients {
+			if ingredients[i].Id == ingredientId {
+				return "", fmt.Errorf("%w: duplicated ingredient %s", utility.ErrInvalidArgument, ingredientId)
+			}
+		}
+		ingredients = append(ingredients, cloneMemoryAsset(ingredient))
+	}
+
+	node := model_sig_graph.NewDefaultNode(
+		request.Id,
+		model.ENodeTypeAsset,
+		request.Time,
+		request.Time,
+		request.Signature,
+		request.OwnerPublicKey,
+	)
+	node.SignatureScheme = request.SignatureScheme
+	asset := model_sig_graph.NewAsset(
+		node,
+		model.ECreationProcessCreate,
+		request.Unit,
+		quantity,
+		request.MaterialName,
+	)
+
+	for i := range ingredients {
+		ingredient := &ingredients[i]
+
+		if request.SecretIds[i] != "" {
+			hash, err := s.hashGenerator.GenerateHashedId(ctx, asset.Id, request.SecretIds[i])
+			if err != nil {
+				return "", err
+			}
+			ingredient.PrivateChildrenHashedIds[hash] = true
+		} else {
+			ingredient.PublicChildrenIds[asset.Id] = true
+		}
+
+		if request.IngredientSecretIds[i] != "" {
+			hash, err := s.hashGenerator.GenerateHashedId(ctx, ingredient.Id, request.IngredientSecretIds[i])
+			if err != nil {
+				return "", err
+			}
+			asset.PrivateParentsHashedIds[hash] = true
+		} else {
+			asset.PublicParentsIds[ingredient.Id] = true
+		}
+
+		ingredient.IsFinalized = true
+		ingredient.UpdatedTime = request.Time
+		ingredient.Signature = request.IngredientSignatures[i]
+		ingredient.SignatureScheme = request.SignatureScheme
+	}
+
+	assetJson, err := json.Marshal(asset)
+	if err != nil {
+		return "", err
+	}
+
+	changes := map[string]model.ENodeEventType{
+		asset.Id: model.ENodeEventTypeCreated,
+	}
+	for i := range ingredients {
+		s.nodes[ingredients[i].Id] = ingredients[i]
+		changes[ingredients[i].Id] = model.ENodeEventTypeFinalized
+	}
+	s.nodes[asset.Id] = asset
+	s.commit(changes)
+
+	return string(assetJson), nil
+}
+
+func (s *smartContractServiceMemory) transferAsset(requestJson string) (string, error) {
+	ctx := context.Background()
+	request := transefrAssetRequest{}
+	err := json.Unmarshal([]byte(requestJson), &request)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	stored, ok := s.nodes[request.CurrentId]
+	if !ok {
+		return "", fmt.Errorf("%w: node %s", utility.ErrNotFound, request.CurrentId)
+	}
+	current := cloneMemoryAsset(stored)
+
+	if current.IsFinalized {
+		return "", fmt.Errorf("%w: node %s", utility.ErrAlreadyFinalized, request.CurrentId)
+	}
+
+	if _, ok := s.nodes[request.NewId]; ok {
+		return "", fmt.Errorf("%w: node %s", utility.ErrAlreadyExists, request.NewId)
+	}
+
+	newNode := model_sig_graph.NewDefaultNode(
+		request.NewId,
+		current.NodeType,
+		request.TimeMs,
+		request.TimeMs,
+		request.NewSignature,
+		request.NewOwnerPublicKey,
+	)
+	newNode.SignatureScheme = request.NewSignatureScheme
+	newAsset := model_sig_graph.NewAsset(
+		newNode,
+		model.ECreationProcessTransfer,
+		current.Unit,
+		current.Quantity,
+		current.MaterialName,
+	)
+
+	if request.NewSecret != "" {
+		hash, err := s.hashGenerator.GenerateHashedId(ctx, newAsset.Id, request.NewSecret)
+		if err != nil {
+			return "", err
+		}
+		current.PrivateChildrenHashedIds[hash] = true
+	} else {
+		current.PublicChildrenIds[newAsset.Id] = true
+	}
+
+	if request.CurrentSecret != "" {
+		hash, err := s.hashGenerator.GenerateHashedId(ctx, current.Id, request.CurrentSecret)
+		if err != nil {
+			return "", err
+		}
+		newAsset.PrivateParentsHashedIds[hash] = true
+	} else {
+		newAsset.PublicParentsIds[current.Id] = true
+	}
+
+	current.IsFinalized = true
+	current.UpdatedTime = request.TimeMs
+	current.Signature = request.CurrentSignature
+	current.SignatureScheme = request.CurrentSignatureScheme
+
+	newAssetJson, err := json.Marshal(newAsset)
+	if err != nil {
+		return "", err
+	}
+
+	s.nodes[current.Id] = current
+	s.nodes[newAsset.Id] = newAsset
+	s.commit(map[string]model.ENodeEventType{
+		current.Id:  model.ENodeEventTypeFinalized,
+		newAsset.Id: model.ENodeEventTypeTransferred,
+	})
+
+	return string(newAssetJson), nil
+}
+
+func (s *smartContractServiceMemory) splitAsset(requestJson string) (string, error) {
+	ctx := context.Background()
+	request := splitAssetRequest{}
+	err := json.Unmarshal([]byte(requestJson), &request)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	stored, ok := s.nodes[request.ParentId]
+	if !ok {
+		return "", fmt.Errorf("%w: node %s", utility.ErrNotFound, request.ParentId)
+	}
+	parent := cloneMemoryAsset(stored)
+
+	if parent.IsFinalized {
+		return "", fmt.Errorf("%w: node %s", utility.ErrAlreadyFinalized, request.ParentId)
+	}
+
+	if len(request.Children) < 2 {
+		return "", fmt.Errorf("%w: an asset is split into at least 2 children", utility.ErrInvalidArgument)
+	}
+
+	// validate all children before modifying anything
+	total := decimal.Zero
+	children := make([]model_sig_graph.Asset, 0, len(request.Children))
+	for _, childRequest := range request.Children {
+		if _, ok := s.nodes[childRequest.Id]; ok {
+			return "", fmt.Errorf("%w: node %s", utility.ErrAlreadyExists, childRequest.Id)
+		}
+
+		for i := range children {
+			if children[i].Id == childRequest.Id {
+				return "", fmt.Errorf("%w: duplicated child %s", utility.ErrInvalidArgument, childRequest.Id)
+			}
+		}
+
+		quantity, err := decimal.NewFromString(childRequest.Quantity)
+		if err != nil || !quantity.IsPositive() {
+			return "", fmt.Errorf("%w: invalid quantity %s", utility.ErrInvalidArgument, childRequest.Quantity)
+		}
+		total = total.Add(quantity)
+
+		node := model_sig_graph.NewDefaultNode(
+			childRequest.Id,
+			parent.NodeType,
+			request.TimeMs,
+			request.TimeMs,
+			childRequest.Signature,
+			parent.OwnerPublicKey,
+		)
+		node.SignatureScheme = childRequest.SignatureScheme
+		children = append(children, model_sig_graph.NewAsset(
+			node,
+			model.ECreationProcessSplit,
+			parent.Unit,
+			quantity,
+			parent.MaterialName,
+		))
+	}
+
+	if !total.Equal(parent.Quantity) {
+		return "", fmt.Errorf("%w: quantities add up to %s instead of %s", utility.ErrInvalidArgument, total, parent.Quantity)
+	}
+
+	for i := range children {
+		child := &children[i]
+		childRequest := &request.Children[i]
+
+		if childRequest.Secret != "" {
+			hash, err := s.hashGenerator.GenerateHashedId(ctx, child.Id, childRequest.Secret)
+			if err != nil {
+				return "", err
+			}
+			parent.PrivateChildrenHashedIds[hash] = true
+		} else {
+			parent.PublicChildrenIds[child.Id] = true
+		}
+
+		if childRequest.ParentSecret != "" {
+			hash, err := s.hashGenerator.GenerateHashedId(ctx, parent.Id, childRequest.ParentSecret)
+			if err != nil {
+				return "", err
+			}
+			child.PrivateParentsHashedIds[hash] = true
+		} else {
+			child.PublicParentsIds[parent.Id] = true
+		}
+	}
+
+	parent.IsFinalized = true
+	parent.UpdatedTime = request.TimeMs
+	parent.Signature = request.ParentSignature
+	parent.SignatureScheme = request.ParentSignatureScheme
+
+	childrenJson, err := json.Marshal(children)
+	if err != nil {
+		return "", err
+	}
+
+	changes := map[string]model.ENodeEventType{
+		parent.Id: model.ENodeEventTypeFinalized,
+	}
+	s.nodes[parent.Id] = parent
+	for i := range children {
+		s.nodes[children[i].Id] = children[i]
+		changes[children[i].Id] = model.ENodeEventTypeCreated
+	}
+	s.commit(changes)
+
+	return string(childrenJson), nil
+}
+
+func (s *smartContractServiceMemory) getAsset(id string) (string, error) {
+	asset, ok := s.nodes[id]
+	if !ok {
+		return "", fmt.Errorf("%w: node %s", utility.ErrNotFound, id)
+	}
+
+	assetJson, err := json.Marshal(asset)
+	if err != nil {
+		return "", err
+	}
+
+	return string(assetJson), nil
+}
+
+func (s *smartContractServiceMemory) doNodeIdsExist(idsJson string) (string, error) {
+	ids := map[string]bool{}
+	err := json.Unmarshal([]byte(idsJson), &ids)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	exist := map[string]bool{}
+	for id := range ids {
+		_, exist[id] = s.nodes[id]
+	}
+
+	existJson, err := json.Marshal(exist)
+	if err != nil {
+		return "", err
+	}
+
+	return string(existJson), nil
+}
+
+func (s *smartContractServiceMemory) getNodesById(requestJson string) (string, error) {
+	request := getNodesByIdRequest{}
+	err := json.Unmarshal([]byte(requestJson), &request)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	nodes := map[string]model_sig_graph.Asset{}
+	for id := range request.Ids {
+		node, ok := s.nodes[id]
+		if !ok {
+			return "", fmt.Errorf("%w: node %s", utility.ErrNotFound, id)
+		}
+		nodes[id] = node
+	}
+
+	nodesJson, err := json.Marshal(nodes)
+	if err != nil {
+		return "", err
+	}
+
+	return string(nodesJson), nil
+}
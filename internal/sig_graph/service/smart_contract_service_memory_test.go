@@ -0,0 +1,231 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+)
+
+const (
+	memoryTestFlourId = "sgp://memory:[]:public:flour"
+	memoryTestWaterId = "sgp://memory:[]:public:water"
+	memoryTestDoughId = "sgp://memory:[]:public:dough"
+)
+
+var errMemoryTestHash = errors.New("hash failure")
+
+// fails from the failAt-th hash on, counting from 1, and never if failAt is 0
+type memoryTestHashGenerator struct {
+	utility.HashedIdGeneratorServiceI
+	failAt int
+	calls  int
+}
+
+func (g *memoryTestHashGenerator) GenerateHashedId(ctx context.Context, id string, secret string) (string, error) {
+	g.calls++
+	if g.failAt != 0 && g.calls >= g.failAt {
+		return "", errMemoryTestHash
+	}
+	return g.HashedIdGeneratorServiceI.GenerateHashedId(ctx, id, secret)
+}
+
+// ledger holding flour and water, hashes are counted from the next request
+func newMemoryTest(t *testing.T) (*smartContractServiceMemory, *memoryTestHashGenerator) {
+	t.Helper()
+
+	hashGenerator := &memoryTestHashGenerator{HashedIdGeneratorServiceI: utility.NewHashedIdGeneratorService()}
+	ledger := NewSmartContractServiceMemory(hashGenerator)
+	for _, id := range []string{memoryTestFlourId, memoryTestWaterId} {
+		submitMemoryTest(t, ledger, "CreateAsset", createAssetRequest{
+			Time:                 1,
+			Id:                   id,
+			MaterialName:         "ingredient",
+			Quantity:             "10",
+			Unit:                 "kg",
+			Signature:            "signature",
+			OwnerPublicKey:       "owner",
+			IngredientIds:        []string{},
+			IngredientSecretIds:  []string{},
+			SecretIds:            []string{},
+			IngredientSignatures: []string{},
+		})
+	}
+	hashGenerator.calls = 0
+	return ledger, hashGenerator
+}
+
+func submitMemoryTest(t *testing.T, ledger *smartContractServiceMemory, functionName string, request any) error {
+	t.Helper()
+
+	requestJson, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = ledger.SubmitTransaction(context.Background(), functionName, string(requestJson))
+	return err
+}
+
+// stored nodes and block number, to check that a failed transaction left
+// the ledger as it was
+func snapshotMemoryTest(t *testing.T, ledger *smartContractServiceMemory) string {
+	t.Helper()
+
+	ledger.mtx.Lock()
+	defer ledger.mtx.Unlock()
+	snapshot, err := json.Marshal(struct {
+		Nodes       any
+		BlockNumber uint64
+	}{ledger.nodes, ledger.blockNumber})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(snapshot)
+}
+
+func newMemoryTestDough(ingredientIds []string, secret string) createAssetRequest {
+	request := createAssetRequest{
+		Time:           2,
+		Id:             memoryTestDoughId,
+		MaterialName:   "dough",
+		Quantity:       "20",
+		Unit:           "kg",
+		Signature:      "signature",
+		OwnerPublicKey: "owner",
+		IngredientIds:  ingredientIds,
+	}
+	for range ingredientIds {
+		request.IngredientSecretIds = append(request.IngredientSecretIds, secret)
+		request.SecretIds = append(request.SecretIds, secret)
+		request.IngredientSignatures = append(request.IngredientSignatures, "ingredient signature")
+	}
+	return request
+}
+
+func TestSmartContractServiceMemoryApplies(t *testing.T) {
+	ledger, _ := newMemoryTest(t)
+
+	err := submitMemoryTest(t, ledger, "CreateAsset", newMemoryTestDough([]string{memoryTestFlourId, memoryTestWaterId}, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{memoryTestFlourId, memoryTestWaterId} {
+		ingredient := ledger.nodes[id]
+		if !ingredient.IsFinalized || !ingredient.PublicChildrenIds[memoryTestDoughId] {
+			t.Fatalf("expected %s finalized with the dough as child, got %+v", id, ingredient)
+		}
+	}
+	dough := ledger.nodes[memoryTestDoughId]
+	if len(dough.PublicParentsIds) != 2 {
+		t.Fatalf("expected 2 parents, got %v", dough.PublicParentsIds)
+	}
+
+	err = submitMemoryTest(t, ledger, "SplitAsset", splitAssetRequest{
+		TimeMs:   3,
+		ParentId: memoryTestDoughId,
+		Children: []splitAssetChildRequest{
+			{Id: "sgp://memory:[]:public:half1", Quantity: "10", Secret: "secret", ParentSecret: "secret"},
+			{Id: "sgp://memory:[]:public:half2", Quantity: "10"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dough = ledger.nodes[memoryTestDoughId]
+	if !dough.IsFinalized || len(dough.PrivateChildrenHashedIds) != 1 || len(dough.PublicChildrenIds) != 1 {
+		t.Fatalf("expected the dough finalized with a private and a public child, got %+v", dough)
+	}
+
+	err = submitMemoryTest(t, ledger, "TransferAsset", transefrAssetRequest{
+		TimeMs:            4,
+		CurrentId:         "sgp://memory:[]:public:half2",
+		NewId:             "sgp://memory:[]:public:sold",
+		NewOwnerPublicKey: "buyer",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sold := ledger.nodes["sgp://memory:[]:public:sold"]
+	if sold.OwnerPublicKey != "buyer" || !sold.PublicParentsIds["sgp://memory:[]:public:half2"] {
+		t.Fatalf("expected the half transferred to the buyer, got %+v", sold)
+	}
+	if ledger.blockNumber != 5 {
+		t.Fatalf("expected 5 blocks, got %d", ledger.blockNumber)
+	}
+}
+
+func TestSmartContractServiceMemoryLeavesNoPartialState(t *testing.T) {
+	cases := []struct {
+		name         string
+		functionName string
+		request      any
+		// hash failing, 0 if the request is invalid
+		failAt int
+		err    error
+	}{
+		{"create with a hash failing on the last ingredient", "CreateAsset",
+			newMemoryTestDough([]string{memoryTestFlourId, memoryTestWaterId}, "secret"), 3, errMemoryTestHash},
+		{"create with a missing ingredient", "CreateAsset",
+			newMemoryTestDough([]string{memoryTestFlourId, "sgp://memory:[]:public:missing"}, ""), 0, utility.ErrNotFound},
+		{"create with a duplicated ingredient", "CreateAsset",
+			newMemoryTestDough([]string{memoryTestFlourId, memoryTestFlourId}, ""), 0, utility.ErrInvalidArgument},
+		{"transfer with a hash failing on the parent secret", "TransferAsset", transefrAssetRequest{
+			TimeMs:        2,
+			CurrentId:     memoryTestFlourId,
+			CurrentSecret: "secret",
+			NewId:         memoryTestDoughId,
+			NewSecret:     "secret",
+		}, 2, errMemoryTestHash},
+		{"transfer to an existing node", "TransferAsset", transefrAssetRequest{
+			TimeMs:    2,
+			CurrentId: memoryTestFlourId,
+			NewId:     memoryTestWaterId,
+		}, 0, utility.ErrAlreadyExists},
+		{"split with a hash failing on the last child", "SplitAsset", splitAssetRequest{
+			TimeMs:   2,
+			ParentId: memoryTestFlourId,
+			Children: []splitAssetChildRequest{
+				{Id: "sgp://memory:[]:public:half1", Quantity: "5", Secret: "secret", ParentSecret: "secret"},
+				{Id: "sgp://memory:[]:public:half2", Quantity: "5", Secret: "secret"},
+			},
+		}, 3, errMemoryTestHash},
+		{"split with quantities not adding up", "SplitAsset", splitAssetRequest{
+			TimeMs:   2,
+			ParentId: memoryTestFlourId,
+			Children: []splitAssetChildRequest{
+				{Id: "sgp://memory:[]:public:half1", Quantity: "5"},
+				{Id: "sgp://memory:[]:public:half2", Quantity: "4"},
+			},
+		}, 0, utility.ErrInvalidArgument},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ledger, hashGenerator := newMemoryTest(t)
+			hashGenerator.failAt = c.failAt
+			before := snapshotMemoryTest(t, ledger)
+
+			err := submitMemoryTest(t, ledger, c.functionName, c.request)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+			if c.failAt != 0 && hashGenerator.calls != c.failAt {
+				t.Fatalf("expected the hash %d to fail, got %d hashes", c.failAt, hashGenerator.calls)
+			}
+			after := snapshotMemoryTest(t, ledger)
+			if after != before {
+				t.Fatalf("expected the ledger unchanged\nbefore %s\nafter  %s", before, after)
+			}
+
+			// the same request goes through once the hashes work
+			hashGenerator.failAt = 0
+			if c.failAt != 0 {
+				err = submitMemoryTest(t, ledger, c.functionName, c.request)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package service_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sync"
+	"time"
+)
+
+type RetryPolicy struct {
+	// attempts including the first one
+	MaxAttempts int
+	// backoff before the second attempt, doubled after every attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// backoff after the given attempt, with jitter over its upper half
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type submittedNode struct {
+	id        string
+	signature string
+}
+
+// transaction creating nodes signed by the client. If the nodes exist with
+// our signatures the transaction was committed, so it is safe to resubmit it
+// when they do not
+type idempotentFunction struct {
+	createdNodes func(requestJson string) ([]submittedNode, error)
+	// the result is the json array of the created nodes instead of the node
+	returnsArray bool
+}
+
+var idempotentFunctions = map[string]idempotentFunction{
+	"CreateAsset": {
+		createdNodes: func(requestJson string) ([]submittedNode, error) {
+			request := createAssetRequest{}
+			err := json.Unmarshal([]byte(requestJson), &request)
+			if err != nil {
+				return nil, err
+			}
+			return []submittedNode{{id: request.Id, signature: request.Signature}}, nil
+		},
+	},
+	"TransferAsset": {
+		createdNodes: func(requestJson string) ([]submittedNode, error) {
+			request := transefrAssetRequest{}
+			err := json.Unmarshal([]byte(requestJson), &request)
+			if err != nil {
+				return nil, err
+			}
+			return []submittedNode{{id: request.NewId, signature: request.NewSignature}}, nil
+		},
+	},
+	"SplitAsset": {
+		createdNodes: func(requestJson string) ([]submittedNode, error) {
+			request := splitAssetRequest{}
+			err := json.Unmarshal([]byte(requestJson), &request)
+			if err != nil {
+				return nil, err
+			}
+			nodes := make([]submittedNode, 0, len(request.Children))
+			for _, child := range request.Children {
+				nodes = append(nodes, submittedNode{id: child.Id, signature: child.Signature})
+			}
+			return nodes, nil
+		},
+		returnsArray: true,
+	},
+}
+
+// retry calls failing with ErrTransient. Only queries and the transactions of
+// idempotentFunctions are retried, the others could be applied twice
+type smartContractServiceRetry struct {
+	smartContractService SmartContractServiceI
+	policy               RetryPolicy
+}
+
+func NewSmartContractServiceRetry(
+	smartContractService SmartContractServiceI,
+	policy RetryPolicy,
+) *smartContractServiceRetry {
+	return &smartContractServiceRetry{
+		smartContractService: smartContractService,
+		policy:               policy,
+	}
+}
+
+func (s *smartContractServiceRetry) Query(
+	ctx context.Context,
+	functionName string,
+	args ...string,
+) (string, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := s.smartContractService.Query(ctx, functionName, args...)
+		if err == nil || !errors.Is(err, utility.ErrTransient) || attempt >= s.policy.MaxAttempts {
+			return result, err
+		}
+		if sleepErr := sleepContext(ctx, s.policy.backoff(attempt)); sleepErr != nil {
+			return result, err
+		}
+	}
+}
+
+func (s *smartContractServiceRetry) SubmitTransaction(
+	ctx context.Context,
+	functionName string,
+	args ...string,
+) (string, LedgerTransactionI, error) {
+	function, isIdempotent := idempotentFunctions[functionName]
+	if !isIdempotent || len(args) != 1 {
+		return s.smartContractService.SubmitTransaction(ctx, functionName, args...)
+	}
+
+	nodes, err := function.createdNodes(args[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	result, transaction, err := s.submit(ctx, functionName, function, nodes, args[0], false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return result, &ledgerTransactionRetry{
+		service:      s,
+		functionName: functionName,
+		function:     function,
+		nodes:        nodes,
+		requestJson:  args[0],
+		identity:     FabricIdentityFromContext(ctx),
+		transaction:  transaction,
+	}, nil
+}
+
+// submit until the transaction is endorsed, unless the nodes are already on
+// the ledger. mayBeCommitted checks the ledger before the first attempt
+func (s *smartContractServiceRetry) submit(
+	ctx context.Context,
+	functionName string,
+	function idempotentFunction,
+	nodes []submittedNode,
+	requestJson string,
+	mayBeCommitted bool,
+) (string, LedgerTransactionI, error) {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 || mayBeCommitted {
+			result, isCommitted, err := s.committedResult(ctx, function, nodes)
+			if err != nil {
+				return "", nil, err
+			}
+			if isCommitted {
+				return result, &ledgerTransactionCommitted{}, nil
+			}
+		}
+
+		result, transaction, err := s.smartContractService.SubmitTransaction(ctx, functionName, requestJson)
+		if err == nil {
+			return result, transaction, nil
+		}
+
+		// an earlier attempt may have been committed in the meantime
+		if (attempt > 1 || mayBeCommitted) &&
+			(errors.Is(err, utility.ErrAlreadyExists) || errors.Is(err, utility.ErrAlreadyFinalized)) {
+			committedResult, isCommitted, committedErr := s.committedResult(ctx, function, nodes)
+			if committedErr == nil && isCommitted {
+				return committedResult, &ledgerTransactionCommitted{}, nil
+			}
+			return "", nil, err
+		}
+
+		if !errors.Is(err, utility.ErrTransient) || attempt >= s.policy.MaxAttempts {
+			return "", nil, err
+		}
+		if sleepErr := sleepContext(ctx, s.policy.backoff(attempt)); sleepErr != nil {
+			return "", nil, err
+		}
+	}
+}
+
+// the created nodes if all of them are on the ledger with our signatures
+func (s *smartContractServiceRetry) committedResult(
+	ctx context.Context,
+	function idempotentFunction,
+	nodes []submittedNode,
+) (string, bool, error) {
+	request := getNodesByIdRequest{Ids: map[string]bool{}}
+	for _, node := range nodes {
+		request.Ids[node.id] = true
+	}
+	requestJson, err := json.Marshal(request)
+	if err != nil {
+		return "", false, err
+	}
+
+	responseJson, err := s.Query(ctx, "GetNodesById", string(requestJson))
+	if errors.Is(err, utility.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	response := map[string]json.RawMessage{}
+	err = json.Unmarshal([]byte(responseJson), &response)
+	if err != nil {
+		return "", false, err
+	}
+
+	committedNodes := make([]json.RawMessage, 0, len(nodes))
+	for _, node := range nodes {
+		nodeJson, ok := response[node.id]
+		if !ok {
+			return "", false, nil
+		}
+
+		committed := struct {
+			Signature string `json:"signature"`
+		}{}
+		err = json.Unmarshal(nodeJson, &committed)
+		if err != nil {
+			return "", false, err
+		}
+		// the id was taken by someone else, submitting fails with ErrAlreadyExists
+		if committed.Signature != node.signature {
+			return "", false, nil
+		}
+		committedNodes = append(committedNodes, nodeJson)
+	}
+
+	if !function.returnsArray {
+		return string(committedNodes[0]), true, nil
+	}
+	result, err := json.Marshal(committedNodes)
+	if err != nil {
+		return "", false, err
+	}
+	return string(result), true, nil
+}
+
+// transaction found on the ledger after its submission failed, its id is unknown
+type ledgerTransactionCommitted struct {
+}
+
+func (t *ledgerTransactionCommitted) Id() string {
+	return ""
+}
+
+func (t *ledgerTransactionCommitted) WaitForCommit(ctx context.Context) error {
+	return nil
+}
+
+// submit the transaction again if its commit fails with ErrTransient,
+// e.g. on MVCC read conflicts
+type ledgerTransactionRetry struct {
+	mtx          sync.Mutex
+	service      *smartContractServiceRetry
+	functionName string
+	function     idempotentFunction
+	nodes        []submittedNode
+	requestJson  string
+	// identity of the submission, ctx of WaitForCommit may not carry it
+	identity    *model_sig_graph.FabricIdentity
+	transaction LedgerTransactionI
+}
+
+func (t *ledgerTransactionRetry) Id() string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.transaction.Id()
+}
+
+func (t *ledgerTransactionRetry) WaitForCommit(ctx context.Context) error {
+	for attempt := 1; ; attempt++ {
+		t.mtx.Lock()
+		transaction := t.transaction
+		t.mtx.Unlock()
+
+		err := transaction.WaitForCommit(ctx)
+		if err == nil || !errors.Is(err, utility.ErrTransient) || attempt >= t.service.policy.MaxAttempts {
+			return err
+		}
+		if sleepErr := sleepContext(ctx, t.service.policy.backoff(attempt)); sleepErr != nil {
+			return err
+		}
+
+		submitCtx := ctx
+		if t.identity != nil {
+			submitCtx = WithFabricIdentity(ctx, t.identity)
+		}
+		// the commit status may have been lost while the transaction was committed
+		_, transaction, err = t.service.submit(submitCtx, t.functionName, t.function, t.nodes, t.requestJson, true)
+		if err != nil {
+			return err
+		}
+		if _, isCommitted := transaction.(*ledgerTransactionCommitted); isCommitted {
+			return nil
+		}
+
+		t.mtx.Lock()
+		t.transaction = transaction
+		t.mtx.Unlock()
+	}
+}
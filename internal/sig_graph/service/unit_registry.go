@@ -0,0 +1,152 @@
+package service_sig_graph
+
+import (
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	"sig_graph_scp/pkg/utility"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+type unitDefinition struct {
+	symbol    string
+	dimension model.EUnitDimension
+	factor    decimal.Decimal
+}
+
+type unitRegistry struct {
+	mtx sync.RWMutex
+	// by lower case symbol and aliases
+	units map[string]*unitDefinition
+}
+
+var _ UnitRegistryI = (*unitRegistry)(nil)
+
+// registry of the common units, the base units are kg, l and pcs
+func NewUnitRegistry() *unitRegistry {
+	r := &unitRegistry{
+		units: map[string]*unitDefinition{},
+	}
+
+	defaults := []struct {
+		symbol    string
+		dimension model.EUnitDimension
+		factor    string
+		aliases   []string
+	}{
+		{"mg", model.EUnitDimensionMass, "0.000001", []string{"milligram", "milligrams"}},
+		{"g", model.EUnitDimensionMass, "0.001", []string{"gram", "grams"}},
+		{"kg", model.EUnitDimensionMass, "1", []string{"kilogram", "kilograms", "kgs", "kilo", "kilos"}},
+		{"t", model.EUnitDimensionMass, "1000", []string{"tonne", "tonnes", "metric ton"}},
+		{"oz", model.EUnitDimensionMass, "0.028349523125", []string{"ounce", "ounces"}},
+		{"lb", model.EUnitDimensionMass, "0.45359237", []string{"lbs", "pound", "pounds"}},
+
+		{"ml", model.EUnitDimensionVolume, "0.001", []string{"milliliter", "milliliters", "millilitre", "millilitres"}},
+		{"cl", model.EUnitDimensionVolume, "0.01", []string{"centiliter", "centiliters", "centilitre", "centilitres"}},
+		{"l", model.EUnitDimensionVolume, "1", []string{"liter", "liters", "litre", "litres"}},
+		{"m3", model.EUnitDimensionVolume, "1000", []string{"m³", "cubic meter", "cubic meters", "cubic metre", "cubic metres"}},
+		{"gal", model.EUnitDimensionVolume, "3.785411784", []string{"gallon", "gallons"}},
+
+		{"pcs", model.EUnitDimensionCount, "1", []string{"pc", "piece", "pieces", "unit", "units", "ea", "each"}},
+		{"dozen", model.EUnitDimensionCount, "12", []string{"dz", "dozens"}},
+	}
+	for _, unit := range defaults {
+		// the default units do not collide
+		_ = r.Register(unit.symbol, unit.dimension, decimal.RequireFromString(unit.factor), unit.aliases...)
+	}
+
+	return r
+}
+
+func unitKey(unit string) string {
+	return strings.ToLower(strings.TrimSpace(unit))
+}
+
+func (r *unitRegistry) find(unit string) (*unitDefinition, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	definition, ok := r.units[unitKey(unit)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown unit %q", utility.ErrInvalidArgument, unit)
+	}
+	return definition, nil
+}
+
+func (r *unitRegistry) Normalize(unit string) (string, error) {
+	definition, err := r.find(unit)
+	if err != nil {
+		return "", err
+	}
+	return definition.symbol, nil
+}
+
+func (r *unitRegistry) Dimension(unit string) (model.EUnitDimension, error) {
+	definition, err := r.find(unit)
+	if err != nil {
+		return "", err
+	}
+	return definition.dimension, nil
+}
+
+func (r *unitRegistry) Convert(quantity decimal.Decimal, from string, to string) (decimal.Decimal, error) {
+	fromDefinition, err := r.find(from)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	toDefinition, err := r.find(to)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	if fromDefinition.dimension != toDefinition.dimension {
+		return decimal.Zero, fmt.Errorf(
+			"%w: cannot convert %s (%s) to %s (%s)",
+			utility.ErrInvalidArgument,
+			fromDefinition.symbol,
+			fromDefinition.dimension,
+			toDefinition.symbol,
+			toDefinition.dimension,
+		)
+	}
+
+	if fromDefinition == toDefinition {
+		return quantity, nil
+	}
+	return quantity.Mul(fromDefinition.factor).Div(toDefinition.factor), nil
+}
+
+func (r *unitRegistry) Register(symbol string, dimension model.EUnitDimension, factor decimal.Decimal, aliases ...string) error {
+	if unitKey(symbol) == "" {
+		return fmt.Errorf("%w: empty unit symbol", utility.ErrInvalidArgument)
+	}
+	if !factor.IsPositive() {
+		return fmt.Errorf("%w: factor of unit %s must be positive", utility.ErrInvalidArgument, symbol)
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	keys := []string{unitKey(symbol)}
+	for _, alias := range aliases {
+		keys = append(keys, unitKey(alias))
+	}
+	for _, key := range keys {
+		if _, ok := r.units[key]; ok {
+			return fmt.Errorf("%w: unit %s", utility.ErrAlreadyExists, key)
+		}
+	}
+
+	definition := &unitDefinition{
+		symbol:    strings.TrimSpace(symbol),
+		dimension: dimension,
+		factor:    factor,
+	}
+	for _, key := range keys {
+		r.units[key] = definition
+	}
+	return nil
+}
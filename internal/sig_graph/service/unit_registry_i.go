@@ -0,0 +1,20 @@
+package service_sig_graph
+
+import (
+	"sig_graph_scp/pkg/model"
+
+	"github.com/shopspring/decimal"
+)
+
+// units of measure of asset quantities. Units are looked up case
+// insensitively by symbol or alias, unknown units return ErrInvalidArgument
+type UnitRegistryI interface {
+	// symbol that assets are stored with
+	Normalize(unit string) (string, error)
+	Dimension(unit string) (model.EUnitDimension, error)
+	// ErrInvalidArgument if the units are not of the same dimension
+	Convert(quantity decimal.Decimal, from string, to string) (decimal.Decimal, error)
+	// factor is the quantity of the base unit of dimension in one unit.
+	// Return ErrAlreadyExists if the symbol or an alias is taken
+	Register(symbol string, dimension model.EUnitDimension, factor decimal.Decimal, aliases ...string) error
+}
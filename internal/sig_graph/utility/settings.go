@@ -19,8 +19,9 @@ type settings struct {
 	identityX509Certificate *x509.Certificate
 	identityPeerPrivateKey  *ecdsa.PrivateKey
 
-	TlsCetificate *x509.Certificate
-	gatewayPeer   string
+	TlsCetificate   *x509.Certificate
+	gatewayPeer     string
+	peerServerNames map[string]string
 }
 
 func NewSettingsFromEnv() (SettingsI, error) {
@@ -58,27 +59,23 @@ func NewSettingsFromEnv() (SettingsI, error) {
 	if value, ok := os.LookupEnv("GATEWAY_PEER"); !ok {
 		return nil, fmt.Errorf("%w: missing env var GATEWAY_PEER", utility.ErrNotFound)
 	} else {
-		ret.gatewayPeer = value
+		// one name per peer address, or the name of the first peer only
+		serverNames := strings.Split(value, ",")
+		if len(serverNames) != 1 && len(serverNames) != len(ret.peerAddresses) {
+			return nil, fmt.Errorf("%w: GATEWAY_PEER must have one name or one per address of PEER_ADDRESSES", utility.ErrInvalidArgument)
+		}
+
+		ret.gatewayPeer = serverNames[0]
+		ret.peerServerNames = map[string]string{}
+		for i, serverName := range serverNames {
+			ret.peerServerNames[ret.peerAddresses[i]] = serverName
+		}
 	}
 
 	if value, ok := os.LookupEnv("TLS_PEM_CERTIFICATE_PATH"); !ok {
 		return nil, fmt.Errorf("%w: missing env var TLS_PEM_CERTIFICATE_PATH", utility.ErrNotFound)
 	} else {
-		if _, err := os.Stat(value); errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("%w: file not found %s", utility.ErrNotFound, value)
-		}
-
-		fileData, err := os.ReadFile(value)
-		if err != nil {
-			return nil, err
-		}
-
-		pemDecoded, _ := pem.Decode(fileData)
-		if pemDecoded == nil {
-			return nil, fmt.Errorf("could not decode pem file")
-		}
-
-		certificate, err := x509.ParseCertificate(pemDecoded.Bytes)
+		certificate, err := readPemCertificate(value)
 		if err != nil {
 			return nil, err
 		}
@@ -88,61 +85,86 @@ func NewSettingsFromEnv() (SettingsI, error) {
 	if value, ok := os.LookupEnv("PEM_CERTIFICATE_PATH"); !ok {
 		return nil, fmt.Errorf("%w: missing env var PEM_CERTIFICATE_PATH", utility.ErrNotFound)
 	} else {
-		if _, err := os.Stat(value); errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("%w: file not found %s", utility.ErrNotFound, value)
-		}
-
-		fileData, err := os.ReadFile(value)
-		if err != nil {
-			return nil, err
-		}
-
-		pemDecoded, _ := pem.Decode(fileData)
-		if pemDecoded == nil {
-			return nil, fmt.Errorf("could not decode pem file")
-		}
-
-		ret.identityX509Certificate, err = x509.ParseCertificate(pemDecoded.Bytes)
+		certificate, err := readPemCertificate(value)
 		if err != nil {
 			return nil, err
 		}
+		ret.identityX509Certificate = certificate
 	}
 
 	if value, ok := os.LookupEnv("PEM_SECRET_KEY_PATH"); !ok {
 		return nil, fmt.Errorf("%w: missing env var PEM_SECRET_KEY_PATH", utility.ErrNotFound)
 	} else {
-		if _, err := os.Stat(value); errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("%w: file not found %s", utility.ErrNotFound, value)
-		}
-
-		fileData, err := os.ReadFile(value)
+		privateKey, err := readPemEcdsaKey(value)
 		if err != nil {
 			return nil, err
 		}
+		ret.identityPeerPrivateKey = privateKey
+	}
 
-		pemDecoded, _ := pem.Decode(fileData)
-		if pemDecoded == nil {
-			return nil, fmt.Errorf("could not decode pem file")
-		}
+	return ret, nil
+}
 
-		privateKey, err := x509.ParsePKCS8PrivateKey(pemDecoded.Bytes)
-		if err != nil {
-			return nil, err
-		}
-		if privateKey, ok := privateKey.(*ecdsa.PrivateKey); !ok {
-			return nil, fmt.Errorf("only ecdsa key supported")
-		} else {
-			ret.identityPeerPrivateKey = privateKey
-		}
+func readPemCertificate(path string) (*x509.Certificate, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: file not found %s", utility.ErrNotFound, path)
 	}
 
-	return ret, nil
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePemCertificate(fileData)
+}
+
+func parsePemCertificate(data []byte) (*x509.Certificate, error) {
+	pemDecoded, _ := pem.Decode(data)
+	if pemDecoded == nil {
+		return nil, fmt.Errorf("could not decode pem file")
+	}
+
+	return x509.ParseCertificate(pemDecoded.Bytes)
+}
+
+func readPemEcdsaKey(path string) (*ecdsa.PrivateKey, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: file not found %s", utility.ErrNotFound, path)
+	}
+
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePemEcdsaKey(fileData)
+}
+
+func parsePemEcdsaKey(data []byte) (*ecdsa.PrivateKey, error) {
+	pemDecoded, _ := pem.Decode(data)
+	if pemDecoded == nil {
+		return nil, fmt.Errorf("could not decode pem file")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(pemDecoded.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey, ok := privateKey.(*ecdsa.PrivateKey); !ok {
+		return nil, fmt.Errorf("only ecdsa key supported")
+	} else {
+		return privateKey, nil
+	}
 }
 
 func (s *settings) GatewayPeer() string {
 	return s.gatewayPeer
 }
 
+func (s *settings) PeerServerName(address string) string {
+	return s.peerServerNames[address]
+}
+
 func (s *settings) TlsX509Certificate() *x509.Certificate {
 	return s.TlsCetificate
 }
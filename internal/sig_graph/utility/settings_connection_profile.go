@@ -0,0 +1,186 @@
+package utility_sig_graph
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sig_graph_scp/pkg/utility"
+	"sort"
+	"strings"
+)
+
+// values that are not part of a connection profile or override it
+type ConnectionProfileOptions struct {
+	// required
+	ContractName string
+	// defaults to client.organization of the profile
+	Organization string
+	// defaults to the only channel of the profile
+	ChannelName string
+	// default to organizations.<org>.signedCert and adminPrivateKey of the profile
+	IdentityCertificatePath string
+	IdentityPrivateKeyPath  string
+}
+
+type connectionProfilePem struct {
+	Path string `json:"path" yaml:"path"`
+	Pem  string `json:"pem" yaml:"pem"`
+}
+
+type connectionProfile struct {
+	Client struct {
+		Organization string `json:"organization" yaml:"organization"`
+	} `json:"client" yaml:"client"`
+	Organizations map[string]struct {
+		MspId           string               `json:"mspid" yaml:"mspid"`
+		Peers           []string             `json:"peers" yaml:"peers"`
+		SignedCert      connectionProfilePem `json:"signedCert" yaml:"signedCert"`
+		AdminPrivateKey connectionProfilePem `json:"adminPrivateKey" yaml:"adminPrivateKey"`
+	} `json:"organizations" yaml:"organizations"`
+	Peers map[string]struct {
+		Url         string               `json:"url" yaml:"url"`
+		TlsCaCerts  connectionProfilePem `json:"tlsCACerts" yaml:"tlsCACerts"`
+		GrpcOptions map[string]any       `json:"grpcOptions" yaml:"grpcOptions"`
+	} `json:"peers" yaml:"peers"`
+	Channels map[string]any `json:"channels" yaml:"channels"`
+}
+
+// read settings from a Hyperledger Fabric common connection profile
+// (.yaml, .yml or .json). The gateway connects to the peers of the
+// organization, which must share the same TLS CA.
+func NewSettingsFromConnectionProfile(path string, options ConnectionProfileOptions) (SettingsI, error) {
+	profile := connectionProfile{}
+	err := unmarshalConfigFile(path, &profile)
+	if err != nil {
+		return nil, err
+	}
+	directory := filepath.Dir(path)
+
+	if options.ContractName == "" {
+		return nil, fmt.Errorf("%w: missing contract name", utility.ErrInvalidArgument)
+	}
+
+	organizationName := options.Organization
+	if organizationName == "" {
+		organizationName = profile.Client.Organization
+	}
+	organization, ok := profile.Organizations[organizationName]
+	if !ok {
+		return nil, fmt.Errorf("%w: organization %s in %s", utility.ErrNotFound, organizationName, path)
+	}
+
+	channelName := options.ChannelName
+	if channelName == "" {
+		if len(profile.Channels) != 1 {
+			return nil, fmt.Errorf("%w: channel name is required when the profile does not have exactly one channel", utility.ErrInvalidArgument)
+		}
+		for name := range profile.Channels {
+			channelName = name
+		}
+	}
+
+	ret := &settings{
+		channelName:     channelName,
+		contractName:    options.ContractName,
+		mspId:           organization.MspId,
+		peerServerNames: map[string]string{},
+	}
+
+	peerNames := organization.Peers
+	if len(peerNames) == 0 {
+		for name := range profile.Peers {
+			peerNames = append(peerNames, name)
+		}
+		sort.Strings(peerNames)
+	}
+
+	for i, peerName := range peerNames {
+		peer, ok := profile.Peers[peerName]
+		if !ok {
+			return nil, fmt.Errorf("%w: peer %s in %s", utility.ErrNotFound, peerName, path)
+		}
+
+		address := peer.Url
+		for _, scheme := range []string{"grpcs://", "grpc://"} {
+			address = strings.TrimPrefix(address, scheme)
+		}
+		ret.peerAddresses = append(ret.peerAddresses, address)
+
+		tlsCertificateData, err := readConnectionProfilePem(directory, peer.TlsCaCerts)
+		if err != nil {
+			return nil, fmt.Errorf("tls ca of peer %s: %w", peerName, err)
+		}
+		tlsCertificate, err := parsePemCertificate(tlsCertificateData)
+		if err != nil {
+			return nil, fmt.Errorf("tls ca of peer %s: %w", peerName, err)
+		}
+
+		serverName, _ := peer.GrpcOptions["ssl-target-name-override"].(string)
+		if serverName == "" {
+			serverName, _ = peer.GrpcOptions["hostnameOverride"].(string)
+		}
+
+		ret.peerServerNames[address] = serverName
+
+		if i == 0 {
+			ret.TlsCetificate = tlsCertificate
+			ret.gatewayPeer = serverName
+		} else if !ret.TlsCetificate.Equal(tlsCertificate) {
+			return nil, fmt.Errorf("%w: peers of organization %s have different TLS CAs", utility.ErrInvalidArgument, organizationName)
+		}
+	}
+
+	if len(ret.peerAddresses) == 0 {
+		return nil, fmt.Errorf("%w: no peer in %s", utility.ErrInvalidArgument, path)
+	}
+
+	certificatePem := organization.SignedCert
+	if options.IdentityCertificatePath != "" {
+		certificatePem = connectionProfilePem{Path: options.IdentityCertificatePath}
+	}
+	ret.identityX509Certificate, err = readConnectionProfileCertificate(directory, certificatePem)
+	if err != nil {
+		return nil, fmt.Errorf("identity certificate: %w", err)
+	}
+
+	privateKeyPem := organization.AdminPrivateKey
+	if options.IdentityPrivateKeyPath != "" {
+		privateKeyPem = connectionProfilePem{Path: options.IdentityPrivateKeyPath}
+	}
+	ret.identityPeerPrivateKey, err = readConnectionProfileEcdsaKey(directory, privateKeyPem)
+	if err != nil {
+		return nil, fmt.Errorf("identity private key: %w", err)
+	}
+
+	return ret, nil
+}
+
+func readConnectionProfilePem(directory string, value connectionProfilePem) ([]byte, error) {
+	if value.Pem != "" {
+		return []byte(value.Pem), nil
+	}
+
+	if value.Path == "" {
+		return nil, fmt.Errorf("%w: missing pem or path", utility.ErrInvalidArgument)
+	}
+
+	return os.ReadFile(resolvePath(directory, value.Path))
+}
+
+func readConnectionProfileCertificate(directory string, value connectionProfilePem) (*x509.Certificate, error) {
+	data, err := readConnectionProfilePem(directory, value)
+	if err != nil {
+		return nil, err
+	}
+	return parsePemCertificate(data)
+}
+
+func readConnectionProfileEcdsaKey(directory string, value connectionProfilePem) (*ecdsa.PrivateKey, error) {
+	data, err := readConnectionProfilePem(directory, value)
+	if err != nil {
+		return nil, err
+	}
+	return parsePemEcdsaKey(data)
+}
@@ -0,0 +1,124 @@
+package utility_sig_graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sig_graph_scp/pkg/utility"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// relative paths are relative to the directory of the config file
+type settingsFile struct {
+	PeerAddresses           []string `json:"peer_addresses" yaml:"peer_addresses"`
+	ChannelName             string   `json:"channel_name" yaml:"channel_name"`
+	ContractName            string   `json:"contract_name" yaml:"contract_name"`
+	MspId                   string   `json:"msp_id" yaml:"msp_id"`
+	GatewayPeer             string   `json:"gateway_peer" yaml:"gateway_peer"`
+	TlsCertificatePath      string   `json:"tls_certificate_path" yaml:"tls_certificate_path"`
+	IdentityCertificatePath string   `json:"identity_certificate_path" yaml:"identity_certificate_path"`
+	IdentityPrivateKeyPath  string   `json:"identity_private_key_path" yaml:"identity_private_key_path"`
+	// by peer address, the first peer defaults to gateway_peer
+	PeerServerNames map[string]string `json:"peer_server_names" yaml:"peer_server_names"`
+}
+
+// read settings from a .yaml, .yml or .json file
+func NewSettingsFromFile(path string) (SettingsI, error) {
+	file := settingsFile{}
+	err := unmarshalConfigFile(path, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.PeerAddresses) == 0 {
+		return nil, fmt.Errorf("%w: missing peer_addresses in %s", utility.ErrInvalidArgument, path)
+	}
+
+	err = requireFields(path, [][2]string{
+		{"channel_name", file.ChannelName},
+		{"contract_name", file.ContractName},
+		{"msp_id", file.MspId},
+		{"tls_certificate_path", file.TlsCertificatePath},
+		{"identity_certificate_path", file.IdentityCertificatePath},
+		{"identity_private_key_path", file.IdentityPrivateKeyPath},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	directory := filepath.Dir(path)
+	ret := &settings{
+		peerAddresses: file.PeerAddresses,
+		channelName:   file.ChannelName,
+		contractName:  file.ContractName,
+		mspId:         file.MspId,
+		gatewayPeer:   file.GatewayPeer,
+	}
+
+	ret.peerServerNames = map[string]string{}
+	for address, serverName := range file.PeerServerNames {
+		ret.peerServerNames[address] = serverName
+	}
+	if _, ok := ret.peerServerNames[file.PeerAddresses[0]]; !ok {
+		ret.peerServerNames[file.PeerAddresses[0]] = file.GatewayPeer
+	}
+
+	ret.TlsCetificate, err = readPemCertificate(resolvePath(directory, file.TlsCertificatePath))
+	if err != nil {
+		return nil, err
+	}
+
+	ret.identityX509Certificate, err = readPemCertificate(resolvePath(directory, file.IdentityCertificatePath))
+	if err != nil {
+		return nil, err
+	}
+
+	ret.identityPeerPrivateKey, err = readPemEcdsaKey(resolvePath(directory, file.IdentityPrivateKeyPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func unmarshalConfigFile(path string, out any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, out)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, out)
+	default:
+		return fmt.Errorf("%w: unsupported config file extension %s", utility.ErrInvalidArgument, filepath.Ext(path))
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s", utility.ErrInvalidArgument, err.Error())
+	}
+
+	return nil
+}
+
+// fields are pairs of name and value
+func requireFields(path string, fields [][2]string) error {
+	for _, field := range fields {
+		if field[1] == "" {
+			return fmt.Errorf("%w: missing %s in %s", utility.ErrInvalidArgument, field[0], path)
+		}
+	}
+	return nil
+}
+
+func resolvePath(directory string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(directory, path)
+}
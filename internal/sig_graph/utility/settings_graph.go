@@ -0,0 +1,29 @@
+package utility_sig_graph
+
+// settings of one graph of a network, whose peers and channel are given
+// by the graph uri instead of the network settings
+type settingsGraph struct {
+	SettingsI
+	peerAddresses []string
+	channelName   string
+}
+
+// keep the peer addresses of settings if peerAddresses is empty
+func NewSettingsOfGraph(settings SettingsI, peerAddresses []string, channelName string) SettingsI {
+	if len(peerAddresses) == 0 {
+		peerAddresses = settings.PeerAddresses()
+	}
+	return &settingsGraph{
+		SettingsI:     settings,
+		peerAddresses: peerAddresses,
+		channelName:   channelName,
+	}
+}
+
+func (s *settingsGraph) PeerAddresses() []string {
+	return s.peerAddresses
+}
+
+func (s *settingsGraph) ChannelName() string {
+	return s.channelName
+}
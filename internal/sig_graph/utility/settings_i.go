@@ -3,7 +3,6 @@ package utility_sig_graph
 import (
 	"crypto/ecdsa"
 	"crypto/x509"
-	"os"
 	"sync"
 )
 
@@ -16,6 +15,9 @@ type SettingsI interface {
 	IdentityEDCSAKey() *ecdsa.PrivateKey
 	TlsX509Certificate() *x509.Certificate
 	GatewayPeer() string
+	// TLS server name of the peer at address, empty to verify the host of
+	// the address
+	PeerServerName(address string) string
 }
 
 var instance SettingsI
@@ -27,17 +29,9 @@ func SetGlobalSettings(iSettings SettingsI) {
 	mtx.Unlock()
 }
 
+// return nil if SetGlobalSettings has not been called
 func GetGlobalSettings() SettingsI {
+	mtx.Lock()
+	defer mtx.Unlock()
 	return instance
 }
-
-func init() {
-	_, ok := os.LookupEnv("BYPASS_ENV_SETTINGS")
-	if !ok {
-		setting, err := NewSettingsFromEnv()
-		if err != nil {
-			panic(err)
-		}
-		SetGlobalSettings(setting)
-	}
-}
@@ -5,6 +5,7 @@ import (
 	service_asset_transfer "sig_graph_scp/internal/asset_transfer/service"
 	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
 	model_asset_transfer "sig_graph_scp/pkg/asset_transfer/model"
+	"sig_graph_scp/pkg/model"
 	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 	"sig_graph_scp/pkg/utility"
@@ -30,11 +31,44 @@ type AssetTransferServiceApi interface {
 		message string,
 		isNewConnectionSecretOrPublic bool,
 	) (updatedRequest *model_asset_transfer.RequestToAcceptAsset, newSecret string, oldSecret string, err error)
+
+	// same as accepting with AcceptRequestToAcceptAsset, but return as soon
+	// as the transfer is endorsed without informing the sender.
+	// Call SendAcceptAsset once the transaction is committed
+	TransferAcceptedAsset(
+		ctx context.Context,
+		request *model_asset_transfer.RequestToAcceptAsset,
+		isNewConnectionSecretOrPublic bool,
+	) (updatedRequest *model_asset_transfer.RequestToAcceptAsset, newSecret string, oldSecret string, transaction api_sig_graph.LedgerTransactionI, err error)
+
+	// inform the sender whether the request is accepted
+	SendAcceptAsset(
+		ctx context.Context,
+		peer *model_asset_transfer.Peer,
+		request *model_asset_transfer.RequestToAcceptAsset,
+		acceptOrReject bool,
+		message string,
+	) error
 }
 
 type Options struct {
 	// number of candidate id to generate when transfer asset
 	NumberOfCandidates uint32
+	// defaults to the wall clock
+	Clock utility.ClockI
+	// how far the time of a request may be from Clock when it is accepted,
+	// defaults to DefaultAcceptClockSkewLimits
+	AcceptClockSkewLimits *utility.ClockSkewLimits
+	// format of the generated candidate ids, default to uuid v4
+	IdFormat model.ENodeIdFormat
+	// call peers with mutual TLS, as the user sending the call. Plaintext if nil
+	Tls *utility.GrpcTlsOptions
+}
+
+// requests are usually accepted by hand, so they stay acceptable for a day
+var DefaultAcceptClockSkewLimits = utility.ClockSkewLimits{
+	MaxPast:   24 * time.Hour,
+	MaxFuture: time.Minute,
 }
 
 type assetTransferServiceApi struct {
@@ -44,11 +78,29 @@ type assetTransferServiceApi struct {
 func NewAssetTransferServiceApi(sigGraphClientApi api_sig_graph.SigGraphClientApi, options *Options) (AssetTransferServiceApi, error) {
 	connPool := utility.NewGrpcConnectionPool()
 	numberOfCandidates := uint32(6)
+	var clock utility.ClockI = utility.NewClockWall()
+	acceptClockSkewLimits := DefaultAcceptClockSkewLimits
+	idFormat := model.ENodeIdFormatUuidV4
 	if options != nil {
 		numberOfCandidates = options.NumberOfCandidates
+		if options.Clock != nil {
+			clock = options.Clock
+		}
+		if options.AcceptClockSkewLimits != nil {
+			acceptClockSkewLimits = *options.AcceptClockSkewLimits
+		}
+		if options.IdFormat != "" {
+			idFormat = options.IdFormat
+		}
+		if options.Tls != nil {
+			connPool = utility.NewGrpcConnectionPoolTls(options.Tls)
+		}
+	}
+	secretGenerator := utility.NewSecretIdGeneratorCrypto(20)
+	idGenerator, err := service_sig_graph.NewIdGenerateService(idFormat, sigGraphClientApi.GetGraphName(), clock)
+	if err != nil {
+		return nil, err
 	}
-	secretGenerator := service_asset_transfer.NewSecretIdGeneratorCrypto(20)
-	idGenerator := service_sig_graph.NewIdGenerateServiceUuid(sigGraphClientApi.GetGraphName())
 	nodeSigningService := service_sig_graph.NewNodeSigningService()
 	hashGenerator := utility.NewHashedIdGeneratorService()
 	cloner := utility.NewCloner()
@@ -62,6 +114,8 @@ func NewAssetTransferServiceApi(sigGraphClientApi api_sig_graph.SigGraphClientAp
 		sigGraphClientApi,
 		hashGenerator,
 		cloner,
+		clock,
+		&acceptClockSkewLimits,
 	)
 
 	return &assetTransferServiceApi{
@@ -106,3 +160,31 @@ func (s *assetTransferServiceApi) AcceptRequestToAcceptAsset(
 		isNewConnectionSecretOrPublic,
 	)
 }
+
+func (s *assetTransferServiceApi) TransferAcceptedAsset(
+	ctx context.Context,
+	request *model_asset_transfer.RequestToAcceptAsset,
+	isNewConnectionSecretOrPublic bool,
+) (updatedRequest *model_asset_transfer.RequestToAcceptAsset, newSecret string, oldSecret string, transaction api_sig_graph.LedgerTransactionI, err error) {
+	return s.assetTransferService.TransferAcceptedAsset(
+		ctx,
+		request,
+		isNewConnectionSecretOrPublic,
+	)
+}
+
+func (s *assetTransferServiceApi) SendAcceptAsset(
+	ctx context.Context,
+	peer *model_asset_transfer.Peer,
+	request *model_asset_transfer.RequestToAcceptAsset,
+	acceptOrReject bool,
+	message string,
+) error {
+	return s.assetTransferService.SendAcceptAsset(
+		ctx,
+		peer,
+		request,
+		acceptOrReject,
+		message,
+	)
+}
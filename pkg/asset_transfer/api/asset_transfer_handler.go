@@ -32,6 +32,32 @@ func NewAssetTransferHandlerFilterExposedSecretIdsNotFound(
 	), nil
 }
 
+// drop candidates whose signature the ledger would reject, and reject the
+// request if none is left
+func NewAssetTransferHandlerFilterCandidatesInvalidSignature(
+	handler AssetTransferHandlerI,
+	sigGraphClient api_sig_graph.SigGraphClientApi,
+) (AssetTransferHandlerI, error) {
+	return service_asset_transfer.NewAssetTransferHandlerFilterCandidatesInvalidSignature(
+		handler,
+		sigGraphClient,
+		utility.NewHashedIdGeneratorService(),
+		utility.NewCloner(),
+	), nil
+}
+
+// pass only requests signed by the sender, who must own the asset on the
+// ledger, which must not be finalized
+func NewAssetTransferHandlerFilterSenderSignature(
+	handler AssetTransferHandlerI,
+	sigGraphClient api_sig_graph.SigGraphClientApi,
+) (AssetTransferHandlerI, error) {
+	return service_asset_transfer.NewAssetTransferHandlerFilterSenderSignature(
+		handler,
+		sigGraphClient,
+	), nil
+}
+
 func NewAssetTransferHandlerDefault() (AssetTransferHandlerI, error) {
 	return service_asset_transfer.NewAssetTransferHandlerDefault(), nil
 }
@@ -2,9 +2,11 @@ package api_asset_transfer
 
 import (
 	"context"
+	"fmt"
 	service_asset_transfer "sig_graph_scp/internal/asset_transfer/service"
 	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
 	"sig_graph_scp/pkg/utility"
+	"time"
 
 	EventBus "github.com/asaskevich/eventbus"
 )
@@ -24,12 +26,34 @@ type AssetAcceptHandlerI interface {
 	service_asset_transfer.AssetAcceptHandlerI
 }
 
+type OutboundRequestPeerResolverI interface {
+	service_asset_transfer.OutboundRequestPeerResolverI
+}
+
 type AssetTransferServerApiOptions struct {
 	CustomHandlers                       []AssetTransferHandlerI
 	NewReceivedRequestToAcceptAssetTopic string
 	NewReceivedAssetAcceptTopic          string
 	SigGraphApiClient                    api_sig_graph.SigGraphClientApi
 	EventBus                             EventBus.Bus
+	// defaults to the wall clock
+	Clock utility.ClockI
+	// how far the time of received requests may be from Clock,
+	// defaults to DefaultRequestClockSkewLimits
+	ClockSkewLimits *utility.ClockSkewLimits
+	// ids of received requests are rejected unless they are valid, defaults
+	// to the graphs of SigGraphApiClient, or to any graph if it is nil
+	NodeIdValidator api_sig_graph.NodeIdValidatorI
+	// require TLS client certificates of the CAs, the certificate of a request
+	// must be of its OwnerPublicKey. Plaintext if nil
+	Tls *utility.GrpcTlsOptions
+	// peers our requests were sent to, required with Tls. The certificate of
+	// an accept must be of the peer of its request
+	OutboundRequestPeers OutboundRequestPeerResolverI
+	// reject requests unless they are signed by the owner of the asset on the
+	// ledger of SigGraphApiClient, see NewAssetTransferHandlerFilterSenderSignature.
+	// Ignored with CustomHandlers
+	RequireSenderSignature bool
 }
 
 type assetTransferServerApi struct {
@@ -41,12 +65,23 @@ type assetTransferServerApi struct {
 const defaultNewReceivedRequestToAcceptAssetTopic = "new_request_to_accept_asset_event"
 const defaultNewReceivedAssetAcceptTopic = "new_received_asset_accept_topic"
 
+var DefaultRequestClockSkewLimits = utility.ClockSkewLimits{
+	MaxPast:   5 * time.Minute,
+	MaxFuture: time.Minute,
+}
+
 func NewAssetTransferServerApi(
 	serverAddress string,
 	option AssetTransferServerApiOptions,
 ) (AssetTransferServerApi, error) {
 	multiAssetTransferHandler := service_asset_transfer.NewAssetTransferHandlerMultiple([]service_asset_transfer.AssetTransferHandlerI{})
 	ctx := context.Background()
+	if option.RequireSenderSignature && option.CustomHandlers == nil && option.SigGraphApiClient == nil {
+		return nil, fmt.Errorf("%w: verifying sender signatures needs SigGraphApiClient", utility.ErrInvalidArgument)
+	}
+	if option.Tls != nil && option.OutboundRequestPeers == nil {
+		return nil, fmt.Errorf("%w: verifying the peers accepting requests needs OutboundRequestPeers", utility.ErrInvalidArgument)
+	}
 	if option.CustomHandlers != nil {
 		for i := range option.CustomHandlers {
 			err := multiAssetTransferHandler.AddHandler(ctx, option.CustomHandlers[i])
@@ -89,7 +124,26 @@ func NewAssetTransferServerApi(
 				return nil, err
 			}
 
-			err = multiAssetTransferHandler.AddHandler(ctx, secretIdFilterNotFound)
+			candidateFilterInvalidSignature, err := NewAssetTransferHandlerFilterCandidatesInvalidSignature(
+				secretIdFilterNotFound,
+				option.SigGraphApiClient,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			// the signature covers the candidates before they are filtered
+			if option.RequireSenderSignature {
+				candidateFilterInvalidSignature, err = NewAssetTransferHandlerFilterSenderSignature(
+					candidateFilterInvalidSignature,
+					option.SigGraphApiClient,
+				)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			err = multiAssetTransferHandler.AddHandler(ctx, candidateFilterInvalidSignature)
 			if err != nil {
 				return nil, err
 			}
@@ -120,11 +174,34 @@ func NewAssetTransferServerApi(
 
 	hashedIdGenerator := utility.NewHashedIdGeneratorService()
 
+	var clock utility.ClockI = utility.NewClockWall()
+	if option.Clock != nil {
+		clock = option.Clock
+	}
+	clockSkewLimits := DefaultRequestClockSkewLimits
+	if option.ClockSkewLimits != nil {
+		clockSkewLimits = *option.ClockSkewLimits
+	}
+
+	nodeIdValidator := option.NodeIdValidator
+	if nodeIdValidator == nil {
+		if option.SigGraphApiClient != nil {
+			nodeIdValidator = api_sig_graph.NewNodeIdValidatorOfClient(option.SigGraphApiClient)
+		} else {
+			nodeIdValidator = api_sig_graph.NewNodeIdValidator(nil, nil)
+		}
+	}
+
 	assetTransferServer := service_asset_transfer.NewAssetTransferServerGrpc(
 		multiAssetTransferHandler,
 		assetAcceptHandler,
 		serverAddress,
 		hashedIdGenerator,
+		clock,
+		&clockSkewLimits,
+		nodeIdValidator,
+		option.Tls,
+		option.OutboundRequestPeers,
 	)
 	return &assetTransferServerApi{
 		assetTransferServer: assetTransferServer,
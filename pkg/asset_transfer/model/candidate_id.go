@@ -1,7 +1,8 @@
 package model_asset_transfer
 
 type CandidateId struct {
-	Id        string `json:"id"`
-	Secret    string `json:"secret"`
-	Signature string `json:"signature"`
+	Id              string `json:"id"`
+	Secret          string `json:"secret"`
+	Signature       string `json:"signature"`
+	SignatureScheme string `json:"signature_scheme"`
 }
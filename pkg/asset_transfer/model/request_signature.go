@@ -0,0 +1,9 @@
+package model_asset_transfer
+
+import "sig_graph_scp/pkg/model"
+
+// signature of a request to accept an asset by the owner of the asset
+type RequestSignature struct {
+	Signature       string                 `json:"signature"`
+	SignatureScheme model.ESignatureScheme `json:"signature_scheme"`
+}
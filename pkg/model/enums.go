@@ -5,6 +5,7 @@ type ECreationProcess = string
 const (
 	ECreationProcessCreate   = "create"
 	ECreationProcessTransfer = "transfer"
+	ECreationProcessSplit    = "split"
 )
 
 type ENodeType = string
@@ -27,3 +28,73 @@ const (
 	ERequestToAcceptAssetStatusAccepted ERequestToAcceptAssetStatus = "accepted"
 	ERequestToAcceptAssetStatusRejected ERequestToAcceptAssetStatus = "rejected"
 )
+
+// how the signed payload of a node is built, stored in the node next to its signature
+type ESignatureScheme = string
+
+const (
+	// node json without the signature field, re-encoded by encoding/json.
+	// Used by nodes signed before signature schemes were introduced
+	ESignatureSchemeLegacy ESignatureScheme = ""
+	// canonical json of the node without the signature field, see README
+	ESignatureSchemeCanonicalJsonV1 ESignatureScheme = "sgp-canonical-json-v1"
+)
+
+// change of a node on the ledger
+type ENodeEventType = string
+
+const (
+	// node created from ingredients or by splitting another node
+	ENodeEventTypeCreated ENodeEventType = "created"
+	// node created by transferring another node
+	ENodeEventTypeTransferred ENodeEventType = "transferred"
+	// node used as an ingredient, transferred or split, it cannot change anymore
+	ENodeEventTypeFinalized ENodeEventType = "finalized"
+)
+
+// state of a transaction submitted to the ledger
+type ELedgerTransactionStatus = string
+
+const (
+	// endorsed and waiting to be committed
+	ELedgerTransactionStatusSubmitting ELedgerTransactionStatus = "submitting"
+	ELedgerTransactionStatusCommitted  ELedgerTransactionStatus = "committed"
+	ELedgerTransactionStatusFailed     ELedgerTransactionStatus = "failed"
+	// not committed within the commit timeout, it may still be committed.
+	// The ledger is checked again by transaction id
+	ELedgerTransactionStatusTimedOut ELedgerTransactionStatus = "timed_out"
+	// committed, but what had to be done once it is committed, e.g. caching
+	// the created asset, failed. Message holds the reason
+	ELedgerTransactionStatusFollowUpFailed ELedgerTransactionStatus = "follow_up_failed"
+)
+
+// what a unit of measure quantifies, only units of the same dimension convert
+type EUnitDimension = string
+
+const (
+	EUnitDimensionMass   EUnitDimension = "mass"
+	EUnitDimensionVolume EUnitDimension = "volume"
+	EUnitDimensionCount  EUnitDimension = "count"
+)
+
+// ledger backing a graph, named in its graph uri
+type EGraphBackend = string
+
+const (
+	// Hyperledger Fabric network
+	EGraphBackendHyperledger EGraphBackend = "hyper"
+	// in-process ledger, for development
+	EGraphBackendMemory EGraphBackend = "memory"
+)
+
+// format of the id of a node in its graph
+type ENodeIdFormat = string
+
+const (
+	// random uuid v4, the format of the nodes created before the others
+	ENodeIdFormatUuidV4 ENodeIdFormat = "uuid4"
+	// uuid v7, sorted by creation time
+	ENodeIdFormatUuidV7 ENodeIdFormat = "uuid7"
+	// ulid, sorted by creation time
+	ENodeIdFormatUlid ENodeIdFormat = "ulid"
+)
@@ -19,6 +19,8 @@ type assetController struct {
 	keyRepository        repository_server.UserKeyRepositoryI
 	transactionManager   repository_server.TransactionManagerI
 	hashGeneratorService utility.HashedIdGeneratorServiceI
+	secretIdGenerator    utility.SecretIdGeneratorI
+	ledgerTransactions   LedgerTransactionControllerI
 }
 
 func NewAssetController(
@@ -27,6 +29,8 @@ func NewAssetController(
 	keyRepository repository_server.UserKeyRepositoryI,
 	transactionManager repository_server.TransactionManagerI,
 	hashGeneratorService utility.HashedIdGeneratorServiceI,
+	secretIdGenerator utility.SecretIdGeneratorI,
+	ledgerTransactions LedgerTransactionControllerI,
 ) AssetControllerI {
 	return &assetController{
 		api:                  api,
@@ -34,6 +38,8 @@ func NewAssetController(
 		keyRepository:        keyRepository,
 		transactionManager:   transactionManager,
 		hashGeneratorService: hashGeneratorService,
+		secretIdGenerator:    secretIdGenerator,
+		ledgerTransactions:   ledgerTransactions,
 	}
 }
 
@@ -48,22 +54,22 @@ func (c *assetController) CreateAsset(
 	ingredientSecretIds []string,
 	secretIds []string,
 	ingredientSignatures []string,
-) (*model_server.Asset, error) {
+) (*model_server.Asset, *model_server.LedgerTransaction, error) {
 	if len(ingredients) != len(ingredientSecretIds) {
-		return nil, fmt.Errorf("mismatch length")
+		return nil, nil, fmt.Errorf("mismatch length")
 	}
 
 	if len(ingredients) != len(secretIds) {
-		return nil, fmt.Errorf("mismatch length")
+		return nil, nil, fmt.Errorf("mismatch length")
 	}
 
 	if len(ingredients) != len(ingredientSignatures) {
-		return nil, fmt.Errorf("mismatch length")
+		return nil, nil, fmt.Errorf("mismatch length")
 	}
 
 	transactionId, err := c.transactionManager.BypassTransaction(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer c.transactionManager.StopBypassedTransaction(ctx, transactionId)
 
@@ -73,7 +79,7 @@ func (c *assetController) CreateAsset(
 	}
 	ownerKeys, err := c.keyRepository.FetchKeyPairsOfUser(ctx, transactionId, user, keyPagination)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var ownerKey *model_server.UserKeyPair = nil
@@ -84,7 +90,7 @@ func (c *assetController) CreateAsset(
 	}
 
 	if ownerKey == nil {
-		return nil, fmt.Errorf("%w: no owner key with id %d", utility.ErrNotFound, ownerKeyId)
+		return nil, nil, fmt.Errorf("%w: no owner key with id %d", utility.ErrNotFound, ownerKeyId)
 	}
 
 	sigGraphIngredients := make([]model_sig_graph.Asset, 0, len(ingredients))
@@ -97,7 +103,7 @@ func (c *assetController) CreateAsset(
 		Private: ownerKey.Private,
 	}
 
-	asset, err := c.api.CreateAsset(
+	asset, transaction, err := c.api.CreateAssetAsync(
 		ctx,
 		materialName,
 		unit,
@@ -110,49 +116,446 @@ func (c *assetController) CreateAsset(
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	secretParentIds := map[string]model_server.PrivateId{}
+	ingredientIds := make([]model_server.NodeId, 0, len(ingredients))
 	for i := range ingredients {
-		thisId := ingredients[i].Id
-		thisSecret := ingredientSecretIds[i]
+		ingredientIds = append(ingredientIds, ingredients[i].Id)
+	}
+	secretParentIds, _, err := c.ingredientPrivateIds(ctx, model_server.NodeId(asset.Id), ingredientIds, ingredientSecretIds, secretIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespace := fmt.Sprintf("%d", user.ID)
+	modelNode := model_server.FromSigGraphNode(&asset.Node, 0, namespace, secretParentIds, map[string]model_server.PrivateId{})
+	modelAsset := model_server.FromSigGraphAsset(asset, &modelNode)
+
+	// the asset is cached once it is on the ledger
+	ledgerTransaction, err := c.ledgerTransactions.TrackLedgerTransaction(
+		ctx,
+		user,
+		"CreateAsset",
+		transaction,
+		func(ctx context.Context) error {
+			transactionId, err := c.transactionManager.BypassTransaction(ctx)
+			if err != nil {
+				return err
+			}
+			defer c.transactionManager.StopBypassedTransaction(ctx, transactionId)
+
+			savedAsset := modelAsset
+			return c.repository.SaveAsset(ctx, transactionId, &savedAsset)
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &modelAsset, ledgerTransaction, nil
+}
+
+func (c *assetController) ManufactureAsset(
+	ctx context.Context,
+	user *model_server.User,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKeyId model_server.UserKeyPairId,
+	ingredientDbIds []model_server.NodeDbId,
+	isIngredientPrivate []bool,
+) (*model_server.Asset, []model_server.Asset, *model_server.LedgerTransaction, error) {
+	if len(ingredientDbIds) != len(isIngredientPrivate) {
+		return nil, nil, nil, fmt.Errorf("%w: mismatch length", utility.ErrInvalidArgument)
+	}
+
+	transactionId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, transactionId)
+
+	keyPagination := repository_server.PaginationOption[model_server.UserKeyPairId]{
+		MinId: 0,
+		Limit: math.MaxInt,
+	}
+	keys, err := c.keyRepository.FetchKeyPairsOfUser(ctx, transactionId, user, keyPagination)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var ownerKey *model_server.UserKeyPair = nil
+	keysByPublic := map[string]*model_server.UserKeyPair{}
+	for i := range keys {
+		if keys[i].Id == ownerKeyId {
+			ownerKey = &keys[i]
+		}
+		keysByPublic[keys[i].Public] = &keys[i]
+	}
+
+	if ownerKey == nil {
+		return nil, nil, nil, fmt.Errorf("%w: no owner key with id %d", utility.ErrNotFound, ownerKeyId)
+	}
+
+	dbIds := map[model_server.NodeDbId]bool{}
+	for _, dbId := range ingredientDbIds {
+		if dbIds[dbId] {
+			return nil, nil, nil, fmt.Errorf("%w: duplicated ingredient %d", utility.ErrInvalidArgument, dbId)
+		}
+		dbIds[dbId] = true
+	}
+
+	namespace := fmt.Sprintf("%d", user.ID)
+	cachedIngredients, err := c.repository.FetchAssetsByDbIds(ctx, transactionId, namespace, dbIds)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cachedIngredientsByDbId := map[model_server.NodeDbId]*model_server.Asset{}
+	for i := range cachedIngredients {
+		cachedIngredientsByDbId[cachedIngredients[i].NodeDbId] = &cachedIngredients[i]
+	}
+
+	ingredients := make([]*model_server.Asset, 0, len(ingredientDbIds))
+	ingredientIds := make([]model_server.NodeId, 0, len(ingredientDbIds))
+	sigGraphIngredients := make([]model_sig_graph.Asset, 0, len(ingredientDbIds))
+	ingredientKeys := make([]*model_sig_graph.UserKeyPair, 0, len(ingredientDbIds))
+	ingredientSecretIds := make([]string, 0, len(ingredientDbIds))
+	secretIds := make([]string, 0, len(ingredientDbIds))
+	for i, dbId := range ingredientDbIds {
+		ingredient, ok := cachedIngredientsByDbId[dbId]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w: no cached asset with db id %d", utility.ErrNotFound, dbId)
+		}
+
+		key, ok := keysByPublic[ingredient.OwnerPublicKey]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w: asset %s is not owned by the user", utility.ErrPermissionDenied, ingredient.Id)
+		}
+
+		// the signature of the finalized ingredient covers its edges on the ledger
+		sigGraphIngredient, err := c.api.GetAssetById(ctx, ingredient.Id)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 
-		if thisSecret != "" {
-			thisHash, err := c.hashGeneratorService.GenerateHashedId(ctx, string(thisId), thisSecret)
+		ingredientSecretId := ""
+		secretId := ""
+		if isIngredientPrivate[i] {
+			ingredientSecretId, err = c.secretIdGenerator.NewSecretId(ctx)
 			if err != nil {
-				return nil, err
+				return nil, nil, nil, err
 			}
 
-			otherId := asset.Id
-			otherSecret := secretIds[i]
-			otherHash, err := c.hashGeneratorService.GenerateHashedId(ctx, string(otherId), otherSecret)
+			secretId, err = c.secretIdGenerator.NewSecretId(ctx)
 			if err != nil {
-				return nil, err
+				return nil, nil, nil, err
 			}
+		}
 
-			privateId := model_server.PrivateId{
-				ThisId:     thisId,
-				ThisHash:   thisHash,
-				ThisSecret: thisSecret,
+		ingredients = append(ingredients, ingredient)
+		ingredientIds = append(ingredientIds, ingredient.Id)
+		sigGraphIngredients = append(sigGraphIngredients, *sigGraphIngredient)
+		ingredientKeys = append(ingredientKeys, &model_sig_graph.UserKeyPair{
+			Public:  key.Public,
+			Private: key.Private,
+		})
+		ingredientSecretIds = append(ingredientSecretIds, ingredientSecretId)
+		secretIds = append(secretIds, secretId)
+	}
 
-				OtherId:     model_server.NodeId(otherId),
-				OtherSecret: otherSecret,
-				OtherHash:   otherHash,
+	sigGraphOwnerKeyPair := &model_sig_graph.UserKeyPair{
+		Public:  ownerKey.Public,
+		Private: ownerKey.Private,
+	}
+
+	asset, updatedIngredients, transaction, err := c.api.ManufactureAssetAsync(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		sigGraphOwnerKeyPair,
+		sigGraphIngredients,
+		ingredientKeys,
+		ingredientSecretIds,
+		secretIds,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	assetId := model_server.NodeId(asset.Id)
+	secretParentIds, secretChildrenIds, err := c.ingredientPrivateIds(ctx, assetId, ingredientIds, ingredientSecretIds, secretIds)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	modelNode := model_server.FromSigGraphNode(&asset.Node, 0, namespace, secretParentIds, map[string]model_server.PrivateId{})
+	modelAsset := model_server.FromSigGraphAsset(asset, &modelNode)
+
+	// keep the known secrets of the ingredients and add the edge toward the asset
+	modelIngredients := make([]model_server.Asset, 0, len(updatedIngredients))
+	for i := range updatedIngredients {
+		privateParentsIds := map[string]model_server.PrivateId{}
+		for hash := range ingredients[i].PrivateParentsIds {
+			privateParentsIds[hash] = ingredients[i].PrivateParentsIds[hash]
+		}
+		privateChildrenIds := map[string]model_server.PrivateId{}
+		for hash := range ingredients[i].PrivateChildrenIds {
+			privateChildrenIds[hash] = ingredients[i].PrivateChildrenIds[hash]
+		}
+		if privateId, ok := secretChildrenIds[ingredientIds[i]]; ok {
+			privateChildrenIds[privateId.ThisHash] = privateId
+		}
+
+		ingredientNode := model_server.FromSigGraphNode(&updatedIngredients[i].Node, ingredients[i].NodeDbId, namespace, privateParentsIds, privateChildrenIds)
+		modelIngredients = append(modelIngredients, model_server.FromSigGraphAsset(&updatedIngredients[i], &ingredientNode))
+	}
+
+	// the asset and the ingredients are cached once they are on the ledger
+	ledgerTransaction, err := c.ledgerTransactions.TrackLedgerTransaction(
+		ctx,
+		user,
+		"CreateAsset",
+		transaction,
+		func(ctx context.Context) error {
+			transactionId, err := c.transactionManager.BypassTransaction(ctx)
+			if err != nil {
+				return err
+			}
+			defer c.transactionManager.StopBypassedTransaction(ctx, transactionId)
+
+			savedAsset := modelAsset
+			err = c.repository.SaveAsset(ctx, transactionId, &savedAsset)
+			if err != nil {
+				return err
 			}
 
-			secretParentIds[thisHash] = privateId
+			for i := range modelIngredients {
+				savedIngredient := modelIngredients[i]
+				err = c.repository.SaveAsset(ctx, transactionId, &savedIngredient)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &modelAsset, modelIngredients, ledgerTransaction, nil
+}
+
+// private edges between a new asset and its ingredients. Return the
+// edges of the asset by hash and the edge of each ingredient toward the
+// asset by ingredient id
+func (c *assetController) ingredientPrivateIds(
+	ctx context.Context,
+	assetId model_server.NodeId,
+	ingredientIds []model_server.NodeId,
+	ingredientSecretIds []string,
+	secretIds []string,
+) (secretParentIds map[string]model_server.PrivateId, secretChildrenIds map[model_server.NodeId]model_server.PrivateId, err error) {
+	secretParentIds = map[string]model_server.PrivateId{}
+	secretChildrenIds = map[model_server.NodeId]model_server.PrivateId{}
+	for i := range ingredientIds {
+		ingredientHash := ""
+		if ingredientSecretIds[i] != "" {
+			ingredientHash, err = c.hashGeneratorService.GenerateHashedId(ctx, string(ingredientIds[i]), ingredientSecretIds[i])
+			if err != nil {
+				return
+			}
+		}
+
+		assetHash := ""
+		if secretIds[i] != "" {
+			assetHash, err = c.hashGeneratorService.GenerateHashedId(ctx, string(assetId), secretIds[i])
+			if err != nil {
+				return
+			}
+		}
+
+		privateId := model_server.PrivateId{
+			ThisId:     ingredientIds[i],
+			ThisHash:   ingredientHash,
+			ThisSecret: ingredientSecretIds[i],
+
+			OtherId:     assetId,
+			OtherHash:   assetHash,
+			OtherSecret: secretIds[i],
+		}
+
+		if ingredientHash != "" {
+			secretParentIds[ingredientHash] = privateId
+		}
+		if assetHash != "" {
+			secretChildrenIds[ingredientIds[i]] = model_server.ReversePrivateId(&privateId)
 		}
 	}
 
+	return
+}
+
+func (c *assetController) SplitAsset(
+	ctx context.Context,
+	user *model_server.User,
+	assetId model_server.NodeId,
+	quantities []decimal.Decimal,
+	childrenSecretIds []string,
+	parentSecretIds []string,
+) (*model_server.Asset, []model_server.Asset, *model_server.LedgerTransaction, error) {
+	if len(quantities) != len(childrenSecretIds) || len(quantities) != len(parentSecretIds) {
+		return nil, nil, nil, fmt.Errorf("%w: mismatch length", utility.ErrInvalidArgument)
+	}
+
+	// the signature of the updated asset covers its edges on the ledger
+	asset, err := c.GetAssetById(ctx, user, assetId, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	transactionId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, transactionId)
+
+	keyPagination := repository_server.PaginationOption[model_server.UserKeyPairId]{
+		MinId: 0,
+		Limit: math.MaxInt,
+	}
+	keys, err := c.keyRepository.FetchKeyPairsOfUser(ctx, transactionId, user, keyPagination)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var ownerKey *model_server.UserKeyPair = nil
+	for i := range keys {
+		if keys[i].Public == asset.OwnerPublicKey {
+			ownerKey = &keys[i]
+		}
+	}
+
+	if ownerKey == nil {
+		return nil, nil, nil, fmt.Errorf("%w: asset %s is not owned by the user", utility.ErrPermissionDenied, assetId)
+	}
+
+	sigGraphAsset := model_server.ToSigGraphAsset(asset)
+	sigGraphOwnerKeyPair := &model_sig_graph.UserKeyPair{
+		Public:  ownerKey.Public,
+		Private: ownerKey.Private,
+	}
+
+	updatedAsset, children, transaction, err := c.api.SplitAssetAsync(
+		ctx,
+		&sigGraphAsset,
+		sigGraphOwnerKeyPair,
+		quantities,
+		childrenSecretIds,
+		parentSecretIds,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// keep the known secrets of the asset and add the ones of the children
+	assetPrivateParentsIds := map[string]model_server.PrivateId{}
+	for hash := range asset.PrivateParentsIds {
+		assetPrivateParentsIds[hash] = asset.PrivateParentsIds[hash]
+	}
+	assetPrivateChildrenIds := map[string]model_server.PrivateId{}
+	for hash := range asset.PrivateChildrenIds {
+		assetPrivateChildrenIds[hash] = asset.PrivateChildrenIds[hash]
+	}
+
 	namespace := fmt.Sprintf("%d", user.ID)
-	modelNode := model_server.FromSigGraphNode(&asset.Node, 0, namespace, secretParentIds, map[string]model_server.PrivateId{})
-	modelAsset := model_server.FromSigGraphAsset(asset, &modelNode)
+	modelChildren := make([]model_server.Asset, 0, len(children))
+	for i := range children {
+		childId := model_server.NodeId(children[i].Id)
 
-	// save asset
-	c.repository.SaveAsset(ctx, transactionId, &modelAsset)
+		childHash := ""
+		if childrenSecretIds[i] != "" {
+			childHash, err = c.hashGeneratorService.GenerateHashedId(ctx, string(childId), childrenSecretIds[i])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
 
-	return &modelAsset, nil
+		parentHash := ""
+		if parentSecretIds[i] != "" {
+			parentHash, err = c.hashGeneratorService.GenerateHashedId(ctx, string(asset.Id), parentSecretIds[i])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+
+		if childHash != "" {
+			assetPrivateChildrenIds[childHash] = model_server.PrivateId{
+				ThisId:      childId,
+				ThisSecret:  childrenSecretIds[i],
+				ThisHash:    childHash,
+				OtherId:     asset.Id,
+				OtherSecret: parentSecretIds[i],
+				OtherHash:   parentHash,
+			}
+		}
+
+		childPrivateParentsIds := map[string]model_server.PrivateId{}
+		if parentHash != "" {
+			childPrivateParentsIds[parentHash] = model_server.PrivateId{
+				ThisId:      asset.Id,
+				ThisSecret:  parentSecretIds[i],
+				ThisHash:    parentHash,
+				OtherId:     childId,
+				OtherSecret: childrenSecretIds[i],
+				OtherHash:   childHash,
+			}
+		}
+
+		childNode := model_server.FromSigGraphNode(&children[i].Node, 0, namespace, childPrivateParentsIds, map[string]model_server.PrivateId{})
+		modelChildren = append(modelChildren, model_server.FromSigGraphAsset(&children[i], &childNode))
+	}
+
+	assetNode := model_server.FromSigGraphNode(&updatedAsset.Node, asset.NodeDbId, namespace, assetPrivateParentsIds, assetPrivateChildrenIds)
+	modelAsset := model_server.FromSigGraphAsset(updatedAsset, &assetNode)
+
+	// the asset and its children are cached once they are on the ledger
+	ledgerTransaction, err := c.ledgerTransactions.TrackLedgerTransaction(
+		ctx,
+		user,
+		"SplitAsset",
+		transaction,
+		func(ctx context.Context) error {
+			transactionId, err := c.transactionManager.BypassTransaction(ctx)
+			if err != nil {
+				return err
+			}
+			defer c.transactionManager.StopBypassedTransaction(ctx, transactionId)
+
+			savedAsset := modelAsset
+			err = c.repository.SaveAsset(ctx, transactionId, &savedAsset)
+			if err != nil {
+				return err
+			}
+
+			for i := range modelChildren {
+				savedChild := modelChildren[i]
+				err = c.repository.SaveAsset(ctx, transactionId, &savedChild)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &modelAsset, modelChildren, ledgerTransaction, nil
 }
 
 func (c *assetController) GetAssetById(ctx context.Context, user *model_server.User, id model_server.NodeId, useCache bool) (*model_server.Asset, error) {
@@ -203,6 +606,7 @@ func (c *assetController) GetOwnedAssetsFromCache(
 	user *model_server.User,
 	isTransferred []bool,
 	pagination repository_server.PaginationOption[model_server.NodeDbId],
+	unit string,
 ) ([]model_server.Asset, error) {
 	transactionId, err := c.transactionManager.BypassTransaction(ctx)
 	if err != nil {
@@ -241,13 +645,14 @@ func (c *assetController) GetOwnedAssetsFromCache(
 		ret = append(ret, assets...)
 	}
 
-	return ret, nil
+	return c.convertAssetsToUnit(ret, unit)
 }
 
 func (c *assetController) GetAssetsFromCacheByDbId(
 	ctx context.Context,
 	user *model_server.User,
 	ids map[model_server.NodeDbId]bool,
+	unit string,
 ) ([]model_server.Asset, error) {
 	transactionId, err := c.transactionManager.BypassTransaction(ctx)
 	if err != nil {
@@ -262,5 +667,29 @@ func (c *assetController) GetAssetsFromCacheByDbId(
 		return nil, err
 	}
 
-	return ret, nil
+	return c.convertAssetsToUnit(ret, unit)
+}
+
+// fail with ErrInvalidArgument if an asset does not convert to unit
+func (c *assetController) convertAssetsToUnit(assets []model_server.Asset, unit string) ([]model_server.Asset, error) {
+	if unit == "" {
+		return assets, nil
+	}
+
+	unitRegistry := c.api.GetUnitRegistry()
+	unit, err := unitRegistry.Normalize(unit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range assets {
+		quantity, err := unitRegistry.Convert(assets[i].Quantity, assets[i].Unit, unit)
+		if err != nil {
+			return nil, fmt.Errorf("%w: asset %s", err, assets[i].Id)
+		}
+		assets[i].Quantity = quantity
+		assets[i].Unit = unit
+	}
+
+	return assets, nil
 }
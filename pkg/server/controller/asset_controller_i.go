@@ -9,6 +9,8 @@ import (
 )
 
 type AssetControllerI interface {
+	// return once the asset is endorsed, it is cached when the
+	// ledger transaction is committed
 	CreateAsset(
 		ctx context.Context,
 		user *model_server.User,
@@ -20,7 +22,36 @@ type AssetControllerI interface {
 		ingredientSecretIds []string,
 		secretIds []string,
 		ingredientSignatures []string,
-	) (*model_server.Asset, error)
+	) (*model_server.Asset, *model_server.LedgerTransaction, error)
+	// create an asset from cached assets of the user, picked by db id.
+	// A private ingredient is linked to the asset by generated secrets in
+	// both directions, other ingredients by public edges. The finalized
+	// ingredients are signed with the keys owning them. Return once
+	// endorsed, the asset and the ingredients are cached with their
+	// secrets when the ledger transaction is committed
+	ManufactureAsset(
+		ctx context.Context,
+		user *model_server.User,
+		materialName string,
+		unit string,
+		quantity decimal.Decimal,
+		ownerKeyId model_server.UserKeyPairId,
+		ingredientDbIds []model_server.NodeDbId,
+		isIngredientPrivate []bool,
+	) (*model_server.Asset, []model_server.Asset, *model_server.LedgerTransaction, error)
+	// finalize an asset owned by the user and create one child per
+	// quantity. childrenSecretIds hide the children in the asset and
+	// parentSecretIds hide the asset in each child, an empty secret makes a
+	// public edge. Return once endorsed, the asset and its children are
+	// cached with their secrets when the ledger transaction is committed
+	SplitAsset(
+		ctx context.Context,
+		user *model_server.User,
+		assetId model_server.NodeId,
+		quantities []decimal.Decimal,
+		childrenSecretIds []string,
+		parentSecretIds []string,
+	) (*model_server.Asset, []model_server.Asset, *model_server.LedgerTransaction, error)
 	GetAssetById(
 		ctx context.Context,
 		user *model_server.User,
@@ -28,16 +59,21 @@ type AssetControllerI interface {
 		useCache bool,
 	) (*model_server.Asset, error)
 
+	// a non empty unit converts the quantities of the assets whose unit has
+	// the same dimension, the other assets are returned as they are
 	GetOwnedAssetsFromCache(
 		ctx context.Context,
 		user *model_server.User,
 		isTransferred []bool,
 		pagination repository_server.PaginationOption[model_server.NodeDbId],
+		unit string,
 	) ([]model_server.Asset, error)
 
+	// same unit conversion as GetOwnedAssetsFromCache
 	GetAssetsFromCacheByDbId(
 		ctx context.Context,
 		user *model_server.User,
 		ids map[model_server.NodeDbId]bool,
+		unit string,
 	) ([]model_server.Asset, error)
 }
@@ -27,6 +27,7 @@ type assetTransferController struct {
 	peerRepository          repository_server.PeerRepositoryI
 	assetTransferRepository repository_server.AssetTransferRepositoryI
 	assetController         AssetControllerI
+	ledgerTransactions      LedgerTransactionControllerI
 	bus                     EventBus.Bus
 }
 
@@ -42,6 +43,7 @@ func NewAssetTransferController(
 	peerRepository repository_server.PeerRepositoryI,
 	assetController AssetControllerI,
 	assetTransferRepository repository_server.AssetTransferRepositoryI,
+	ledgerTransactions LedgerTransactionControllerI,
 ) *assetTransferController {
 	return &assetTransferController{
 		clock:                   clock,
@@ -55,6 +57,7 @@ func NewAssetTransferController(
 		assetTransferRepository: assetTransferRepository,
 		nodeController:          nodeController,
 		hashedIdGenerator:       hashedIdGenerator,
+		ledgerTransactions:      ledgerTransactions,
 	}
 }
 
@@ -192,6 +195,7 @@ func (c *assetTransferController) SubscribeNewAcceptAssetRequestReceivedEvent(
 func (c *assetTransferController) newAcceptAssetRequestReceivedHandler(
 	event model_asset_transfer.RequestToAcceptAssetEvent,
 ) {
+	// sent by a peer, chaincode calls are signed by the identity of the settings
 	ctx := context.Background()
 
 	txId, err := c.transactionManager.BypassTransaction(ctx)
@@ -240,9 +244,10 @@ func (c *assetTransferController) newAcceptAssetRequestReceivedHandler(
 	candidateIds := []model_server.CandidateId{}
 	for i := range event.Candidates {
 		candidateIds = append(candidateIds, model_server.CandidateId{
-			Id:        event.Candidates[i].Id,
-			Secret:    event.Candidates[i].Secret,
-			Signature: event.Candidates[i].Signature,
+			Id:              event.Candidates[i].Id,
+			Secret:          event.Candidates[i].Secret,
+			Signature:       event.Candidates[i].Signature,
+			SignatureScheme: event.Candidates[i].SignatureScheme,
 		})
 	}
 
@@ -370,10 +375,10 @@ func (c *assetTransferController) AcceptReceivedRequestsToAcceptAsset(
 	acceptOrRejct bool,
 	message string,
 	isNewConnectionSecretOrPublic bool,
-) (*model_server.RequestToAcceptAsset, error) {
+) (*model_server.RequestToAcceptAsset, *model_server.LedgerTransaction, error) {
 	txId, err := c.transactionManager.BypassTransaction(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
 
@@ -384,11 +389,11 @@ func (c *assetTransferController) AcceptReceivedRequestsToAcceptAsset(
 		requestId,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if request.IsOutboundOrInbound {
-		return nil, fmt.Errorf("%w: cannot accept outbound request", utility.ErrInvalidArgument)
+		return nil, nil, fmt.Errorf("%w: cannot accept outbound request", utility.ErrInvalidArgument)
 	}
 
 	namespace := fmt.Sprintf("%d", user.ID)
@@ -402,10 +407,10 @@ func (c *assetTransferController) AcceptReceivedRequestsToAcceptAsset(
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(asset) == 0 {
-		return nil, utility.ErrNotFound
+		return nil, nil, utility.ErrNotFound
 	}
 
 	sigGraphAsset := model_server.ToSigGraphAsset(&asset[0])
@@ -416,7 +421,7 @@ func (c *assetTransferController) AcceptReceivedRequestsToAcceptAsset(
 		request.PeerId,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	assetTransferPeer := model_server.ToAssetTransferPeer(peer)
 
@@ -429,10 +434,10 @@ func (c *assetTransferController) AcceptReceivedRequestsToAcceptAsset(
 		},
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(userKeys) == 0 {
-		return nil, utility.ErrNotFound
+		return nil, nil, utility.ErrNotFound
 	}
 	userKey := userKeys[0]
 
@@ -444,51 +449,96 @@ func (c *assetTransferController) AcceptReceivedRequestsToAcceptAsset(
 		request,
 	)
 
-	tempAssetTransferRequest, newSecret, oldSecret, err := c.transferApi.AcceptRequestToAcceptAsset(
+	if !acceptOrRejct {
+		err = c.transferApi.SendAcceptAsset(ctx, &assetTransferPeer, &assetTransferRequest, false, message)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = c.updateRequestStatus(ctx, request, false, message, txId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return request, nil, nil
+	}
+
+	tempAssetTransferRequest, newSecret, oldSecret, transaction, err := c.transferApi.TransferAcceptedAsset(
 		ctx,
-		&assetTransferPeer,
 		&assetTransferRequest,
-		acceptOrRejct,
-		message,
 		isNewConnectionSecretOrPublic,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	assetTransferRequest = *tempAssetTransferRequest
 
-	if acceptOrRejct {
-		// save new asset to repository
-		newAsset, updatedCurrentAsset, err := c.updateCurrentAssetAndNewAsset(
-			ctx,
-			user,
-			assetTransferRequest.NewAsset.Id,
-			newSecret,
-			assetTransferRequest.Asset.Id,
-			oldSecret,
-		)
-		if err != nil {
-			return nil, err
-		}
-		request.AssetId = updatedCurrentAsset.NodeDbId
-		request.NewAssetId = new(model_server.NodeDbId)
-		*request.NewAssetId = newAsset.NodeDbId
+	// the request stays pending until the transfer is committed
+	pendingRequest := *request
+	ledgerTransaction, err := c.ledgerTransactions.TrackLedgerTransaction(
+		ctx,
+		user,
+		"TransferAsset",
+		transaction,
+		func(ctx context.Context) error {
+			return c.completeAcceptedRequest(
+				ctx,
+				user,
+				&assetTransferPeer,
+				&assetTransferRequest,
+				pendingRequest,
+				message,
+				newSecret,
+				oldSecret,
+			)
+		},
+	)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// update request
-	if acceptOrRejct {
-		request.Status = model.ERequestToAcceptAssetStatusAccepted
-	} else {
-		request.Status = model.ERequestToAcceptAssetStatusRejected
+	return request, ledgerTransaction, nil
+}
+
+// inform the sender and cache the assets once the transfer is committed
+func (c *assetTransferController) completeAcceptedRequest(
+	ctx context.Context,
+	user *model_server.User,
+	peer *model_asset_transfer.Peer,
+	assetTransferRequest *model_asset_transfer.RequestToAcceptAsset,
+	request model_server.RequestToAcceptAsset,
+	message string,
+	newSecret string,
+	oldSecret string,
+) error {
+	err := c.transferApi.SendAcceptAsset(ctx, peer, assetTransferRequest, true, message)
+	if err != nil {
+		return err
 	}
-	request.AcceptMessage = message
 
-	err = c.assetTransferRepository.UpdateAssetAcceptRequest(ctx, txId, request)
+	// save new asset to repository
+	newAsset, updatedCurrentAsset, err := c.updateCurrentAssetAndNewAsset(
+		ctx,
+		user,
+		assetTransferRequest.NewAsset.Id,
+		newSecret,
+		assetTransferRequest.Asset.Id,
+		oldSecret,
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	request.AssetId = updatedCurrentAsset.NodeDbId
+	request.NewAssetId = new(model_server.NodeDbId)
+	*request.NewAssetId = newAsset.NodeDbId
 
-	return request, nil
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	return c.updateRequestStatus(ctx, &request, true, message, txId)
 }
 
 func (c *assetTransferController) SubscribeNewAssetAcceptReceivedEvent(
@@ -502,6 +552,7 @@ func (c *assetTransferController) SubscribeNewAssetAcceptReceivedEvent(
 func (c *assetTransferController) newAcceptAssetReceivedHandler(
 	event model_asset_transfer.AcceptAssetEvent,
 ) {
+	// sent by a peer, chaincode calls are signed by the identity of the settings
 	ctx := context.Background()
 
 	txId, err := c.transactionManager.BypassTransaction(ctx)
@@ -536,6 +587,25 @@ func (c *assetTransferController) newAcceptAssetReceivedHandler(
 	c.updateRequestStatus(ctx, request, event.IsAccepted, event.Message, txId)
 }
 
+func (c *assetTransferController) GetPeerPublicKeyByAckId(ctx context.Context, ackId string) (string, error) {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	request, err := c.assetTransferRepository.FetchAssetAcceptRequestsByAckId(ctx, txId, ackId, true)
+	if err != nil {
+		return "", err
+	}
+
+	peer, err := c.peerRepository.FetchPeerById(ctx, txId, request.PeerId)
+	if err != nil {
+		return "", err
+	}
+	return peer.PeerPemPublicKey, nil
+}
+
 func (c *assetTransferController) updateRequestStatus(
 	ctx context.Context,
 	request *model_server.RequestToAcceptAsset,
@@ -566,6 +636,7 @@ func (c *assetTransferController) syncRequestWithCurrentAndNewAsset(
 		ctx,
 		user,
 		map[model_server.NodeDbId]bool{request.AssetId: true},
+		"",
 	)
 	if err != nil {
 		return err
@@ -25,6 +25,9 @@ type AssetTransferControllerI interface {
 		pagination repository_server.PaginationOption[model_server.RequestId],
 	) ([]model_server.RequestToAcceptAsset, error)
 
+	// a rejected request is updated right away. An accepted request stays
+	// pending until the transfer transaction is committed, the ledger
+	// transaction is nil when rejecting
 	AcceptReceivedRequestsToAcceptAsset(
 		ctx context.Context,
 		user *model_server.User,
@@ -33,7 +36,7 @@ type AssetTransferControllerI interface {
 		acceptOrRejct bool,
 		message string,
 		isNewConnectionSecretOrPublic bool,
-	) (*model_server.RequestToAcceptAsset, error)
+	) (*model_server.RequestToAcceptAsset, *model_server.LedgerTransaction, error)
 
 	FetchPrivateEdges(
 		ctx context.Context,
@@ -41,6 +44,9 @@ type AssetTransferControllerI interface {
 		requestId model_server.RequestId,
 	) ([]model_server.Node, error)
 
+	// see api_asset_transfer.OutboundRequestPeerResolverI
+	GetPeerPublicKeyByAckId(ctx context.Context, ackId string) (string, error)
+
 	/*
 
 		GetSentRequestsToAcceptAsset(
@@ -0,0 +1,125 @@
+package controller_server
+
+import (
+	"context"
+	"fmt"
+	model_server "sig_graph_scp/pkg/server/model"
+	repository_server "sig_graph_scp/pkg/server/repository"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	"sig_graph_scp/pkg/utility"
+)
+
+type fabricIdentityController struct {
+	clock              utility.ClockI
+	repository         repository_server.FabricIdentityRepositoryI
+	transactionManager repository_server.TransactionManagerI
+	// nil if identities can only be added
+	enroller api_sig_graph.FabricCaEnrollerI
+}
+
+func NewFabricIdentityController(
+	clock utility.ClockI,
+	repository repository_server.FabricIdentityRepositoryI,
+	transactionManager repository_server.TransactionManagerI,
+	enroller api_sig_graph.FabricCaEnrollerI,
+) FabricIdentityControllerI {
+	return &fabricIdentityController{
+		clock:              clock,
+		repository:         repository,
+		transactionManager: transactionManager,
+		enroller:           enroller,
+	}
+}
+
+func (c *fabricIdentityController) AddFabricIdentity(
+	ctx context.Context,
+	user *model_server.User,
+	fabricIdentity *model_server.FabricIdentity,
+) error {
+	if fabricIdentity.Name == "" {
+		return fmt.Errorf("%w: missing identity name", utility.ErrInvalidArgument)
+	}
+
+	sigGraphIdentity := model_server.ToSigGraphFabricIdentity(fabricIdentity)
+	err := api_sig_graph.ValidateFabricIdentity(&sigGraphIdentity)
+	if err != nil {
+		return err
+	}
+
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	fabricIdentity.UserId = user.ID
+	fabricIdentity.CreatedTime = uint64(c.clock.Now().UnixMilli())
+	return c.repository.CreateFabricIdentity(ctx, txId, fabricIdentity)
+}
+
+func (c *fabricIdentityController) EnrollFabricIdentity(
+	ctx context.Context,
+	user *model_server.User,
+	name string,
+	enrollmentId string,
+	enrollmentSecret string,
+) (*model_server.FabricIdentity, error) {
+	if c.enroller == nil {
+		return nil, fmt.Errorf("%w: no fabric ca configured", utility.ErrInvalidState)
+	}
+
+	enrolled, err := c.enroller.Enroll(ctx, enrollmentId, enrollmentSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	fabricIdentity := &model_server.FabricIdentity{
+		Name:        name,
+		MspId:       enrolled.MspId,
+		Certificate: enrolled.Certificate,
+		PrivateKey:  enrolled.PrivateKey,
+	}
+	err = c.AddFabricIdentity(ctx, user, fabricIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return fabricIdentity, nil
+}
+
+func (c *fabricIdentityController) FetchFabricIdentitiesOfUser(
+	ctx context.Context,
+	user *model_server.User,
+) ([]model_server.FabricIdentity, error) {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	return c.repository.FetchFabricIdentitiesOfUser(ctx, txId, user)
+}
+
+func (c *fabricIdentityController) SelectFabricIdentity(
+	ctx context.Context,
+	user *model_server.User,
+	name string,
+) (*model_server.FabricIdentity, error) {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	if name != "" {
+		return c.repository.FetchFabricIdentityOfUserByName(ctx, txId, user, name)
+	}
+
+	fabricIdentities, err := c.repository.FetchFabricIdentitiesOfUser(ctx, txId, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(fabricIdentities) == 0 {
+		return nil, nil
+	}
+	return &fabricIdentities[0], nil
+}
@@ -0,0 +1,27 @@
+package controller_server
+
+import (
+	"context"
+	model_server "sig_graph_scp/pkg/server/model"
+)
+
+type FabricIdentityControllerI interface {
+	// store an identity enrolled elsewhere. Return ErrInvalidArgument if the
+	// key is not the key of the certificate
+	AddFabricIdentity(ctx context.Context, user *model_server.User, fabricIdentity *model_server.FabricIdentity) error
+	// enroll an identity registered with the Fabric CA and store it under name.
+	// Return ErrInvalidState if no CA is configured
+	EnrollFabricIdentity(
+		ctx context.Context,
+		user *model_server.User,
+		name string,
+		enrollmentId string,
+		enrollmentSecret string,
+	) (*model_server.FabricIdentity, error)
+	FetchFabricIdentitiesOfUser(ctx context.Context, user *model_server.User) ([]model_server.FabricIdentity, error)
+	// identity signing the chaincode calls of a request of user. The one
+	// named name, or the oldest identity of the user if name is empty.
+	// nil if the user has no identity, calls are then signed by the
+	// identity of the settings
+	SelectFabricIdentity(ctx context.Context, user *model_server.User, name string) (*model_server.FabricIdentity, error)
+}
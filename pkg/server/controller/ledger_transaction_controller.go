@@ -0,0 +1,281 @@
+package controller_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	repository_server "sig_graph_scp/pkg/server/repository"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	"sig_graph_scp/pkg/utility"
+	"time"
+)
+
+const (
+	// how long to wait for a commit before the transaction is marked as timed out
+	ledgerTransactionCommitTimeout = 5 * time.Minute
+	// how often the ledger is checked for a timed out transaction, and for
+	// how long after its creation
+	ledgerTransactionPollInterval = 30 * time.Second
+	ledgerTransactionPollTimeout  = 24 * time.Hour
+	// attempts to save the state of a transaction
+	ledgerTransactionSaveAttempts = 5
+	ledgerTransactionSaveBackoff  = time.Second
+)
+
+// the follow-up of a transaction submitted before a restart is not known
+var errFollowUpLost = errors.New("the follow-up of the commit was lost with a restart of the server")
+
+type ledgerTransactionController struct {
+	// of the server, the transactions are watched until it is done. It
+	// carries no Fabric identity, follow-ups are signed by the one of the
+	// settings
+	ctx                context.Context
+	clock              utility.ClockI
+	repository         repository_server.LedgerTransactionRepositoryI
+	transactionManager repository_server.TransactionManagerI
+	sigGraphApi        api_sig_graph.SigGraphClientApi
+}
+
+// the transactions are watched in the background until ctx is done, those
+// still pending are resumed by ResumeLedgerTransactions after a restart
+func NewLedgerTransactionController(
+	ctx context.Context,
+	clock utility.ClockI,
+	repository repository_server.LedgerTransactionRepositoryI,
+	transactionManager repository_server.TransactionManagerI,
+	sigGraphApi api_sig_graph.SigGraphClientApi,
+) *ledgerTransactionController {
+	return &ledgerTransactionController{
+		ctx:                ctx,
+		clock:              clock,
+		repository:         repository,
+		transactionManager: transactionManager,
+		sigGraphApi:        sigGraphApi,
+	}
+}
+
+// false once ctx is done
+func sleepWithContext(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (c *ledgerTransactionController) TrackLedgerTransaction(
+	ctx context.Context,
+	user *model_server.User,
+	functionName string,
+	transaction api_sig_graph.LedgerTransactionI,
+	onCommitted func(ctx context.Context) error,
+) (*model_server.LedgerTransaction, error) {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	now := uint64(c.clock.Now().UnixMilli())
+	ledgerTransaction := model_server.LedgerTransaction{
+		TransactionId: transaction.Id(),
+		UserId:        user.ID,
+		FunctionName:  functionName,
+		Status:        model.ELedgerTransactionStatusSubmitting,
+		CreatedTime:   now,
+		UpdatedTime:   now,
+	}
+	err = c.repository.CreateLedgerTransaction(ctx, txId, &ledgerTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// the request context ends with the request, the commit comes later
+	go c.waitForCommit(ledgerTransaction, transaction, onCommitted)
+
+	return &ledgerTransaction, nil
+}
+
+func (c *ledgerTransactionController) ResumeLedgerTransactions(ctx context.Context) error {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	ledgerTransactions, err := c.repository.FetchLedgerTransactionsByStatuses(ctx, txId, map[model.ELedgerTransactionStatus]bool{
+		model.ELedgerTransactionStatusSubmitting: true,
+		model.ELedgerTransactionStatusTimedOut:   true,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ledgerTransaction := range ledgerTransactions {
+		go c.pollCommitStatus(ledgerTransaction, func(ctx context.Context) error {
+			return errFollowUpLost
+		})
+	}
+	return nil
+}
+
+func (c *ledgerTransactionController) waitForCommit(
+	ledgerTransaction model_server.LedgerTransaction,
+	transaction api_sig_graph.LedgerTransactionI,
+	onCommitted func(ctx context.Context) error,
+) {
+	ctx := c.ctx
+
+	commitCtx, cancel := context.WithTimeout(ctx, ledgerTransactionCommitTimeout)
+	err := transaction.WaitForCommit(commitCtx)
+	isTimedOut := errors.Is(commitCtx.Err(), context.DeadlineExceeded)
+	cancel()
+	if ctx.Err() != nil {
+		// left submitting, resumed after a restart
+		return
+	}
+
+	// the transaction is resubmitted under a new id on read conflicts
+	if transactionId := transaction.Id(); transactionId != "" {
+		ledgerTransaction.TransactionId = transactionId
+	}
+
+	switch {
+	case err == nil:
+		c.followUpCommit(ctx, ledgerTransaction, onCommitted)
+	case isTimedOut:
+		ledgerTransaction.Status = model.ELedgerTransactionStatusTimedOut
+		ledgerTransaction.Message = fmt.Sprintf("not committed within %s: %s", ledgerTransactionCommitTimeout, err.Error())
+		c.saveLedgerTransaction(ctx, &ledgerTransaction)
+		c.pollCommitStatus(ledgerTransaction, onCommitted)
+	default:
+		ledgerTransaction.Status = model.ELedgerTransactionStatusFailed
+		ledgerTransaction.Message = err.Error()
+		c.saveLedgerTransaction(ctx, &ledgerTransaction)
+	}
+}
+
+// check the ledger by transaction id until the transaction is found or
+// ledgerTransactionPollTimeout after its creation
+func (c *ledgerTransactionController) pollCommitStatus(
+	ledgerTransaction model_server.LedgerTransaction,
+	onCommitted func(ctx context.Context) error,
+) {
+	ctx := c.ctx
+	ticker := time.NewTicker(ledgerTransactionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := c.sigGraphApi.GetLedgerTransactionStatus(ctx, ledgerTransaction.TransactionId)
+		switch {
+		case err == nil:
+			c.followUpCommit(ctx, ledgerTransaction, onCommitted)
+			return
+		case errors.Is(err, utility.ErrTransactionFailed):
+			ledgerTransaction.Status = model.ELedgerTransactionStatusFailed
+			ledgerTransaction.Message = err.Error()
+			c.saveLedgerTransaction(ctx, &ledgerTransaction)
+			return
+		case !errors.Is(err, utility.ErrNotFound):
+			log.Printf("could not check ledger transaction %s: %s", ledgerTransaction.TransactionId, err)
+		}
+
+		age := c.clock.Now().Sub(time.UnixMilli(int64(ledgerTransaction.CreatedTime)))
+		if age >= ledgerTransactionPollTimeout {
+			ledgerTransaction.Status = model.ELedgerTransactionStatusTimedOut
+			ledgerTransaction.Message = fmt.Sprintf("not found on the ledger within %s", ledgerTransactionPollTimeout)
+			c.saveLedgerTransaction(ctx, &ledgerTransaction)
+			return
+		}
+		if ledgerTransaction.Status == model.ELedgerTransactionStatusSubmitting && age >= ledgerTransactionCommitTimeout {
+			ledgerTransaction.Status = model.ELedgerTransactionStatusTimedOut
+			ledgerTransaction.Message = fmt.Sprintf("not committed within %s", ledgerTransactionCommitTimeout)
+			c.saveLedgerTransaction(ctx, &ledgerTransaction)
+		}
+
+		// left as it is, resumed after a restart
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run onCommitted, its failure is kept in the status. It is not retried,
+// a part of it may have been done
+func (c *ledgerTransactionController) followUpCommit(
+	ctx context.Context,
+	ledgerTransaction model_server.LedgerTransaction,
+	onCommitted func(ctx context.Context) error,
+) {
+	ledgerTransaction.Status = model.ELedgerTransactionStatusCommitted
+	ledgerTransaction.Message = ""
+	if onCommitted != nil {
+		err := onCommitted(ctx)
+		if err != nil {
+			log.Printf("follow-up of ledger transaction %s failed: %s", ledgerTransaction.TransactionId, err)
+			ledgerTransaction.Status = model.ELedgerTransactionStatusFollowUpFailed
+			ledgerTransaction.Message = err.Error()
+		}
+	}
+	c.saveLedgerTransaction(ctx, &ledgerTransaction)
+}
+
+// nothing else records the state, retry until it is saved
+func (c *ledgerTransactionController) saveLedgerTransaction(
+	ctx context.Context,
+	ledgerTransaction *model_server.LedgerTransaction,
+) {
+	ledgerTransaction.UpdatedTime = uint64(c.clock.Now().UnixMilli())
+
+	for attempt := 1; ; attempt++ {
+		err := c.updateLedgerTransaction(ctx, ledgerTransaction)
+		if err == nil {
+			return
+		}
+		if attempt >= ledgerTransactionSaveAttempts {
+			log.Printf("could not save ledger transaction %d as %s, giving up: %s", ledgerTransaction.Id, ledgerTransaction.Status, err)
+			return
+		}
+		log.Printf("could not save ledger transaction %d as %s, retrying: %s", ledgerTransaction.Id, ledgerTransaction.Status, err)
+		if !sleepWithContext(ctx, time.Duration(attempt)*ledgerTransactionSaveBackoff) {
+			log.Printf("could not save ledger transaction %d as %s, giving up on shutdown: %s", ledgerTransaction.Id, ledgerTransaction.Status, err)
+			return
+		}
+	}
+}
+
+func (c *ledgerTransactionController) updateLedgerTransaction(
+	ctx context.Context,
+	ledgerTransaction *model_server.LedgerTransaction,
+) error {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	return c.repository.UpdateLedgerTransaction(ctx, txId, ledgerTransaction)
+}
+
+func (c *ledgerTransactionController) GetLedgerTransactionsByIds(
+	ctx context.Context,
+	user *model_server.User,
+	ids map[model_server.LedgerTransactionDbId]bool,
+) ([]model_server.LedgerTransaction, error) {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	return c.repository.FetchLedgerTransactionsByIds(ctx, txId, user, ids)
+}
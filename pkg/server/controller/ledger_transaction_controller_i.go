@@ -0,0 +1,34 @@
+package controller_server
+
+import (
+	"context"
+	model_server "sig_graph_scp/pkg/server/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+)
+
+type LedgerTransactionControllerI interface {
+	// record transaction as submitting and wait for its commit in the
+	// background. onCommitted runs once the transaction is committed,
+	// before the record is marked as committed. If onCommitted fails the
+	// record is marked as follow_up_failed with the error as its message.
+	// A transaction not committed within the commit timeout is marked as
+	// timed_out and checked on the ledger by id until it is found
+	TrackLedgerTransaction(
+		ctx context.Context,
+		user *model_server.User,
+		functionName string,
+		transaction api_sig_graph.LedgerTransactionI,
+		onCommitted func(ctx context.Context) error,
+	) (*model_server.LedgerTransaction, error)
+
+	// check the ledger for the transactions left submitting or timed_out,
+	// e.g. by a restart, in the background. Their onCommitted is lost, they
+	// are marked as follow_up_failed once committed
+	ResumeLedgerTransactions(ctx context.Context) error
+
+	GetLedgerTransactionsByIds(
+		ctx context.Context,
+		user *model_server.User,
+		ids map[model_server.LedgerTransactionDbId]bool,
+	) ([]model_server.LedgerTransaction, error)
+}
@@ -2,9 +2,19 @@ package controller_server
 
 import (
 	"context"
+	"log"
 	model_server "sig_graph_scp/pkg/server/model"
 	repository_server "sig_graph_scp/pkg/server/repository"
 	service_server "sig_graph_scp/pkg/server/service"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"time"
+)
+
+const (
+	// attempts to update the cache with a node event
+	nodeEventUpdateAttempts = 5
+	nodeEventUpdateBackoff  = time.Second
 )
 
 type nodeController struct {
@@ -51,3 +61,114 @@ func (c *nodeController) FetchPrivateEdges(
 
 	return c.nodeService.FetchPrivateEdges(ctx, txId, user, exposedPrivateConnections, endNode, useCache)
 }
+
+func (c *nodeController) TraceNode(
+	ctx context.Context,
+	user *model_server.User,
+	id model_server.NodeId,
+	direction model_server.ETraceDirection,
+	maxDepth int,
+	useCache bool,
+) (*model_server.Trace, error) {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	return c.nodeService.TraceNode(ctx, txId, user, id, direction, maxDepth, useCache)
+}
+
+func (c *nodeController) BuildProofBundle(
+	ctx context.Context,
+	user *model_server.User,
+	id model_server.NodeId,
+	direction model_server.ETraceDirection,
+	maxDepth int,
+) (*model_sig_graph.ProofBundle, error) {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	return c.nodeService.BuildProofBundle(ctx, txId, user, id, direction, maxDepth)
+}
+
+func (c *nodeController) SubscribeNodeEvents(
+	ctx context.Context,
+	source api_sig_graph.NodeEventSourceI,
+) error {
+	events, err := source.SubscribeNodeEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for event := range events {
+			c.nodeEventHandler(ctx, event)
+		}
+	}()
+
+	return nil
+}
+
+// a failed update is retried in the background so that it does not hold
+// back the next events. A cached node stays outdated until it is fetched
+// again if every attempt fails
+func (c *nodeController) nodeEventHandler(
+	ctx context.Context,
+	event model_sig_graph.NodeEvent,
+) {
+	err := c.updateCachedNode(ctx, model_server.NodeId(event.NodeId))
+	if err == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		log.Printf("could not update cached node %s on %s event, giving up: %s", event.NodeId, event.EventType, err)
+		return
+	}
+	log.Printf("could not update cached node %s on %s event, retrying: %s", event.NodeId, event.EventType, err)
+	go c.retryNodeEvent(ctx, event)
+}
+
+func (c *nodeController) retryNodeEvent(
+	ctx context.Context,
+	event model_sig_graph.NodeEvent,
+) {
+	for attempt := 2; ; attempt++ {
+		timer := time.NewTimer(time.Duration(attempt-1) * nodeEventUpdateBackoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("could not update cached node %s on %s event, giving up: %s", event.NodeId, event.EventType, ctx.Err())
+			return
+		case <-timer.C:
+		}
+
+		err := c.updateCachedNode(ctx, model_server.NodeId(event.NodeId))
+		if err == nil {
+			return
+		}
+		if attempt >= nodeEventUpdateAttempts || ctx.Err() != nil {
+			log.Printf("could not update cached node %s on %s event, giving up: %s", event.NodeId, event.EventType, err)
+			return
+		}
+		log.Printf("could not update cached node %s on %s event, retrying: %s", event.NodeId, event.EventType, err)
+	}
+}
+
+func (c *nodeController) updateCachedNode(
+	ctx context.Context,
+	id model_server.NodeId,
+) error {
+	txId, err := c.transactionManager.BypassTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.transactionManager.StopBypassedTransaction(ctx, txId)
+
+	return c.nodeService.UpdateCachedNodes(ctx, txId, map[model_server.NodeId]bool{
+		id: true,
+	})
+}
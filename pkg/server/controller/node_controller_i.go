@@ -3,6 +3,8 @@ package controller_server
 import (
 	"context"
 	model_server "sig_graph_scp/pkg/server/model"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 )
 
 type NodeControllerI interface {
@@ -19,4 +21,29 @@ type NodeControllerI interface {
 		endNode *model_server.Node,
 		useCache bool,
 	) (relatedNodes []model_server.Node, err error)
+
+	// provenance graph of a node, see NodeServiceI.TraceNode
+	TraceNode(
+		ctx context.Context,
+		user *model_server.User,
+		id model_server.NodeId,
+		direction model_server.ETraceDirection,
+		maxDepth int,
+		useCache bool,
+	) (*model_server.Trace, error)
+
+	// see NodeServiceI.BuildProofBundle
+	BuildProofBundle(
+		ctx context.Context,
+		user *model_server.User,
+		id model_server.NodeId,
+		direction model_server.ETraceDirection,
+		maxDepth int,
+	) (*model_sig_graph.ProofBundle, error)
+
+	// keep the node cache in sync with the ledger until ctx is done
+	SubscribeNodeEvents(
+		ctx context.Context,
+		source api_sig_graph.NodeEventSourceI,
+	) error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	model_server "sig_graph_scp/pkg/server/model"
 	repository_server "sig_graph_scp/pkg/server/repository"
+	"sig_graph_scp/pkg/utility"
 )
 
 type userKeyPairController struct {
@@ -37,6 +38,11 @@ func (c *userKeyPairController) FetchKeyPairsByUser(ctx context.Context, user *m
 }
 
 func (c *userKeyPairController) AddKeyPairToUser(ctx context.Context, user *model_server.User, keyPair *model_server.UserKeyPair) error {
+	_, err := utility.ValidatePemKeyPair(keyPair.Public, keyPair.Private)
+	if err != nil {
+		return err
+	}
+
 	tx, err := c.transactionManager.BypassTransaction(ctx)
 	if err != nil {
 		return err
@@ -0,0 +1,112 @@
+package exporter_server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ids of the exported nodes, parties and events
+const uriPrefix = "urn:sgp:"
+
+func nodeUri(id model_server.NodeId) string {
+	return uriPrefix + "node:" + string(id)
+}
+
+func eventUri(id model_server.NodeId) string {
+	return uriPrefix + "event:" + string(id)
+}
+
+// sha256 hex digest, identifies owners by their public key
+func fingerprint(value string) string {
+	digest := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(digest[:])
+}
+
+func partyUri(publicKey string) string {
+	return uriPrefix + "party:" + fingerprint(publicKey)
+}
+
+func timeFromMs(ms uint64) time.Time {
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+// node of a trace with its asset fields, if it is an asset
+type traceNode struct {
+	model_server.Node
+	// empty for other node types
+	CreationProcess model.ECreationProcess
+	MaterialName    string
+	Unit            string
+	Quantity        decimal.Decimal
+	IsAsset         bool
+}
+
+// parents of a node within the trace
+type traceParents struct {
+	// parents reached by a public or a private edge
+	Ids []model_server.NodeId
+	// hashes of the parents hidden by an opaque edge
+	OpaqueHashes []string
+}
+
+type traceIndex struct {
+	// sorted by creation time then id
+	Nodes     []traceNode
+	NodesById map[model_server.NodeId]traceNode
+	Parents   map[model_server.NodeId]*traceParents
+}
+
+func indexTrace(trace *model_server.Trace) traceIndex {
+	nodes := make([]traceNode, 0, len(trace.Nodes))
+	nodesById := map[model_server.NodeId]traceNode{}
+	for id := range trace.Nodes {
+		node := traceNode{Node: trace.Nodes[id]}
+		if asset, ok := node.Extra.(*model_server.Asset); ok {
+			node.CreationProcess = asset.CreationProcess
+			node.MaterialName = asset.MaterialName
+			node.Unit = asset.Unit
+			node.Quantity = asset.Quantity
+			node.IsAsset = true
+		}
+		nodes = append(nodes, node)
+		nodesById[id] = node
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].CreatedTime != nodes[j].CreatedTime {
+			return nodes[i].CreatedTime < nodes[j].CreatedTime
+		}
+		return nodes[i].Id < nodes[j].Id
+	})
+
+	parents := map[model_server.NodeId]*traceParents{}
+	for _, node := range nodes {
+		parents[node.Id] = &traceParents{}
+	}
+	for _, edge := range trace.Edges {
+		childParents, ok := parents[edge.ChildId]
+		if !ok {
+			continue
+		}
+		if edge.EdgeType == model_server.ETraceEdgeTypeOpaque {
+			childParents.OpaqueHashes = append(childParents.OpaqueHashes, edge.Hash)
+		} else {
+			childParents.Ids = append(childParents.Ids, edge.ParentId)
+		}
+	}
+	for id := range parents {
+		sort.Slice(parents[id].Ids, func(i, j int) bool { return parents[id].Ids[i] < parents[id].Ids[j] })
+		sort.Strings(parents[id].OpaqueHashes)
+	}
+
+	return traceIndex{
+		Nodes:     nodes,
+		NodesById: nodesById,
+		Parents:   parents,
+	}
+}
@@ -0,0 +1,103 @@
+package exporter_server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	model_server "sig_graph_scp/pkg/server/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// edge styles of each edge type
+var dotEdgeStyles = map[model_server.ETraceEdgeType]string{
+	model_server.ETraceEdgeTypePublic:  "solid",
+	model_server.ETraceEdgeTypePrivate: "dashed",
+	model_server.ETraceEdgeTypeOpaque:  "dotted",
+}
+
+// Graphviz digraph from the parents to the children. The root is bold and
+// the unknown ends of opaque edges are drawn as points
+type traceExporterDot struct {
+}
+
+func NewTraceExporterDot() *traceExporterDot {
+	return &traceExporterDot{}
+}
+
+func (e *traceExporterDot) Format() string {
+	return "dot"
+}
+
+func (e *traceExporterDot) ContentType() string {
+	return "text/vnd.graphviz"
+}
+
+func (e *traceExporterDot) Export(ctx context.Context, trace *model_server.Trace) ([]byte, error) {
+	index := indexTrace(trace)
+
+	buffer := bytes.Buffer{}
+	buffer.WriteString("digraph provenance {\n")
+	buffer.WriteString("  rankdir=LR;\n")
+	buffer.WriteString("  node [shape=box];\n")
+
+	for _, node := range index.Nodes {
+		lines := []string{string(node.Id)}
+		if node.IsAsset {
+			lines = append(lines,
+				node.MaterialName,
+				fmt.Sprintf("%s %s", node.Quantity.String(), node.Unit),
+				node.CreationProcess,
+			)
+		}
+		lines = append(lines,
+			"owner "+fingerprint(node.OwnerPublicKey)[:16],
+			timeFromMs(node.CreatedTime).Format(time.RFC3339),
+		)
+
+		attributes := fmt.Sprintf("label=%s", dotQuote(strings.Join(lines, "\n")))
+		if node.Id == trace.RootId {
+			attributes += ", style=bold"
+		}
+		if node.IsFinalized {
+			attributes += ", color=gray"
+		}
+		fmt.Fprintf(&buffer, "  %s [%s];\n", dotQuote(string(node.Id)), attributes)
+	}
+
+	edges := append([]model_server.TraceEdge{}, trace.Edges...)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].ParentId != edges[j].ParentId {
+			return edges[i].ParentId < edges[j].ParentId
+		}
+		if edges[i].ChildId != edges[j].ChildId {
+			return edges[i].ChildId < edges[j].ChildId
+		}
+		return edges[i].Hash < edges[j].Hash
+	})
+	for _, edge := range edges {
+		parentId, childId := string(edge.ParentId), string(edge.ChildId)
+		if edge.EdgeType == model_server.ETraceEdgeTypeOpaque {
+			hiddenId := "hidden:" + edge.Hash
+			fmt.Fprintf(&buffer, "  %s [shape=point, tooltip=%s];\n", dotQuote(hiddenId), dotQuote(edge.Hash))
+			if parentId == "" {
+				parentId = hiddenId
+			} else {
+				childId = hiddenId
+			}
+		}
+		fmt.Fprintf(&buffer, "  %s -> %s [style=%s];\n", dotQuote(parentId), dotQuote(childId), dotEdgeStyles[edge.EdgeType])
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.Bytes(), nil
+}
+
+// double quoted DOT id, new lines become centered line breaks
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
@@ -0,0 +1,217 @@
+package exporter_server
+
+import (
+	"context"
+	"encoding/json"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	"sig_graph_scp/pkg/utility"
+	"strings"
+	"time"
+)
+
+const epcisContext = "https://ref.gs1.org/standards/epcis/2.0.0/epcis-context.jsonld"
+
+// UN/CEFACT rec 20 codes of the default units, by lower case symbol
+var epcisUnitCodes = map[string]string{
+	"mg":    "MGM",
+	"g":     "GRM",
+	"kg":    "KGM",
+	"t":     "TNE",
+	"oz":    "ONZ",
+	"lb":    "LBR",
+	"ml":    "MLT",
+	"cl":    "CLT",
+	"l":     "LTR",
+	"m3":    "MTQ",
+	"gal":   "GLL",
+	"pcs":   "H87",
+	"dozen": "DZN",
+}
+
+// CBV business step of each creation process
+var epcisBizSteps = map[model.ECreationProcess]string{
+	model.ECreationProcessCreate:   "creating_class_instance",
+	model.ECreationProcessTransfer: "receiving",
+	model.ECreationProcessSplit:    "repackaging",
+}
+
+type epcisQuantityElement struct {
+	EpcClass string      `json:"epcClass"`
+	Quantity json.Number `json:"quantity,omitempty"`
+	Uom      string      `json:"uom,omitempty"`
+
+	// extensions, the quantity of a unit without UN/CEFACT code. A quantity
+	// without uom would be read as a number of instances
+	UnknownUnitQuantity json.Number `json:"sgp:quantity,omitempty"`
+	UnknownUnit         string      `json:"sgp:unit,omitempty"`
+}
+
+type epcisSource struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+}
+
+type epcisDestination struct {
+	Type        string `json:"type"`
+	Destination string `json:"destination"`
+}
+
+type epcisEvent struct {
+	Type                string `json:"type"`
+	EventId             string `json:"eventID"`
+	EventTime           string `json:"eventTime"`
+	EventTimeZoneOffset string `json:"eventTimeZoneOffset"`
+	// ObjectEvent only
+	Action             string                 `json:"action,omitempty"`
+	BizStep            string                 `json:"bizStep,omitempty"`
+	QuantityList       []epcisQuantityElement `json:"quantityList,omitempty"`
+	InputQuantityList  []epcisQuantityElement `json:"inputQuantityList,omitempty"`
+	OutputQuantityList []epcisQuantityElement `json:"outputQuantityList,omitempty"`
+	SourceList         []epcisSource          `json:"sourceList,omitempty"`
+	DestinationList    []epcisDestination     `json:"destinationList,omitempty"`
+
+	// extensions
+	MaterialName string `json:"sgp:materialName,omitempty"`
+	Owner        string `json:"sgp:owner,omitempty"`
+	// hashes of the inputs hidden by private edges
+	HiddenInputs []string `json:"sgp:hiddenInputs,omitempty"`
+	// classes a transferred node was received as, ObjectEvent only
+	TransferredFrom []string `json:"sgp:transferredFrom,omitempty"`
+}
+
+type epcisBody struct {
+	EventList []epcisEvent `json:"eventList"`
+}
+
+type epcisDocument struct {
+	Context       []any     `json:"@context"`
+	Type          string    `json:"type"`
+	SchemaVersion string    `json:"schemaVersion"`
+	CreationDate  string    `json:"creationDate"`
+	EpcisBody     epcisBody `json:"epcisBody"`
+}
+
+// one event per node: an ObjectEvent adding the nodes without parents, an
+// ObjectEvent observing the transferred nodes received from their parents and
+// a TransformationEvent from the parents for the others. Each node is its own
+// EPC class, identified by urn:sgp:node:<id>
+type traceExporterEpcis struct {
+	clock utility.ClockI
+}
+
+func NewTraceExporterEpcis(clock utility.ClockI) *traceExporterEpcis {
+	return &traceExporterEpcis{
+		clock: clock,
+	}
+}
+
+func (e *traceExporterEpcis) Format() string {
+	return "epcis"
+}
+
+func (e *traceExporterEpcis) ContentType() string {
+	return "application/ld+json"
+}
+
+func (e *traceExporterEpcis) Export(ctx context.Context, trace *model_server.Trace) ([]byte, error) {
+	index := indexTrace(trace)
+
+	events := make([]epcisEvent, 0, len(index.Nodes))
+	for _, node := range index.Nodes {
+		parents := index.Parents[node.Id]
+		event := epcisEvent{
+			EventId:             eventUri(node.Id),
+			EventTime:           timeFromMs(node.CreatedTime).Format(time.RFC3339Nano),
+			EventTimeZoneOffset: "+00:00",
+			BizStep:             epcisBizSteps[node.CreationProcess],
+			MaterialName:        node.MaterialName,
+			Owner:               partyUri(node.OwnerPublicKey),
+			HiddenInputs:        parents.OpaqueHashes,
+		}
+
+		if len(parents.Ids) == 0 && len(parents.OpaqueHashes) == 0 {
+			event.Type = "ObjectEvent"
+			event.Action = "ADD"
+			event.QuantityList = []epcisQuantityElement{epcisQuantity(&node, &node)}
+			events = append(events, event)
+			continue
+		}
+
+		// the goods do not change hands through a transformation
+		if node.CreationProcess == model.ECreationProcessTransfer {
+			event.Type = "ObjectEvent"
+			event.Action = "OBSERVE"
+			event.QuantityList = []epcisQuantityElement{epcisQuantity(&node, &node)}
+			for _, parentId := range parents.Ids {
+				event.TransferredFrom = append(event.TransferredFrom, nodeUri(parentId))
+				parent, ok := index.NodesById[parentId]
+				if !ok {
+					continue
+				}
+				event.SourceList = append(event.SourceList, epcisSource{
+					Type:   "owning_party",
+					Source: partyUri(parent.OwnerPublicKey),
+				})
+				event.DestinationList = append(event.DestinationList, epcisDestination{
+					Type:        "owning_party",
+					Destination: partyUri(node.OwnerPublicKey),
+				})
+			}
+			events = append(events, event)
+			continue
+		}
+
+		event.Type = "TransformationEvent"
+		event.OutputQuantityList = []epcisQuantityElement{epcisQuantity(&node, &node)}
+		for _, parentId := range parents.Ids {
+			parent, ok := index.NodesById[parentId]
+			if !ok {
+				event.InputQuantityList = append(event.InputQuantityList, epcisQuantityElement{EpcClass: nodeUri(parentId)})
+				continue
+			}
+
+			// a split only consumes the quantity of the child
+			quantityNode := &parent
+			if node.CreationProcess == model.ECreationProcessSplit {
+				quantityNode = &node
+			}
+			event.InputQuantityList = append(event.InputQuantityList, epcisQuantity(&parent, quantityNode))
+		}
+		events = append(events, event)
+	}
+
+	document := epcisDocument{
+		Context: []any{
+			epcisContext,
+			map[string]string{"sgp": uriPrefix},
+		},
+		Type:          "EPCISDocument",
+		SchemaVersion: "2.0",
+		CreationDate:  e.clock.Now().UTC().Format(time.RFC3339Nano),
+		EpcisBody: epcisBody{
+			EventList: events,
+		},
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// quantity element of the class node with the quantity of quantityNode
+func epcisQuantity(node *traceNode, quantityNode *traceNode) epcisQuantityElement {
+	ret := epcisQuantityElement{
+		EpcClass: nodeUri(node.Id),
+	}
+	if !quantityNode.IsAsset {
+		return ret
+	}
+
+	uom, ok := epcisUnitCodes[strings.ToLower(strings.TrimSpace(quantityNode.Unit))]
+	if !ok {
+		ret.UnknownUnitQuantity = json.Number(quantityNode.Quantity.String())
+		ret.UnknownUnit = quantityNode.Unit
+		return ret
+	}
+	ret.Quantity = json.Number(quantityNode.Quantity.String())
+	ret.Uom = uom
+	return ret
+}
@@ -0,0 +1,15 @@
+package exporter_server
+
+import (
+	"context"
+	model_server "sig_graph_scp/pkg/server/model"
+)
+
+// convert a traced provenance graph to a standard format
+type TraceExporterI interface {
+	// short name, e.g. epcis
+	Format() string
+	// mime type of the exported document
+	ContentType() string
+	Export(ctx context.Context, trace *model_server.Trace) ([]byte, error)
+}
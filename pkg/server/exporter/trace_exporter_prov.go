@@ -0,0 +1,196 @@
+package exporter_server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	model_server "sig_graph_scp/pkg/server/model"
+	"time"
+)
+
+// typed literal of PROV-JSON
+type provLiteral struct {
+	Value string `json:"$"`
+	Type  string `json:"type"`
+}
+
+type provEntity struct {
+	Type            string       `json:"prov:type"`
+	MaterialName    string       `json:"sgp:materialName,omitempty"`
+	Quantity        *provLiteral `json:"sgp:quantity,omitempty"`
+	Unit            string       `json:"sgp:unit,omitempty"`
+	CreationProcess string       `json:"sgp:creationProcess,omitempty"`
+	IsFinalized     *bool        `json:"sgp:isFinalized,omitempty"`
+	Signature       string       `json:"sgp:signature,omitempty"`
+	// hash hiding a parent, for the entities of opaque edges
+	Hash string `json:"sgp:hash,omitempty"`
+}
+
+type provActivity struct {
+	Type      string `json:"prov:type,omitempty"`
+	StartTime string `json:"prov:startTime"`
+	EndTime   string `json:"prov:endTime"`
+}
+
+type provAgent struct {
+	Type      string `json:"prov:type"`
+	PublicKey string `json:"sgp:publicKey"`
+}
+
+type provGeneration struct {
+	Entity   string `json:"prov:entity"`
+	Activity string `json:"prov:activity"`
+	Time     string `json:"prov:time"`
+}
+
+type provUsage struct {
+	Activity string `json:"prov:activity"`
+	Entity   string `json:"prov:entity"`
+}
+
+type provDerivation struct {
+	GeneratedEntity string `json:"prov:generatedEntity"`
+	UsedEntity      string `json:"prov:usedEntity"`
+	Activity        string `json:"prov:activity"`
+}
+
+type provAttribution struct {
+	Entity string `json:"prov:entity"`
+	Agent  string `json:"prov:agent"`
+}
+
+type provAssociation struct {
+	Activity string `json:"prov:activity"`
+	Agent    string `json:"prov:agent"`
+}
+
+type provDocument struct {
+	Prefix            map[string]string          `json:"prefix"`
+	Entity            map[string]provEntity      `json:"entity"`
+	Activity          map[string]provActivity    `json:"activity"`
+	Agent             map[string]provAgent       `json:"agent"`
+	WasGeneratedBy    map[string]provGeneration  `json:"wasGeneratedBy"`
+	Used              map[string]provUsage       `json:"used"`
+	WasDerivedFrom    map[string]provDerivation  `json:"wasDerivedFrom"`
+	WasAttributedTo   map[string]provAttribution `json:"wasAttributedTo"`
+	WasAssociatedWith map[string]provAssociation `json:"wasAssociatedWith"`
+}
+
+// each node is an entity generated by the activity creating it, which used
+// the parents of the node. Owners are the agents of the entities and
+// activities
+type traceExporterProv struct {
+}
+
+func NewTraceExporterProv() *traceExporterProv {
+	return &traceExporterProv{}
+}
+
+func (e *traceExporterProv) Format() string {
+	return "prov"
+}
+
+func (e *traceExporterProv) ContentType() string {
+	return "application/json"
+}
+
+func (e *traceExporterProv) Export(ctx context.Context, trace *model_server.Trace) ([]byte, error) {
+	index := indexTrace(trace)
+
+	document := provDocument{
+		Prefix:            map[string]string{"sgp": uriPrefix},
+		Entity:            map[string]provEntity{},
+		Activity:          map[string]provActivity{},
+		Agent:             map[string]provAgent{},
+		WasGeneratedBy:    map[string]provGeneration{},
+		Used:              map[string]provUsage{},
+		WasDerivedFrom:    map[string]provDerivation{},
+		WasAttributedTo:   map[string]provAttribution{},
+		WasAssociatedWith: map[string]provAssociation{},
+	}
+
+	// relations are identified by blank nodes
+	relationCount := 0
+	relationId := func() string {
+		relationCount++
+		return fmt.Sprintf("_:r%d", relationCount)
+	}
+
+	for _, node := range index.Nodes {
+		entityId := "sgp:node:" + string(node.Id)
+		activityId := "sgp:event:" + string(node.Id)
+		agentId := "sgp:party:" + fingerprint(node.OwnerPublicKey)
+		createdTime := timeFromMs(node.CreatedTime).Format(time.RFC3339Nano)
+
+		isFinalized := node.IsFinalized
+		entity := provEntity{
+			Type:        "sgp:" + string(node.NodeType),
+			IsFinalized: &isFinalized,
+			Signature:   node.Signature,
+		}
+		if node.IsAsset {
+			entity.MaterialName = node.MaterialName
+			entity.Quantity = &provLiteral{Value: node.Quantity.String(), Type: "xsd:decimal"}
+			entity.Unit = node.Unit
+			entity.CreationProcess = node.CreationProcess
+		}
+		document.Entity[entityId] = entity
+
+		activity := provActivity{
+			StartTime: createdTime,
+			EndTime:   createdTime,
+		}
+		if node.CreationProcess != "" {
+			activity.Type = "sgp:" + node.CreationProcess
+		}
+		document.Activity[activityId] = activity
+
+		document.Agent[agentId] = provAgent{
+			Type:      "prov:Organization",
+			PublicKey: node.OwnerPublicKey,
+		}
+
+		document.WasGeneratedBy[relationId()] = provGeneration{
+			Entity:   entityId,
+			Activity: activityId,
+			Time:     createdTime,
+		}
+		document.WasAttributedTo[relationId()] = provAttribution{
+			Entity: entityId,
+			Agent:  agentId,
+		}
+		document.WasAssociatedWith[relationId()] = provAssociation{
+			Activity: activityId,
+			Agent:    agentId,
+		}
+
+		parentIds := []string{}
+		for _, parentId := range index.Parents[node.Id].Ids {
+			parentIds = append(parentIds, "sgp:node:"+string(parentId))
+		}
+		// the hidden parents are only known by their hash, which is base64 and
+		// cannot be part of a qualified name
+		for _, hash := range index.Parents[node.Id].OpaqueHashes {
+			hiddenId := "sgp:hidden:" + fingerprint(hash)
+			document.Entity[hiddenId] = provEntity{
+				Type: "sgp:hidden",
+				Hash: hash,
+			}
+			parentIds = append(parentIds, hiddenId)
+		}
+
+		for _, parentId := range parentIds {
+			document.Used[relationId()] = provUsage{
+				Activity: activityId,
+				Entity:   parentId,
+			}
+			document.WasDerivedFrom[relationId()] = provDerivation{
+				GeneratedEntity: entityId,
+				UsedEntity:      parentId,
+				Activity:        activityId,
+			}
+		}
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
@@ -0,0 +1,143 @@
+package exporter_server
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var isGoldenUpdated = flag.Bool("update", false, "write the golden files of the exporters")
+
+const (
+	traceExporterTestFarmId     model_server.NodeId = "sgp://memory:[]:public:farm"
+	traceExporterTestMillId     model_server.NodeId = "sgp://memory:[]:public:mill"
+	traceExporterTestBagId      model_server.NodeId = "sgp://memory:[]:public:bag"
+	traceExporterTestBreadId    model_server.NodeId = "sgp://memory:[]:public:bread"
+	traceExporterTestFarmer                         = "farmer public key"
+	traceExporterTestMiller                         = "miller public key"
+	traceExporterTestBaker                          = "baker public key"
+	traceExporterTestBagHash                        = "bag hash"
+	traceExporterTestOpaqueHash                     = "opaque hash"
+)
+
+func newTraceExporterTestNode(
+	id model_server.NodeId,
+	createdTime uint64,
+	owner string,
+	creationProcess model.ECreationProcess,
+	unit string,
+	quantity int64,
+	materialName string,
+) model_server.Node {
+	node := model_server.Node{
+		Id:             id,
+		NodeType:       model.ENodeTypeAsset,
+		CreatedTime:    createdTime,
+		UpdatedTime:    createdTime,
+		OwnerPublicKey: owner,
+	}
+	node.Extra = &model_server.Asset{
+		Node:            node,
+		CreationProcess: creationProcess,
+		Unit:            unit,
+		Quantity:        decimal.NewFromInt(quantity),
+		MaterialName:    materialName,
+	}
+	return node
+}
+
+// wheat created by a farmer, transferred to a miller who splits a bag off it
+// through a private edge. The baker makes crates of bread from the bag and
+// from an input hidden by an opaque edge
+func newTraceExporterTestTrace() *model_server.Trace {
+	return &model_server.Trace{
+		RootId: traceExporterTestBagId,
+		Nodes: map[model_server.NodeId]model_server.Node{
+			traceExporterTestFarmId:  newTraceExporterTestNode(traceExporterTestFarmId, 1000, traceExporterTestFarmer, model.ECreationProcessCreate, "kg", 100, "wheat"),
+			traceExporterTestMillId:  newTraceExporterTestNode(traceExporterTestMillId, 2000, traceExporterTestMiller, model.ECreationProcessTransfer, "kg", 100, "wheat"),
+			traceExporterTestBagId:   newTraceExporterTestNode(traceExporterTestBagId, 3000, traceExporterTestMiller, model.ECreationProcessSplit, "kg", 25, "wheat"),
+			traceExporterTestBreadId: newTraceExporterTestNode(traceExporterTestBreadId, 4000, traceExporterTestBaker, model.ECreationProcessCreate, "crate", 3, "bread"),
+		},
+		Depths: map[model_server.NodeId]int{
+			traceExporterTestFarmId:  2,
+			traceExporterTestMillId:  1,
+			traceExporterTestBagId:   0,
+			traceExporterTestBreadId: 1,
+		},
+		Edges: []model_server.TraceEdge{
+			{ParentId: traceExporterTestFarmId, ChildId: traceExporterTestMillId, EdgeType: model_server.ETraceEdgeTypePublic},
+			{ParentId: traceExporterTestMillId, ChildId: traceExporterTestBagId, EdgeType: model_server.ETraceEdgeTypePrivate, Hash: traceExporterTestBagHash},
+			{ParentId: traceExporterTestBagId, ChildId: traceExporterTestBreadId, EdgeType: model_server.ETraceEdgeTypePublic},
+			{ChildId: traceExporterTestBreadId, EdgeType: model_server.ETraceEdgeTypeOpaque, Hash: traceExporterTestOpaqueHash},
+		},
+	}
+}
+
+func TestTraceExporterGolden(t *testing.T) {
+	clock := utility.NewClockFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	cases := []struct {
+		exporter TraceExporterI
+		golden   string
+	}{
+		{NewTraceExporterEpcis(clock), "trace.epcis.jsonld"},
+		{NewTraceExporterProv(), "trace.prov.json"},
+		{NewTraceExporterDot(), "trace.dot"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.exporter.Format(), func(t *testing.T) {
+			content, err := c.exporter.Export(context.Background(), newTraceExporterTestTrace())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", c.golden)
+			if *isGoldenUpdated {
+				err = os.WriteFile(path, content, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != string(expected) {
+				t.Fatalf("export differs from %s, run go test with -update to accept it:\n%s", path, content)
+			}
+		})
+	}
+}
+
+func TestEpcisQuantityUnits(t *testing.T) {
+	cases := []struct {
+		unit     string
+		expected epcisQuantityElement
+	}{
+		{"kg", epcisQuantityElement{EpcClass: nodeUri(traceExporterTestFarmId), Quantity: "2", Uom: "KGM"}},
+		{" KG ", epcisQuantityElement{EpcClass: nodeUri(traceExporterTestFarmId), Quantity: "2", Uom: "KGM"}},
+		{"crate", epcisQuantityElement{EpcClass: nodeUri(traceExporterTestFarmId), UnknownUnitQuantity: "2", UnknownUnit: "crate"}},
+		{"", epcisQuantityElement{EpcClass: nodeUri(traceExporterTestFarmId), UnknownUnitQuantity: "2"}},
+	}
+
+	for _, c := range cases {
+		node := traceNode{
+			Node:     model_server.Node{Id: traceExporterTestFarmId},
+			Unit:     c.unit,
+			Quantity: decimal.NewFromInt(2),
+			IsAsset:  true,
+		}
+		quantity := epcisQuantity(&node, &node)
+		if quantity != c.expected {
+			t.Fatalf("expected %+v for unit %q, got %+v", c.expected, c.unit, quantity)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+ALTER TABLE gorm_request_to_accept_asset_candidate_ids DROP COLUMN IF EXISTS candidate_signature_scheme;
+
+ALTER TABLE gorm_nodes DROP COLUMN IF EXISTS node_signature_scheme;
@@ -0,0 +1,3 @@
+ALTER TABLE gorm_nodes ADD COLUMN IF NOT EXISTS node_signature_scheme VARCHAR(256) NOT NULL DEFAULT '';
+
+ALTER TABLE gorm_request_to_accept_asset_candidate_ids ADD COLUMN IF NOT EXISTS candidate_signature_scheme VARCHAR(256) NOT NULL DEFAULT '';
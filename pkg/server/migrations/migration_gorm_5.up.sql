@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS gorm_ledger_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id VARCHAR(1024) NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES gorm_users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    function_name VARCHAR(256) NOT NULL,
+    transaction_status VARCHAR(256) NOT NULL,
+    transaction_message VARCHAR(8192) NOT NULL DEFAULT '',
+    created_time_ms BIGINT NOT NULL,
+    updated_time_ms BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS gorm_ledger_transactions_transaction_id ON gorm_ledger_transactions(transaction_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS gorm_fabric_identities;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS gorm_fabric_identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES gorm_users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    identity_name VARCHAR(256) NOT NULL,
+    msp_id VARCHAR(256) NOT NULL,
+    certificate VARCHAR(8192) NOT NULL,
+    private_key VARCHAR(4096) NOT NULL,
+    created_time_ms BIGINT NOT NULL,
+    UNIQUE (user_id, identity_name)
+);
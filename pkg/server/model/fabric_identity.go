@@ -0,0 +1,26 @@
+package model_server
+
+import model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+
+type FabricIdentityDbId = uint64
+
+// Fabric identity signing the chaincode calls of a user, selected by its name
+type FabricIdentity struct {
+	Id     FabricIdentityDbId `json:"id"`
+	UserId UserId             `json:"user_id"`
+	Name   string             `json:"name"`
+	MspId  string             `json:"msp_id"`
+	// pem encoded x509 certificate
+	Certificate string `json:"certificate"`
+	// pem encoded, never serialized
+	PrivateKey  string `json:"-"`
+	CreatedTime uint64 `json:"created_time"`
+}
+
+func ToSigGraphFabricIdentity(fabricIdentity *FabricIdentity) model_sig_graph.FabricIdentity {
+	return model_sig_graph.FabricIdentity{
+		MspId:       fabricIdentity.MspId,
+		Certificate: fabricIdentity.Certificate,
+		PrivateKey:  fabricIdentity.PrivateKey,
+	}
+}
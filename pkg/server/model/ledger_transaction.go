@@ -0,0 +1,18 @@
+package model_server
+
+import "sig_graph_scp/pkg/model"
+
+type LedgerTransactionDbId = uint64
+
+// transaction submitted to SigGraph on behalf of a user
+type LedgerTransaction struct {
+	Id            LedgerTransactionDbId          `json:"id"`
+	TransactionId string                         `json:"transaction_id"`
+	UserId        UserId                         `json:"user_id"`
+	FunctionName  string                         `json:"function_name"`
+	Status        model.ELedgerTransactionStatus `json:"status"`
+	// reason of the failure
+	Message     string `json:"message"`
+	CreatedTime uint64 `json:"created_time"`
+	UpdatedTime uint64 `json:"updated_time"`
+}
@@ -32,11 +32,12 @@ type Node struct {
 	PrivateParentsIds  map[string]PrivateId `json:"private_parents_ids"` // key is the hash
 	PrivateChildrenIds map[string]PrivateId `json:"private_children_ids"`
 
-	IsFinalized    bool   `json:"is_finalized"`
-	CreatedTime    uint64 `json:"created_time"`
-	UpdatedTime    uint64 `json:"updated_time"`
-	Signature      string `json:"signature"`
-	OwnerPublicKey string `json:"owner_public_key"`
+	IsFinalized     bool   `json:"is_finalized"`
+	CreatedTime     uint64 `json:"created_time"`
+	UpdatedTime     uint64 `json:"updated_time"`
+	Signature       string `json:"signature"`
+	SignatureScheme string `json:"signature_scheme"`
+	OwnerPublicKey  string `json:"owner_public_key"`
 
 	Extra any `json:"-"`
 }
@@ -56,6 +57,7 @@ func FromSigGraphNode(node *model_sig_graph.Node, dbId NodeDbId, namespace strin
 	return Node{
 		NodeDbId:           dbId,
 		Id:                 NodeId(node.Id),
+		NodeType:           node.NodeType,
 		Namespace:          namespace,
 		PublicParentsIds:   node.PublicParentsIds,
 		PublicChildrenIds:  node.PublicChildrenIds,
@@ -65,6 +67,7 @@ func FromSigGraphNode(node *model_sig_graph.Node, dbId NodeDbId, namespace strin
 		CreatedTime:        node.CreatedTime,
 		UpdatedTime:        node.UpdatedTime,
 		Signature:          node.Signature,
+		SignatureScheme:    node.SignatureScheme,
 		OwnerPublicKey:     node.OwnerPublicKey,
 	}
 }
@@ -91,6 +94,7 @@ func ToSigGraphNode(node *Node) model_sig_graph.Node {
 		CreatedTime:              node.CreatedTime,
 		UpdatedTime:              node.UpdatedTime,
 		Signature:                node.Signature,
+		SignatureScheme:          node.SignatureScheme,
 		OwnerPublicKey:           node.OwnerPublicKey,
 	}
 }
@@ -9,9 +9,10 @@ import (
 type RequestId uint64
 
 type CandidateId struct {
-	Id        string `json:"id"`
-	Secret    string `json:"secret"`
-	Signature string `json:"signature"`
+	Id              string `json:"id"`
+	Secret          string `json:"secret"`
+	Signature       string `json:"signature"`
+	SignatureScheme string `json:"signature_scheme"`
 }
 
 type RequestToAcceptAsset struct {
@@ -52,9 +53,10 @@ func ToAssetTransferRequestToAcceptAsset(
 	candidates := make([]model_asset_transfer.CandidateId, 0, len(request.CandidateIds))
 	for i := range request.CandidateIds {
 		candidates = append(candidates, model_asset_transfer.CandidateId{
-			Id:        request.CandidateIds[i].Id,
-			Secret:    request.CandidateIds[i].Secret,
-			Signature: request.CandidateIds[i].Signature,
+			Id:              request.CandidateIds[i].Id,
+			Secret:          request.CandidateIds[i].Secret,
+			Signature:       request.CandidateIds[i].Signature,
+			SignatureScheme: request.CandidateIds[i].SignatureScheme,
 		})
 	}
 
@@ -97,9 +99,10 @@ func FromAssetTransferRequestToAcceptAsset(
 	candidateIds := make([]CandidateId, 0, len(request.Candidates))
 	for i := range request.Candidates {
 		candidateIds = append(candidateIds, CandidateId{
-			Id:        request.Candidates[i].Id,
-			Secret:    request.Candidates[i].Secret,
-			Signature: request.Candidates[i].Signature,
+			Id:              request.Candidates[i].Id,
+			Secret:          request.Candidates[i].Secret,
+			Signature:       request.Candidates[i].Signature,
+			SignatureScheme: request.Candidates[i].SignatureScheme,
 		})
 	}
 
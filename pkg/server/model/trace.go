@@ -0,0 +1,39 @@
+package model_server
+
+type ETraceDirection = string
+
+const (
+	ETraceDirectionAncestors   ETraceDirection = "ancestors"
+	ETraceDirectionDescendants ETraceDirection = "descendants"
+	ETraceDirectionBoth        ETraceDirection = "both"
+)
+
+type ETraceEdgeType = string
+
+const (
+	ETraceEdgeTypePublic ETraceEdgeType = "public"
+	// private edge whose secret is known
+	ETraceEdgeTypePrivate ETraceEdgeType = "private"
+	// private edge whose secret is unknown, the other end is not known either
+	ETraceEdgeTypeOpaque ETraceEdgeType = "opaque"
+)
+
+type TraceEdge struct {
+	// empty for an opaque edge toward an ancestor
+	ParentId NodeId `json:"parent_id"`
+	// empty for an opaque edge toward a descendant
+	ChildId  NodeId         `json:"child_id"`
+	EdgeType ETraceEdgeType `json:"edge_type"`
+	// hash hiding the other end of a private or opaque edge on the ledger
+	Hash string `json:"hash,omitempty"`
+}
+
+// provenance graph around a node. Nodes keep their specific model in Extra
+type Trace struct {
+	RootId NodeId          `json:"root_id"`
+	Nodes  map[NodeId]Node `json:"nodes"`
+	// distance to the root, the shortest one if the node is reached in both
+	// directions
+	Depths map[NodeId]int `json:"depths"`
+	Edges  []TraceEdge    `json:"edges"`
+}
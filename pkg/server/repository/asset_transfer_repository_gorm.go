@@ -26,11 +26,12 @@ type gormRequestToAcceptAssetExposedPrivateId struct {
 }
 
 type gormRequestToAcceptAssetCandidateId struct {
-	ID          uint64 `gorm:"primaryKey"`
-	RequestId   model_server.RequestId
-	CandidateId string `gorm:"column:candidate_id"`
-	Secret      string `gorm:"column:candidate_secret"`
-	Signature   string `gorm:"column:candidate_signature"`
+	ID              uint64 `gorm:"primaryKey"`
+	RequestId       model_server.RequestId
+	CandidateId     string `gorm:"column:candidate_id"`
+	Secret          string `gorm:"column:candidate_secret"`
+	Signature       string `gorm:"column:candidate_signature"`
+	SignatureScheme string `gorm:"column:candidate_signature_scheme"`
 }
 
 type gormRequestToAcceptAsset struct {
@@ -171,7 +172,7 @@ func (r *assetTransferRepositoryGorm) FetchAssetAcceptRequestsByAckId(
 	err = tx.Preload("ExposedPrivateConnections").Preload("CandidateIds").Where("ack_id = ? AND is_outbound_or_inbound = ?", ackId, outboundOrInbound).
 		First(&gormRequest).Error
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	modelRequest := toModelRequest(&gormRequest)
@@ -210,9 +211,10 @@ func toModelRequest(gormRequest *gormRequestToAcceptAsset) model_server.RequestT
 		modelRequest.CandidateIds = append(
 			modelRequest.CandidateIds,
 			model_server.CandidateId{
-				Id:        gormRequest.CandidateIds[j].CandidateId,
-				Secret:    gormRequest.CandidateIds[j].Secret,
-				Signature: gormRequest.CandidateIds[j].Signature,
+				Id:              gormRequest.CandidateIds[j].CandidateId,
+				Secret:          gormRequest.CandidateIds[j].Secret,
+				Signature:       gormRequest.CandidateIds[j].Signature,
+				SignatureScheme: gormRequest.CandidateIds[j].SignatureScheme,
 			},
 		)
 	}
@@ -259,9 +261,10 @@ func fromRequestToAcceptAsset(
 		gormRequest.CandidateIds = append(
 			gormRequest.CandidateIds,
 			gormRequestToAcceptAssetCandidateId{
-				CandidateId: request.CandidateIds[i].Id,
-				Secret:      request.CandidateIds[i].Secret,
-				Signature:   request.CandidateIds[i].Signature,
+				CandidateId:     request.CandidateIds[i].Id,
+				Secret:          request.CandidateIds[i].Secret,
+				Signature:       request.CandidateIds[i].Signature,
+				SignatureScheme: request.CandidateIds[i].SignatureScheme,
 			},
 		)
 	}
@@ -0,0 +1,114 @@
+package repository_server
+
+import (
+	"context"
+	model_server "sig_graph_scp/pkg/server/model"
+)
+
+type fabricIdentityRepositoryGorm struct {
+	transactionManager *transactionManagerGorm
+}
+
+var _ FabricIdentityRepositoryI = (*fabricIdentityRepositoryGorm)(nil)
+
+func NewFabricIdentityRepositoryGorm(
+	transactionManager *transactionManagerGorm,
+) *fabricIdentityRepositoryGorm {
+	return &fabricIdentityRepositoryGorm{
+		transactionManager: transactionManager,
+	}
+}
+
+type gormFabricIdentity struct {
+	ID          model_server.FabricIdentityDbId `gorm:"primaryKey"`
+	UserId      model_server.UserId
+	Name        string `gorm:"column:identity_name"`
+	MspId       string
+	Certificate string
+	PrivateKey  string
+	CreatedTime uint64 `gorm:"column:created_time_ms"`
+}
+
+func toModelServerFabricIdentity(fabricIdentity *gormFabricIdentity) model_server.FabricIdentity {
+	return model_server.FabricIdentity{
+		Id:          fabricIdentity.ID,
+		UserId:      fabricIdentity.UserId,
+		Name:        fabricIdentity.Name,
+		MspId:       fabricIdentity.MspId,
+		Certificate: fabricIdentity.Certificate,
+		PrivateKey:  fabricIdentity.PrivateKey,
+		CreatedTime: fabricIdentity.CreatedTime,
+	}
+}
+
+func (r *fabricIdentityRepositoryGorm) CreateFabricIdentity(
+	ctx context.Context,
+	txId TransactionId,
+	fabricIdentity *model_server.FabricIdentity,
+) error {
+	tx, err := r.transactionManager.GetTransaction(ctx, txId)
+	if err != nil {
+		return err
+	}
+
+	gormIdentity := gormFabricIdentity{
+		UserId:      fabricIdentity.UserId,
+		Name:        fabricIdentity.Name,
+		MspId:       fabricIdentity.MspId,
+		Certificate: fabricIdentity.Certificate,
+		PrivateKey:  fabricIdentity.PrivateKey,
+		CreatedTime: fabricIdentity.CreatedTime,
+	}
+	err = tx.Create(&gormIdentity).Error
+	if err != nil {
+		return wrapError(err)
+	}
+
+	fabricIdentity.Id = gormIdentity.ID
+	return nil
+}
+
+func (r *fabricIdentityRepositoryGorm) FetchFabricIdentitiesOfUser(
+	ctx context.Context,
+	txId TransactionId,
+	user *model_server.User,
+) ([]model_server.FabricIdentity, error) {
+	tx, err := r.transactionManager.GetTransaction(ctx, txId)
+	if err != nil {
+		return nil, err
+	}
+
+	gormIdentities := []gormFabricIdentity{}
+	err = tx.Where("user_id = ?", user.ID).Order("id asc").Find(&gormIdentities).Error
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	ret := make([]model_server.FabricIdentity, 0, len(gormIdentities))
+	for i := range gormIdentities {
+		ret = append(ret, toModelServerFabricIdentity(&gormIdentities[i]))
+	}
+	return ret, nil
+}
+
+func (r *fabricIdentityRepositoryGorm) FetchFabricIdentityOfUserByName(
+	ctx context.Context,
+	txId TransactionId,
+	user *model_server.User,
+	name string,
+) (*model_server.FabricIdentity, error) {
+	tx, err := r.transactionManager.GetTransaction(ctx, txId)
+	if err != nil {
+		return nil, err
+	}
+
+	gormIdentity := gormFabricIdentity{}
+	// wrapError turns gorm.ErrRecordNotFound into ErrNotFound
+	err = tx.Where("user_id = ? AND identity_name = ?", user.ID, name).First(&gormIdentity).Error
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	ret := toModelServerFabricIdentity(&gormIdentity)
+	return &ret, nil
+}
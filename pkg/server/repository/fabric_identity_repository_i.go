@@ -0,0 +1,15 @@
+package repository_server
+
+import (
+	"context"
+	model_server "sig_graph_scp/pkg/server/model"
+)
+
+type FabricIdentityRepositoryI interface {
+	// return ErrAlreadyExists if the user has an identity with the same name
+	CreateFabricIdentity(ctx context.Context, txId TransactionId, fabricIdentity *model_server.FabricIdentity) error
+	// oldest first
+	FetchFabricIdentitiesOfUser(ctx context.Context, txId TransactionId, user *model_server.User) ([]model_server.FabricIdentity, error)
+	// return ErrNotFound if the user has no identity with this name
+	FetchFabricIdentityOfUserByName(ctx context.Context, txId TransactionId, user *model_server.User, name string) (*model_server.FabricIdentity, error)
+}
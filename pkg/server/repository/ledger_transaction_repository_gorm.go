@@ -0,0 +1,160 @@
+package repository_server
+
+import (
+	"context"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+)
+
+type ledgerTransactionRepositoryGorm struct {
+	transactionManager *transactionManagerGorm
+}
+
+var _ LedgerTransactionRepositoryI = (*ledgerTransactionRepositoryGorm)(nil)
+
+func NewLedgerTransactionRepositoryGorm(
+	transactionManager *transactionManagerGorm,
+) *ledgerTransactionRepositoryGorm {
+	return &ledgerTransactionRepositoryGorm{
+		transactionManager: transactionManager,
+	}
+}
+
+// characters of the transaction_message column
+const ledgerTransactionMessageMaxLength = 8192
+
+// the end of longer messages, e.g. errors quoting the ledger, is cut
+func truncateLedgerTransactionMessage(message string) string {
+	runes := []rune(message)
+	if len(runes) <= ledgerTransactionMessageMaxLength {
+		return message
+	}
+	return string(runes[:ledgerTransactionMessageMaxLength])
+}
+
+type gormLedgerTransaction struct {
+	ID            model_server.LedgerTransactionDbId `gorm:"primaryKey"`
+	TransactionId string
+	UserId        model_server.UserId
+	FunctionName  string
+	Status        model.ELedgerTransactionStatus `gorm:"column:transaction_status"`
+	Message       string                         `gorm:"column:transaction_message"`
+	CreatedTime   uint64                         `gorm:"column:created_time_ms"`
+	UpdatedTime   uint64                         `gorm:"column:updated_time_ms"`
+}
+
+func toModelServerLedgerTransaction(transaction *gormLedgerTransaction) model_server.LedgerTransaction {
+	return model_server.LedgerTransaction{
+		Id:            transaction.ID,
+		TransactionId: transaction.TransactionId,
+		UserId:        transaction.UserId,
+		FunctionName:  transaction.FunctionName,
+		Status:        transaction.Status,
+		Message:       transaction.Message,
+		CreatedTime:   transaction.CreatedTime,
+		UpdatedTime:   transaction.UpdatedTime,
+	}
+}
+
+func (r *ledgerTransactionRepositoryGorm) CreateLedgerTransaction(
+	ctx context.Context,
+	txId TransactionId,
+	transaction *model_server.LedgerTransaction,
+) error {
+	tx, err := r.transactionManager.GetTransaction(ctx, txId)
+	if err != nil {
+		return err
+	}
+
+	gormTransaction := gormLedgerTransaction{
+		TransactionId: transaction.TransactionId,
+		UserId:        transaction.UserId,
+		FunctionName:  transaction.FunctionName,
+		Status:        transaction.Status,
+		Message:       truncateLedgerTransactionMessage(transaction.Message),
+		CreatedTime:   transaction.CreatedTime,
+		UpdatedTime:   transaction.UpdatedTime,
+	}
+	err = tx.Create(&gormTransaction).Error
+	if err != nil {
+		return err
+	}
+
+	transaction.Id = gormTransaction.ID
+	return nil
+}
+
+func (r *ledgerTransactionRepositoryGorm) UpdateLedgerTransaction(
+	ctx context.Context,
+	txId TransactionId,
+	transaction *model_server.LedgerTransaction,
+) error {
+	tx, err := r.transactionManager.GetTransaction(ctx, txId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&gormLedgerTransaction{ID: transaction.Id}).Updates(map[string]any{
+		"transaction_id":      transaction.TransactionId,
+		"transaction_status":  transaction.Status,
+		"transaction_message": truncateLedgerTransactionMessage(transaction.Message),
+		"updated_time_ms":     transaction.UpdatedTime,
+	}).Error
+}
+
+func (r *ledgerTransactionRepositoryGorm) FetchLedgerTransactionsByIds(
+	ctx context.Context,
+	txId TransactionId,
+	user *model_server.User,
+	iIds map[model_server.LedgerTransactionDbId]bool,
+) ([]model_server.LedgerTransaction, error) {
+	tx, err := r.transactionManager.GetTransaction(ctx, txId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]model_server.LedgerTransactionDbId, 0, len(iIds))
+	for id := range iIds {
+		ids = append(ids, id)
+	}
+
+	gormTransactions := []gormLedgerTransaction{}
+	err = tx.Where("user_id = ? AND id IN ?", user.ID, ids).Order("id asc").Find(&gormTransactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]model_server.LedgerTransaction, 0, len(gormTransactions))
+	for i := range gormTransactions {
+		ret = append(ret, toModelServerLedgerTransaction(&gormTransactions[i]))
+	}
+	return ret, nil
+}
+
+func (r *ledgerTransactionRepositoryGorm) FetchLedgerTransactionsByStatuses(
+	ctx context.Context,
+	txId TransactionId,
+	iStatuses map[model.ELedgerTransactionStatus]bool,
+) ([]model_server.LedgerTransaction, error) {
+	tx, err := r.transactionManager.GetTransaction(ctx, txId)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]model.ELedgerTransactionStatus, 0, len(iStatuses))
+	for status := range iStatuses {
+		statuses = append(statuses, status)
+	}
+
+	gormTransactions := []gormLedgerTransaction{}
+	err = tx.Where("transaction_status IN ?", statuses).Order("id asc").Find(&gormTransactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]model_server.LedgerTransaction, 0, len(gormTransactions))
+	for i := range gormTransactions {
+		ret = append(ret, toModelServerLedgerTransaction(&gormTransactions[i]))
+	}
+	return ret, nil
+}
@@ -0,0 +1,17 @@
+package repository_server
+
+import (
+	"context"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+)
+
+type LedgerTransactionRepositoryI interface {
+	CreateLedgerTransaction(ctx context.Context, txId TransactionId, transaction *model_server.LedgerTransaction) error
+	// update transaction id, status, message and updated time
+	UpdateLedgerTransaction(ctx context.Context, txId TransactionId, transaction *model_server.LedgerTransaction) error
+	// transactions of other users are omitted
+	FetchLedgerTransactionsByIds(ctx context.Context, txId TransactionId, user *model_server.User, ids map[model_server.LedgerTransactionDbId]bool) ([]model_server.LedgerTransaction, error)
+	// transactions of every user
+	FetchLedgerTransactionsByStatuses(ctx context.Context, txId TransactionId, statuses map[model.ELedgerTransactionStatus]bool) ([]model_server.LedgerTransaction, error)
+}
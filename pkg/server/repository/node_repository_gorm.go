@@ -24,15 +24,16 @@ func NewNodeRepositoryGorm(
 }
 
 type gormNode struct {
-	ID             uint64 `gorm:"primaryKey,autoIncrement"`
-	NodeID         string `gorm:"column:node_id;index:node_id_and_namespace,unique"`
-	Namespace      string `gorm:"column:node_namespace;index:node_id_and_namespace,unique"`
-	NodeType       string `gorm:"not null"`
-	IsFinalized    bool   `gorm:"not null"`
-	CreatedTime    uint64 `gorm:"not null"`
-	UpdatedTime    uint64 `gorm:"not null"`
-	Signature      string `gorm:"column:node_signature;not null"`
-	OwnerPublicKey string `gorm:"not null"`
+	ID              uint64 `gorm:"primaryKey,autoIncrement"`
+	NodeID          string `gorm:"column:node_id;index:node_id_and_namespace,unique"`
+	Namespace       string `gorm:"column:node_namespace;index:node_id_and_namespace,unique"`
+	NodeType        string `gorm:"not null"`
+	IsFinalized     bool   `gorm:"not null"`
+	CreatedTime     uint64 `gorm:"not null"`
+	UpdatedTime     uint64 `gorm:"not null"`
+	Signature       string `gorm:"column:node_signature;not null"`
+	SignatureScheme string `gorm:"column:node_signature_scheme;not null"`
+	OwnerPublicKey  string `gorm:"not null"`
 
 	PublicEdges  []gormPublicEdge  `gorm:"foreignKey:NodeDbId"`
 	PrivateEdges []gormPrivateEdge `gorm:"foreignKey:NodeDbId"`
@@ -68,14 +69,15 @@ func (r *nodeRepositoryGorm) UpsertNode(
 	}
 
 	gormNode := gormNode{
-		NodeID:         string(iNode.Id),
-		Namespace:      iNode.Namespace,
-		NodeType:       string(iNode.NodeType),
-		IsFinalized:    iNode.IsFinalized,
-		CreatedTime:    iNode.CreatedTime,
-		UpdatedTime:    iNode.UpdatedTime,
-		Signature:      iNode.Signature,
-		OwnerPublicKey: iNode.OwnerPublicKey,
+		NodeID:          string(iNode.Id),
+		Namespace:       iNode.Namespace,
+		NodeType:        string(iNode.NodeType),
+		IsFinalized:     iNode.IsFinalized,
+		CreatedTime:     iNode.CreatedTime,
+		UpdatedTime:     iNode.UpdatedTime,
+		Signature:       iNode.Signature,
+		SignatureScheme: iNode.SignatureScheme,
+		OwnerPublicKey:  iNode.OwnerPublicKey,
 	}
 
 	for publicParent := range iNode.PublicParentsIds {
@@ -264,6 +266,7 @@ func gormNodeToModelNode(node *gormNode) model_server.Node {
 		CreatedTime:        node.CreatedTime,
 		UpdatedTime:        node.UpdatedTime,
 		Signature:          node.Signature,
+		SignatureScheme:    node.SignatureScheme,
 		OwnerPublicKey:     node.OwnerPublicKey,
 	}
 	return modelNode
@@ -413,6 +416,36 @@ func (r *nodeRepositoryGorm) FetchPrivateEdgesByNodeIds(
 	return privateEdges, nil
 }
 
+func (r *nodeRepositoryGorm) FetchNamespacesByNodeIds(
+	ctx context.Context,
+	transactionId TransactionId,
+	iIds map[model_server.NodeId]bool,
+) (map[model_server.NodeId][]string, error) {
+	tx, err := r.transactionManager.GetTransaction(ctx, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []model_server.NodeId{}
+	for id := range iIds {
+		ids = append(ids, id)
+	}
+
+	nodes := []gormNode{}
+	err = tx.Select("node_id", "node_namespace").Where("node_id IN ?", ids).Order("id asc").Find(&nodes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[model_server.NodeId][]string{}
+	for i := range nodes {
+		id := model_server.NodeId(nodes[i].NodeID)
+		ret[id] = append(ret[id], nodes[i].Namespace)
+	}
+
+	return ret, nil
+}
+
 func toModelServePrivateId(
 	privateId *gormPrivateEdge,
 ) model_server.PrivateId {
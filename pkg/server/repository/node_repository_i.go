@@ -34,4 +34,6 @@ type NodeRepositoryI interface {
 	FetchNodesByNodeId(ctx context.Context, transactionId TransactionId, nodeType model.ENodeType, namespace string, id map[model_server.NodeId]bool) ([]model_server.Node, error)
 	FetchNodesByDbId(ctx context.Context, transactionId TransactionId, nodeType model.ENodeType, namespace string, id map[model_server.NodeDbId]bool) ([]model_server.Node, error)
 	FetchPrivateEdgesByNodeIds(ctx context.Context, transactionId TransactionId, namespace string, edges []EdgeNodeId) ([]model_server.PrivateId, error)
+	// namespaces in which each node is cached, ids that are not cached are omitted
+	FetchNamespacesByNodeIds(ctx context.Context, transactionId TransactionId, ids map[model_server.NodeId]bool) (map[model_server.NodeId][]string, error)
 }
@@ -2,13 +2,15 @@ package service_server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"sig_graph_scp/pkg/model"
 	model_server "sig_graph_scp/pkg/server/model"
 	repository_server "sig_graph_scp/pkg/server/repository"
 	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 	"sig_graph_scp/pkg/utility"
+	"sort"
 )
 
 type NodeServiceI interface {
@@ -38,24 +40,61 @@ type NodeServiceI interface {
 		ids map[model_server.NodeId]bool,
 		useCache bool,
 	) (map[model_server.NodeId]model_server.Node, error)
+
+	// walk the ancestors and/or descendants of a node up to maxDepth edges
+	// away. Private edges are followed when the secret is cached for the
+	// user, other private edges are returned as opaque. Nodes that are not
+	// cached are read from the ledger, the cache is left as is
+	TraceNode(
+		ctx context.Context,
+		txId repository_server.TransactionId,
+		user *model_server.User,
+		id model_server.NodeId,
+		direction model_server.ETraceDirection,
+		maxDepth int,
+		useCache bool,
+	) (*model_server.Trace, error)
+
+	// proof of the provenance of a node, with the ledger nodes of its trace
+	// and the cached secrets opening the private edges between them
+	BuildProofBundle(
+		ctx context.Context,
+		txId repository_server.TransactionId,
+		user *model_server.User,
+		id model_server.NodeId,
+		direction model_server.ETraceDirection,
+		maxDepth int,
+	) (*model_sig_graph.ProofBundle, error)
+
+	// refetch nodes that changed on the ledger. A node is updated in every
+	// namespace that caches it and added to the namespace of the user
+	// owning it, other nodes are ignored
+	UpdateCachedNodes(
+		ctx context.Context,
+		txId repository_server.TransactionId,
+		ids map[model_server.NodeId]bool,
+	) error
 }
 
 func NewNodeService(
 	nodeRepository repository_server.NodeRepositoryI,
-	genericNodeRepository map[model.ENodeType]repository_server.GenericNodeRepositoryI,
+	nodeTypes NodeTypeRegistryI,
+	keyRepository repository_server.UserKeyRepositoryI,
 	sigGraphApi api_sig_graph.SigGraphClientApi,
 ) *nodeService {
 	return &nodeService{
-		nodeRepository:        nodeRepository,
-		sigGraphApi:           sigGraphApi,
-		genericNodeRepository: genericNodeRepository,
+		nodeRepository: nodeRepository,
+		sigGraphApi:    sigGraphApi,
+		nodeTypes:      nodeTypes,
+		keyRepository:  keyRepository,
 	}
 }
 
 type nodeService struct {
-	nodeRepository        repository_server.NodeRepositoryI
-	genericNodeRepository map[model.ENodeType]repository_server.GenericNodeRepositoryI
-	sigGraphApi           api_sig_graph.SigGraphClientApi
+	nodeRepository repository_server.NodeRepositoryI
+	nodeTypes      NodeTypeRegistryI
+	keyRepository  repository_server.UserKeyRepositoryI
+	sigGraphApi    api_sig_graph.SigGraphClientApi
 }
 
 func (s *nodeService) UpdateNodeSecretId(
@@ -159,6 +198,337 @@ func (s *nodeService) FetchNodesByIds(
 	)
 }
 
+func (s *nodeService) TraceNode(
+	ctx context.Context,
+	txId repository_server.TransactionId,
+	user *model_server.User,
+	id model_server.NodeId,
+	direction model_server.ETraceDirection,
+	maxDepth int,
+	useCache bool,
+) (*model_server.Trace, error) {
+	if maxDepth < 0 {
+		return nil, fmt.Errorf("%w: negative max depth %d", utility.ErrInvalidArgument, maxDepth)
+	}
+
+	directions := []model_server.ETraceDirection{}
+	switch direction {
+	case model_server.ETraceDirectionAncestors, model_server.ETraceDirectionDescendants:
+		directions = append(directions, direction)
+	case model_server.ETraceDirectionBoth:
+		directions = append(directions, model_server.ETraceDirectionAncestors, model_server.ETraceDirectionDescendants)
+	default:
+		return nil, fmt.Errorf("%w: unknown trace direction %s", utility.ErrInvalidArgument, direction)
+	}
+
+	namespace := fmt.Sprintf("%d", user.ID)
+	rootNodes, err := s.fetchTraceNodesByIds(ctx, txId, namespace, map[model_server.NodeId]bool{id: true}, useCache)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &model_server.Trace{
+		RootId: id,
+		Nodes:  map[model_server.NodeId]model_server.Node{id: rootNodes[id]},
+		Depths: map[model_server.NodeId]int{id: 0},
+		Edges:  []model_server.TraceEdge{},
+	}
+	addedEdges := map[model_server.TraceEdge]bool{}
+	for _, direction := range directions {
+		err = s.traceNode(ctx, txId, namespace, trace, addedEdges, direction, maxDepth, useCache)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return trace, nil
+}
+
+func (s *nodeService) BuildProofBundle(
+	ctx context.Context,
+	txId repository_server.TransactionId,
+	user *model_server.User,
+	id model_server.NodeId,
+	direction model_server.ETraceDirection,
+	maxDepth int,
+) (*model_sig_graph.ProofBundle, error) {
+	// the cache holds the secrets
+	trace, err := s.TraceNode(ctx, txId, user, id, direction, maxDepth, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// nodes as signed on the ledger
+	ids := map[string]bool{}
+	for id := range trace.Nodes {
+		ids[string(id)] = true
+	}
+	nodesJson, err := s.sigGraphApi.FetchNodesJsonByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// graph of the root, the client may hold several graphs
+	graphName, err := model_sig_graph.GraphNameOfNodeId(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &model_sig_graph.ProofBundle{
+		Version:    model_sig_graph.ProofBundleVersionV1,
+		GraphName:  graphName,
+		RootId:     string(id),
+		Nodes:      []json.RawMessage{},
+		Secrets:    []model_sig_graph.ProofBundleSecret{},
+		PublicKeys: []string{},
+	}
+
+	nodeIds := make([]string, 0, len(nodesJson))
+	for id := range nodesJson {
+		nodeIds = append(nodeIds, id)
+	}
+	sort.Strings(nodeIds)
+
+	publicKeys := map[string]bool{}
+	for _, id := range nodeIds {
+		// kept byte for byte, a verifier checks the signatures over these
+		bundle.Nodes = append(bundle.Nodes, nodesJson[id])
+
+		node := model_sig_graph.Node{}
+		err = json.Unmarshal(nodesJson[id], &node)
+		if err != nil {
+			return nil, fmt.Errorf("%w: node %s: %s", utility.ErrInvalidArgument, id, err)
+		}
+		if !publicKeys[node.OwnerPublicKey] {
+			publicKeys[node.OwnerPublicKey] = true
+			bundle.PublicKeys = append(bundle.PublicKeys, node.OwnerPublicKey)
+		}
+	}
+
+	// only the secrets of the edges within the bundle, on either side
+	secrets := map[model_sig_graph.ProofBundleSecret]bool{}
+	for _, node := range trace.Nodes {
+		for _, privateIds := range []map[string]model_server.PrivateId{node.PrivateParentsIds, node.PrivateChildrenIds} {
+			for _, privateId := range privateIds {
+				if _, ok := trace.Nodes[privateId.ThisId]; !ok {
+					continue
+				}
+
+				if privateId.ThisSecret != "" {
+					secrets[model_sig_graph.ProofBundleSecret{NodeId: string(privateId.ThisId), Secret: privateId.ThisSecret}] = true
+				}
+				if privateId.OtherSecret != "" && privateId.OtherHash != "" {
+					secrets[model_sig_graph.ProofBundleSecret{NodeId: string(node.Id), Secret: privateId.OtherSecret}] = true
+				}
+			}
+		}
+	}
+	for secret := range secrets {
+		bundle.Secrets = append(bundle.Secrets, secret)
+	}
+	sort.Slice(bundle.Secrets, func(i, j int) bool {
+		if bundle.Secrets[i].NodeId != bundle.Secrets[j].NodeId {
+			return bundle.Secrets[i].NodeId < bundle.Secrets[j].NodeId
+		}
+		return bundle.Secrets[i].Secret < bundle.Secrets[j].Secret
+	})
+
+	return bundle, nil
+}
+
+func (s *nodeService) UpdateCachedNodes(
+	ctx context.Context,
+	txId repository_server.TransactionId,
+	ids map[model_server.NodeId]bool,
+) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	namespaces, err := s.nodeRepository.FetchNamespacesByNodeIds(ctx, txId, ids)
+	if err != nil {
+		return err
+	}
+
+	idsToFetch := map[string]bool{}
+	for id := range ids {
+		idsToFetch[string(id)] = true
+	}
+
+	sigGraphNodes, err := s.sigGraphApi.FetchNodesByIds(ctx, idsToFetch)
+	if err != nil {
+		return err
+	}
+
+	for id := range sigGraphNodes {
+		extractedNode, err := s.extractSigGraphNode(sigGraphNodes[id])
+		if err != nil {
+			return err
+		}
+
+		nodeNamespaces := map[string]bool{}
+		for _, namespace := range namespaces[model_server.NodeId(id)] {
+			nodeNamespaces[namespace] = true
+		}
+
+		owner, err := s.keyRepository.FetchUserWithPublicKey(ctx, txId, extractedNode.OwnerPublicKey)
+		if err == nil {
+			nodeNamespaces[fmt.Sprintf("%d", owner.ID)] = true
+		} else if !errors.Is(err, utility.ErrNotFound) {
+			return err
+		}
+
+		for namespace := range nodeNamespaces {
+			_, err = s.saveGenericSigGraphNodeAndConvertToModel(
+				ctx,
+				txId,
+				namespace,
+				map[string]any{id: sigGraphNodes[id]},
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// breadth first walk from the root of trace in one direction
+func (s *nodeService) traceNode(
+	ctx context.Context,
+	txId repository_server.TransactionId,
+	namespace string,
+	trace *model_server.Trace,
+	addedEdges map[model_server.TraceEdge]bool,
+	direction model_server.ETraceDirection,
+	maxDepth int,
+	useCache bool,
+) error {
+	addEdge := func(edge model_server.TraceEdge) {
+		if !addedEdges[edge] {
+			addedEdges[edge] = true
+			trace.Edges = append(trace.Edges, edge)
+		}
+	}
+
+	visited := map[model_server.NodeId]bool{trace.RootId: true}
+	frontier := []model_server.NodeId{trace.RootId}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		nextIds := map[model_server.NodeId]bool{}
+		for _, id := range frontier {
+			node := trace.Nodes[id]
+
+			publicIds := node.PublicParentsIds
+			privateIds := node.PrivateParentsIds
+			if direction == model_server.ETraceDirectionDescendants {
+				publicIds = node.PublicChildrenIds
+				privateIds = node.PrivateChildrenIds
+			}
+
+			edge := func(otherId model_server.NodeId, edgeType model_server.ETraceEdgeType, hash string) model_server.TraceEdge {
+				if direction == model_server.ETraceDirectionDescendants {
+					return model_server.TraceEdge{ParentId: id, ChildId: otherId, EdgeType: edgeType, Hash: hash}
+				}
+				return model_server.TraceEdge{ParentId: otherId, ChildId: id, EdgeType: edgeType, Hash: hash}
+			}
+
+			for otherId := range publicIds {
+				addEdge(edge(model_server.NodeId(otherId), model_server.ETraceEdgeTypePublic, ""))
+				if !visited[model_server.NodeId(otherId)] {
+					nextIds[model_server.NodeId(otherId)] = true
+				}
+			}
+
+			// the hash of a private edge is the one of the other end
+			for hash, privateId := range privateIds {
+				if privateId.ThisId == "" {
+					addEdge(edge("", model_server.ETraceEdgeTypeOpaque, hash))
+					continue
+				}
+
+				addEdge(edge(privateId.ThisId, model_server.ETraceEdgeTypePrivate, hash))
+				if !visited[privateId.ThisId] {
+					nextIds[privateId.ThisId] = true
+				}
+			}
+		}
+
+		idsToFetch := map[model_server.NodeId]bool{}
+		for id := range nextIds {
+			if _, ok := trace.Nodes[id]; !ok {
+				idsToFetch[id] = true
+			}
+		}
+		fetchedNodes, err := s.fetchTraceNodesByIds(ctx, txId, namespace, idsToFetch, useCache)
+		if err != nil {
+			return err
+		}
+		for id := range fetchedNodes {
+			trace.Nodes[id] = fetchedNodes[id]
+		}
+
+		frontier = []model_server.NodeId{}
+		for id := range nextIds {
+			visited[id] = true
+			frontier = append(frontier, id)
+			if knownDepth, ok := trace.Depths[id]; !ok || depth < knownDepth {
+				trace.Depths[id] = depth
+			}
+		}
+	}
+
+	return nil
+}
+
+// nodes of a trace, from the cache of namespace if useCache and from the
+// ledger otherwise. The ledger nodes are not cached, a trace only reads
+func (s *nodeService) fetchTraceNodesByIds(
+	ctx context.Context,
+	txId repository_server.TransactionId,
+	namespace string,
+	ids map[model_server.NodeId]bool,
+	useCache bool,
+) (map[model_server.NodeId]model_server.Node, error) {
+	ret := map[model_server.NodeId]model_server.Node{}
+	if len(ids) == 0 {
+		return ret, nil
+	}
+
+	if useCache {
+		cachedNodes, err := s.fetchCachedNodes(ctx, txId, namespace, ids)
+		if err != nil {
+			return nil, err
+		}
+		for id := range cachedNodes {
+			ret[id] = cachedNodes[id]
+		}
+	}
+
+	nodesToFetch := map[string]bool{}
+	for id := range ids {
+		if _, ok := ret[id]; !ok {
+			nodesToFetch[string(id)] = true
+		}
+	}
+	if len(nodesToFetch) == 0 {
+		return ret, nil
+	}
+
+	sigGraphNodes, err := s.sigGraphApi.FetchNodesByIds(ctx, nodesToFetch)
+	if err != nil {
+		return nil, err
+	}
+	for id := range sigGraphNodes {
+		ret[model_server.NodeId(id)], _, err = s.parseSigGraphNodeToServerNode(sigGraphNodes[id], namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
 func (s *nodeService) fetchNodesByIds(
 	ctx context.Context,
 	txId repository_server.TransactionId,
@@ -236,8 +606,12 @@ func (s *nodeService) fetchCachedNodes(
 		return nil, err
 	}
 	for i := range nodes {
-		nodeType := nodes[i].NodeType
-		extendedNodes[nodes[i].Id], err = s.genericNodeRepository[nodeType].FetchNode(
+		nodeType, err := s.nodeTypes.Get(nodes[i].NodeType)
+		if err != nil {
+			return nil, err
+		}
+
+		extendedNodes[nodes[i].Id], err = nodeType.Repository().FetchNode(
 			ctx,
 			txId,
 			&nodes[i],
@@ -265,7 +639,7 @@ func (s *nodeService) saveGenericModel(
 		}
 		modelNode := modelNodes[id]
 
-		savedNode, err := s.genericNodeRepository[nodeType].UpsertNode(
+		savedNode, err := nodeType.Repository().UpsertNode(
 			ctx,
 			txId,
 			&modelNode,
@@ -292,7 +666,7 @@ func (s *nodeService) saveGenericSigGraphNodeAndConvertToModel(
 			return nil, err
 		}
 
-		parsedNode, err = s.genericNodeRepository[nodeType].UpsertNode(
+		parsedNode, err = nodeType.Repository().UpsertNode(
 			ctx,
 			txId,
 			&parsedNode,
@@ -385,35 +759,32 @@ func (s *nodeService) fetchPrivateEdges(
 	return nil
 }
 
-func (s *nodeService) extractServerNode(iNode any) (extractedNode model_server.Node, nodeType model.ENodeType, err error) {
-	if node, ok := iNode.(model_server.Node); ok {
-		if asset, ok := node.Extra.(*model_server.Asset); ok {
-			return asset.Node, model.ENodeTypeAsset, nil
-		}
-		return node, model.ENodeTypeNode, nil
-	} else if node, ok := iNode.(model_server.Asset); ok {
-		return node.Node, model.ENodeTypeAsset, nil
-	} else {
-		return model_server.Node{}, "", utility.ErrInvalidArgument
+func (s *nodeService) extractServerNode(node model_server.Node) (extractedNode model_server.Node, nodeType NodeTypeI, err error) {
+	nodeType, err = s.nodeTypes.Get(node.NodeType)
+	if err != nil {
+		return model_server.Node{}, nil, fmt.Errorf("%w: node %s: %s", utility.ErrInvalidArgument, node.Id, err.Error())
 	}
+	return node, nodeType, nil
 }
 
 func (s *nodeService) extractSigGraphNode(iNode any) (extractedNode model_sig_graph.Node, err error) {
-	if node, ok := iNode.(model_sig_graph.Node); ok {
-		return node, nil
-	} else if node, ok := iNode.(model_sig_graph.Asset); ok {
-		return node.Node, nil
-	} else {
-		return model_sig_graph.Node{}, utility.ErrInvalidArgument
+	if node, ok := iNode.(model_sig_graph.NodeI); ok {
+		return node.BaseNode(), nil
 	}
+	return model_sig_graph.Node{}, utility.ErrInvalidArgument
 }
 
 // create model_server structs from model_sig_graph structs
 // fields that cannot be filled will be filled with default values
-func (s *nodeService) parseSigGraphNodeToServerNode(iNode any, namspace string) (modelServerNode model_server.Node, nodeType model.ENodeType, err error) {
+func (s *nodeService) parseSigGraphNodeToServerNode(iNode any, namspace string) (modelServerNode model_server.Node, nodeType NodeTypeI, err error) {
 	extractedNode, err := s.extractSigGraphNode(iNode)
 	if err != nil {
-		return model_server.Node{}, "", err
+		return model_server.Node{}, nil, err
+	}
+
+	nodeType, err = s.nodeTypes.Get(extractedNode.NodeType)
+	if err != nil {
+		return model_server.Node{}, nil, fmt.Errorf("%w: node %s: %s", utility.ErrInvalidArgument, extractedNode.Id, err.Error())
 	}
 
 	privateParentIds := map[string]model_server.PrivateId{}
@@ -433,20 +804,11 @@ func (s *nodeService) parseSigGraphNodeToServerNode(iNode any, namspace string)
 		privateChildrenIds,
 	)
 
-	switch extractedNode.NodeType {
-	case model.ENodeTypeAsset:
-		if asset, ok := iNode.(model_sig_graph.Asset); !ok {
-			return model_server.Node{}, "", utility.ErrInvalidArgument
-		} else {
-			modelAsset := model_server.FromSigGraphAsset(
-				&asset,
-				&modelNode,
-			)
-			return modelAsset.Node, model.ENodeTypeAsset, nil
-		}
-	default:
-		return model_server.Node{}, "", utility.ErrInvalidArgument
+	modelServerNode, err = nodeType.FromSigGraphNode(iNode.(model_sig_graph.NodeI), &modelNode)
+	if err != nil {
+		return model_server.Node{}, nil, err
 	}
+	return modelServerNode, nodeType, nil
 }
 
 func (s *nodeService) buildNodesToFetchMap(
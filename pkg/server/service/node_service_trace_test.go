@@ -0,0 +1,365 @@
+package service_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	repository_server "sig_graph_scp/pkg/server/repository"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sort"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+const traceTestNamespace = "1"
+
+var traceTestUser = &model_server.User{ID: 1}
+
+// ledger of assets, counts how often each node is read
+type traceTestLedger struct {
+	api_sig_graph.SigGraphClientApi
+	assets map[string]model_sig_graph.Asset
+	reads  map[string]int
+}
+
+func newTraceTestLedger(ids ...string) *traceTestLedger {
+	ledger := &traceTestLedger{
+		assets: map[string]model_sig_graph.Asset{},
+		reads:  map[string]int{},
+	}
+	for _, id := range ids {
+		ledger.assets[id] = model_sig_graph.NewAsset(
+			model_sig_graph.NewDefaultNode(id, model.ENodeTypeAsset, 1, 1, "signature", "owner"),
+			model.ECreationProcessCreate,
+			"kg",
+			decimal.NewFromInt(1),
+			"flour",
+		)
+	}
+	return ledger
+}
+
+func (l *traceTestLedger) linkPublic(parentId string, childId string) {
+	l.assets[parentId].PublicChildrenIds[childId] = true
+	l.assets[childId].PublicParentsIds[parentId] = true
+}
+
+func (l *traceTestLedger) linkPrivate(parentId string, childId string, parentHash string, childHash string) {
+	l.assets[parentId].PrivateChildrenHashedIds[childHash] = true
+	l.assets[childId].PrivateParentsHashedIds[parentHash] = true
+}
+
+func (l *traceTestLedger) FetchNodesByIds(ctx context.Context, ids map[string]bool) (map[string]any, error) {
+	ret := map[string]any{}
+	for id := range ids {
+		asset, ok := l.assets[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: node %s", utility.ErrNotFound, id)
+		}
+		l.reads[id]++
+		ret[id] = asset
+	}
+	return ret, nil
+}
+
+// cache of the nodes of traceTestNamespace, fails the test on writes
+type traceTestRepository struct {
+	repository_server.NodeRepositoryI
+	t      *testing.T
+	cached map[model_server.NodeId]model_server.Node
+}
+
+func (r *traceTestRepository) FetchNodesByNodeId(
+	ctx context.Context,
+	transactionId repository_server.TransactionId,
+	nodeType model.ENodeType,
+	namespace string,
+	ids map[model_server.NodeId]bool,
+) ([]model_server.Node, error) {
+	nodes := []model_server.Node{}
+	if namespace != traceTestNamespace {
+		return nodes, nil
+	}
+	for id := range ids {
+		if node, ok := r.cached[id]; ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (r *traceTestRepository) UpsertNode(
+	ctx context.Context,
+	transactionId repository_server.TransactionId,
+	node *model_server.Node,
+) error {
+	r.t.Errorf("node %s cached by a trace", node.Id)
+	return nil
+}
+
+// cache of the specific models, the cached nodes are complete
+type traceTestAssetRepository struct {
+	t *testing.T
+}
+
+func (r *traceTestAssetRepository) FetchNode(
+	ctx context.Context,
+	txId repository_server.TransactionId,
+	node *model_server.Node,
+) (model_server.Node, error) {
+	return *node, nil
+}
+
+func (r *traceTestAssetRepository) UpsertNode(
+	ctx context.Context,
+	txId repository_server.TransactionId,
+	node *model_server.Node,
+) (model_server.Node, error) {
+	r.t.Errorf("asset %s cached by a trace", node.Id)
+	return *node, nil
+}
+
+func newTraceTestService(t *testing.T, ledger *traceTestLedger, cached ...model_server.Node) *nodeService {
+	t.Helper()
+
+	repository := &traceTestRepository{t: t, cached: map[model_server.NodeId]model_server.Node{}}
+	for _, node := range cached {
+		repository.cached[node.Id] = node
+	}
+	nodeTypes := NewNodeTypeRegistry(api_sig_graph.NewNodeTypeRegistry())
+	err := nodeTypes.Register(NewAssetNodeType(&traceTestAssetRepository{t: t}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewNodeService(repository, nodeTypes, nil, ledger)
+}
+
+// node of the ledger as cached, with the other ends of its private edges
+func cachedTraceTestNode(
+	t *testing.T,
+	ledger *traceTestLedger,
+	id string,
+	privateChildrenIds map[string]model_server.PrivateId,
+) model_server.Node {
+	t.Helper()
+
+	asset := ledger.assets[id]
+	node := model_server.FromSigGraphNode(&asset.Node, 1, traceTestNamespace, map[string]model_server.PrivateId{}, privateChildrenIds)
+	return model_server.FromSigGraphAsset(&asset, &node).Node
+}
+
+func assertTrace(
+	t *testing.T,
+	trace *model_server.Trace,
+	depths map[model_server.NodeId]int,
+	edges ...model_server.TraceEdge,
+) {
+	t.Helper()
+
+	if fmt.Sprint(trace.Depths) != fmt.Sprint(depths) {
+		t.Fatalf("expected depths %v, got %v", depths, trace.Depths)
+	}
+	if len(trace.Nodes) != len(depths) {
+		t.Fatalf("expected %d nodes, got %d", len(depths), len(trace.Nodes))
+	}
+	for id := range depths {
+		if node, ok := trace.Nodes[id]; !ok || node.Id != id {
+			t.Fatalf("expected node %s in the trace", id)
+		}
+	}
+
+	sortEdges := func(edges []model_server.TraceEdge) []string {
+		sorted := []string{}
+		for _, edge := range edges {
+			sorted = append(sorted, fmt.Sprintf("%s->%s %s %s", edge.ParentId, edge.ChildId, edge.EdgeType, edge.Hash))
+		}
+		sort.Strings(sorted)
+		return sorted
+	}
+	if fmt.Sprint(sortEdges(trace.Edges)) != fmt.Sprint(sortEdges(edges)) {
+		t.Fatalf("expected edges %v, got %v", sortEdges(edges), sortEdges(trace.Edges))
+	}
+}
+
+func publicTraceEdge(parentId model_server.NodeId, childId model_server.NodeId) model_server.TraceEdge {
+	return model_server.TraceEdge{ParentId: parentId, ChildId: childId, EdgeType: model_server.ETraceEdgeTypePublic}
+}
+
+// a0 -> a1 -> a2 -> a3
+func newTraceTestChain() *traceTestLedger {
+	ledger := newTraceTestLedger("a0", "a1", "a2", "a3")
+	ledger.linkPublic("a0", "a1")
+	ledger.linkPublic("a1", "a2")
+	ledger.linkPublic("a2", "a3")
+	return ledger
+}
+
+func TestTraceNodeDepthLimit(t *testing.T) {
+	cases := []struct {
+		name      string
+		root      model_server.NodeId
+		direction model_server.ETraceDirection
+		maxDepth  int
+		depths    map[model_server.NodeId]int
+		edges     []model_server.TraceEdge
+	}{
+		{"root only", "a1", model_server.ETraceDirectionBoth, 0, map[model_server.NodeId]int{"a1": 0}, nil},
+		{"descendants", "a0", model_server.ETraceDirectionDescendants, 2,
+			map[model_server.NodeId]int{"a0": 0, "a1": 1, "a2": 2},
+			[]model_server.TraceEdge{publicTraceEdge("a0", "a1"), publicTraceEdge("a1", "a2")}},
+		{"ancestors", "a3", model_server.ETraceDirectionAncestors, 1,
+			map[model_server.NodeId]int{"a3": 0, "a2": 1},
+			[]model_server.TraceEdge{publicTraceEdge("a2", "a3")}},
+		{"ancestors beyond the graph", "a3", model_server.ETraceDirectionAncestors, 10,
+			map[model_server.NodeId]int{"a3": 0, "a2": 1, "a1": 2, "a0": 3},
+			[]model_server.TraceEdge{publicTraceEdge("a0", "a1"), publicTraceEdge("a1", "a2"), publicTraceEdge("a2", "a3")}},
+		{"both", "a1", model_server.ETraceDirectionBoth, 1,
+			map[model_server.NodeId]int{"a0": 1, "a1": 0, "a2": 1},
+			[]model_server.TraceEdge{publicTraceEdge("a0", "a1"), publicTraceEdge("a1", "a2")}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service := newTraceTestService(t, newTraceTestChain())
+			trace, err := service.TraceNode(context.Background(), 0, traceTestUser, c.root, c.direction, c.maxDepth, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trace.RootId != c.root {
+				t.Fatalf("expected root %s, got %s", c.root, trace.RootId)
+			}
+			assertTrace(t, trace, c.depths, c.edges...)
+		})
+	}
+}
+
+func TestTraceNodeCycle(t *testing.T) {
+	// c0 -> c1 -> c2 -> c0, the ledger does not allow it but the walk
+	// must end anyway
+	ledger := newTraceTestLedger("c0", "c1", "c2")
+	ledger.linkPublic("c0", "c1")
+	ledger.linkPublic("c1", "c2")
+	ledger.linkPublic("c2", "c0")
+
+	trace, err := newTraceTestService(t, ledger).TraceNode(
+		context.Background(), 0, traceTestUser, "c0", model_server.ETraceDirectionBoth, 50, false,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTrace(
+		t,
+		trace,
+		map[model_server.NodeId]int{"c0": 0, "c1": 1, "c2": 1},
+		publicTraceEdge("c0", "c1"),
+		publicTraceEdge("c1", "c2"),
+		publicTraceEdge("c2", "c0"),
+	)
+	for id, reads := range ledger.reads {
+		if reads != 1 {
+			t.Fatalf("expected node %s to be read once, got %d times", id, reads)
+		}
+	}
+}
+
+func TestTraceNodeShortestDepth(t *testing.T) {
+	// d0 -> d1 -> d2 and d0 -> d2
+	ledger := newTraceTestLedger("d0", "d1", "d2")
+	ledger.linkPublic("d0", "d1")
+	ledger.linkPublic("d1", "d2")
+	ledger.linkPublic("d0", "d2")
+
+	trace, err := newTraceTestService(t, ledger).TraceNode(
+		context.Background(), 0, traceTestUser, "d0", model_server.ETraceDirectionDescendants, 5, false,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTrace(
+		t,
+		trace,
+		map[model_server.NodeId]int{"d0": 0, "d1": 1, "d2": 1},
+		publicTraceEdge("d0", "d1"),
+		publicTraceEdge("d1", "d2"),
+		publicTraceEdge("d0", "d2"),
+	)
+}
+
+func TestTraceNodePrivateEdges(t *testing.T) {
+	// p -> q is private, q is hidden behind hash
+	ledger := newTraceTestLedger("p", "q")
+	ledger.linkPrivate("p", "q", "hash of p", "hash of q")
+	cachedP := cachedTraceTestNode(t, ledger, "p", map[string]model_server.PrivateId{
+		"hash of q": {ThisId: "q", ThisHash: "hash of q", ThisSecret: "secret of q"},
+	})
+
+	t.Run("secret cached", func(t *testing.T) {
+		trace, err := newTraceTestService(t, ledger, cachedP).TraceNode(
+			context.Background(), 0, traceTestUser, "p", model_server.ETraceDirectionDescendants, 5, true,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTrace(
+			t,
+			trace,
+			map[model_server.NodeId]int{"p": 0, "q": 1},
+			model_server.TraceEdge{ParentId: "p", ChildId: "q", EdgeType: model_server.ETraceEdgeTypePrivate, Hash: "hash of q"},
+		)
+	})
+
+	t.Run("cache not used", func(t *testing.T) {
+		trace, err := newTraceTestService(t, ledger, cachedP).TraceNode(
+			context.Background(), 0, traceTestUser, "p", model_server.ETraceDirectionDescendants, 5, false,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTrace(
+			t,
+			trace,
+			map[model_server.NodeId]int{"p": 0},
+			model_server.TraceEdge{ParentId: "p", EdgeType: model_server.ETraceEdgeTypeOpaque, Hash: "hash of q"},
+		)
+	})
+
+	t.Run("secret unknown", func(t *testing.T) {
+		trace, err := newTraceTestService(t, ledger).TraceNode(
+			context.Background(), 0, traceTestUser, "q", model_server.ETraceDirectionAncestors, 5, true,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTrace(
+			t,
+			trace,
+			map[model_server.NodeId]int{"q": 0},
+			model_server.TraceEdge{ChildId: "q", EdgeType: model_server.ETraceEdgeTypeOpaque, Hash: "hash of p"},
+		)
+	})
+}
+
+func TestTraceNodeInvalidArguments(t *testing.T) {
+	service := newTraceTestService(t, newTraceTestChain())
+
+	_, err := service.TraceNode(context.Background(), 0, traceTestUser, "a0", model_server.ETraceDirectionBoth, -1, false)
+	if !errors.Is(err, utility.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for a negative depth, got %v", err)
+	}
+
+	_, err = service.TraceNode(context.Background(), 0, traceTestUser, "a0", "sideways", 1, false)
+	if !errors.Is(err, utility.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for an unknown direction, got %v", err)
+	}
+
+	_, err = service.TraceNode(context.Background(), 0, traceTestUser, "unknown", model_server.ETraceDirectionBoth, 1, false)
+	if !errors.Is(err, utility.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown root, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package service_server
+
+import (
+	"errors"
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	repository_server "sig_graph_scp/pkg/server/repository"
+	api_sig_graph "sig_graph_scp/pkg/sig_graph/api"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sync"
+)
+
+// kind of node cached by the server
+type NodeTypeI interface {
+	// decoder of the ledger json
+	api_sig_graph.NodeTypeI
+
+	// server model of a node decoded by the SigGraph client. modelNode
+	// holds the common fields, the specific model is kept in Extra of the
+	// returned node
+	FromSigGraphNode(sigGraphNode model_sig_graph.NodeI, modelNode *model_server.Node) (model_server.Node, error)
+
+	// cache of the nodes of this type
+	Repository() repository_server.GenericNodeRepositoryI
+}
+
+type NodeTypeRegistryI interface {
+	// also register the decoder to the SigGraph client if it does not
+	// know the node type yet. Return ErrAlreadyExists if a node type with
+	// the same name is registered
+	Register(nodeType NodeTypeI) error
+	// return ErrNotFound if no such node type is registered
+	Get(nodeType model.ENodeType) (NodeTypeI, error)
+}
+
+type nodeTypeRegistry struct {
+	mtx               sync.RWMutex
+	nodeTypes         map[model.ENodeType]NodeTypeI
+	sigGraphNodeTypes api_sig_graph.NodeTypeRegistryI
+}
+
+func NewNodeTypeRegistry(sigGraphNodeTypes api_sig_graph.NodeTypeRegistryI) *nodeTypeRegistry {
+	return &nodeTypeRegistry{
+		nodeTypes:         map[model.ENodeType]NodeTypeI{},
+		sigGraphNodeTypes: sigGraphNodeTypes,
+	}
+}
+
+func (r *nodeTypeRegistry) Register(nodeType NodeTypeI) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.nodeTypes[nodeType.NodeType()]; ok {
+		return fmt.Errorf("%w: node type %s", utility.ErrAlreadyExists, nodeType.NodeType())
+	}
+
+	_, err := r.sigGraphNodeTypes.Get(nodeType.NodeType())
+	if errors.Is(err, utility.ErrNotFound) {
+		err = r.sigGraphNodeTypes.Register(nodeType)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.nodeTypes[nodeType.NodeType()] = nodeType
+	return nil
+}
+
+func (r *nodeTypeRegistry) Get(nodeType model.ENodeType) (NodeTypeI, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	ret, ok := r.nodeTypes[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: node type %s", utility.ErrNotFound, nodeType)
+	}
+	return ret, nil
+}
+
+type assetNodeType struct {
+	api_sig_graph.NodeTypeI
+	repository repository_server.GenericNodeRepositoryI
+}
+
+func NewAssetNodeType(repository repository_server.GenericNodeRepositoryI) *assetNodeType {
+	return &assetNodeType{
+		NodeTypeI:  api_sig_graph.NewAssetNodeType(),
+		repository: repository,
+	}
+}
+
+func (t *assetNodeType) FromSigGraphNode(sigGraphNode model_sig_graph.NodeI, modelNode *model_server.Node) (model_server.Node, error) {
+	asset, ok := sigGraphNode.(model_sig_graph.Asset)
+	if !ok {
+		return model_server.Node{}, fmt.Errorf("%w: node %s is not an asset", utility.ErrInvalidArgument, modelNode.Id)
+	}
+
+	modelAsset := model_server.FromSigGraphAsset(&asset, modelNode)
+	return modelAsset.Node, nil
+}
+
+func (t *assetNodeType) Repository() repository_server.GenericNodeRepositoryI {
+	return t.repository
+}
@@ -0,0 +1,37 @@
+package api_sig_graph
+
+import (
+	"context"
+	"crypto/x509"
+	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+)
+
+// chaincode calls made with ctx are signed by fabricIdentity instead of
+// the identity of the settings
+func WithFabricIdentity(ctx context.Context, fabricIdentity *model_sig_graph.FabricIdentity) context.Context {
+	return service_sig_graph.WithFabricIdentity(ctx, fabricIdentity)
+}
+
+// nil if calls are signed by the identity of the settings
+func FabricIdentityFromContext(ctx context.Context) *model_sig_graph.FabricIdentity {
+	return service_sig_graph.FabricIdentityFromContext(ctx)
+}
+
+// return ErrInvalidArgument if the certificate or the key cannot be parsed
+// or if the key is not the key of the certificate
+func ValidateFabricIdentity(fabricIdentity *model_sig_graph.FabricIdentity) error {
+	_, _, err := service_sig_graph.NewFabricIdentitySign(fabricIdentity)
+	return err
+}
+
+type FabricCaEnrollerI interface {
+	service_sig_graph.FabricCaEnrollerI
+}
+
+// enroll identities of mspId with the Fabric CA at caUrl, e.g.
+// https://localhost:7054. tlsCertificate is the CA certificate of its TLS
+// server, the system roots are used if it is nil
+func NewFabricCaEnroller(caUrl string, caName string, mspId string, tlsCertificate *x509.Certificate) FabricCaEnrollerI {
+	return service_sig_graph.NewFabricCaEnrollerHttp(caUrl, caName, mspId, tlsCertificate)
+}
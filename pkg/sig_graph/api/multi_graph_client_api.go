@@ -0,0 +1,485 @@
+package api_sig_graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	utility_sig_graph "sig_graph_scp/internal/sig_graph/utility"
+	"sig_graph_scp/pkg/model"
+	model_server "sig_graph_scp/pkg/server/model"
+	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
+	"sig_graph_scp/pkg/utility"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// client of several graphs, e.g. channels or networks. Calls on existing
+// nodes go to the graph prefixing their ids, new assets without ingredients
+// are created on the default graph
+type MultiGraphClientApi interface {
+	SigGraphClientApi
+
+	// the first one is the default graph
+	GetGraphNames() []string
+	// return ErrNotFound if the graph is unknown
+	GetGraph(graphName string) (SigGraphClientApi, error)
+}
+
+// client of the graph named by graphUri, on the ledger of its backend.
+// A Hyperledger graph uses the peers and the channel of its uri with the
+// other settings of options
+func NewSigGraphClientApiFromUri(graphUri string, options *Options) (SigGraphClientApi, error) {
+	uri, err := model_sig_graph.ParseGraphUri(graphUri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch uri.Backend {
+	case model.EGraphBackendMemory:
+		return NewAssetClientApiMemory(graphUri, options)
+	case model.EGraphBackendHyperledger:
+		var settings SettingsI
+		if options != nil {
+			settings = options.Settings
+		}
+		if settings == nil {
+			settings = GetGlobalSettings()
+		}
+		if settings == nil {
+			settings, err = NewSettingsFromEnv()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load settings: %w", err)
+			}
+		}
+
+		// peers are host:port
+		peerAddresses := make([]string, 0, len(uri.Endpoints))
+		for _, endpoint := range uri.Endpoints {
+			if strings.Contains(endpoint, "://") {
+				endpointUrl, err := url.Parse(endpoint)
+				if err != nil {
+					return nil, fmt.Errorf("%w: endpoint %s of graph %s: %s", utility.ErrInvalidArgument, endpoint, graphUri, err.Error())
+				}
+				endpoint = endpointUrl.Host
+			}
+			peerAddresses = append(peerAddresses, endpoint)
+		}
+
+		graphOptions := Options{}
+		if options != nil {
+			graphOptions = *options
+		}
+		graphOptions.Settings = utility_sig_graph.NewSettingsOfGraph(settings, peerAddresses, uri.Channel)
+		return NewAssetClientApi(graphUri, &graphOptions)
+	}
+	return nil, fmt.Errorf("%w: graph %s has unknown backend %s", utility.ErrInvalidArgument, graphUri, uri.Backend)
+}
+
+type multiGraphClientApi struct {
+	// default graph
+	defaultGraph SigGraphClientApi
+	graphNames   []string
+	graphs       map[string]SigGraphClientApi
+}
+
+// the first graph is the default one. The graphs should share their
+// unit and node type registries, the ones of the default graph are returned
+func NewMultiGraphClientApi(graphs []SigGraphClientApi) (MultiGraphClientApi, error) {
+	if len(graphs) == 0 {
+		return nil, fmt.Errorf("%w: no graph", utility.ErrInvalidArgument)
+	}
+
+	ret := &multiGraphClientApi{
+		defaultGraph: graphs[0],
+		graphNames:   []string{},
+		graphs:       map[string]SigGraphClientApi{},
+	}
+	for _, graph := range graphs {
+		graphName := graph.GetGraphName()
+		_, err := model_sig_graph.ParseGraphUri(graphName)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ret.graphs[graphName]; ok {
+			return nil, fmt.Errorf("%w: graph %s", utility.ErrAlreadyExists, graphName)
+		}
+
+		ret.graphNames = append(ret.graphNames, graphName)
+		ret.graphs[graphName] = graph
+	}
+	return ret, nil
+}
+
+func (a *multiGraphClientApi) GetGraphNames() []string {
+	return append([]string{}, a.graphNames...)
+}
+
+func (a *multiGraphClientApi) GetGraph(graphName string) (SigGraphClientApi, error) {
+	graph, ok := a.graphs[graphName]
+	if !ok {
+		return nil, fmt.Errorf("%w: graph %s", utility.ErrNotFound, graphName)
+	}
+	return graph, nil
+}
+
+// graph holding the node
+func (a *multiGraphClientApi) graphOfNode(id string) (SigGraphClientApi, error) {
+	graphName, err := model_sig_graph.GraphNameOfNodeId(id)
+	if err != nil {
+		return nil, err
+	}
+	graph, ok := a.graphs[graphName]
+	if !ok {
+		return nil, fmt.Errorf("%w: node %s is on unknown graph %s", utility.ErrNotFound, id, graphName)
+	}
+	return graph, nil
+}
+
+// graph of the ingredients, which must all be on the same graph. The
+// default graph if there is none
+func (a *multiGraphClientApi) graphOfIngredients(ingredients []model_sig_graph.Asset) (SigGraphClientApi, error) {
+	if len(ingredients) == 0 {
+		return a.defaultGraph, nil
+	}
+
+	graphName, err := model_sig_graph.GraphNameOfNodeId(ingredients[0].Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, ingredient := range ingredients[1:] {
+		if !strings.HasPrefix(ingredient.Id, graphName+":") {
+			return nil, fmt.Errorf("%w: ingredients %s and %s are on different graphs", utility.ErrInvalidArgument, ingredients[0].Id, ingredient.Id)
+		}
+	}
+	return a.graphOfNode(ingredients[0].Id)
+}
+
+// ids grouped by the graph holding them, ids of unknown graphs are returned apart
+func (a *multiGraphClientApi) groupByGraph(ids map[string]bool) (map[SigGraphClientApi]map[string]bool, map[string]bool) {
+	groups := map[SigGraphClientApi]map[string]bool{}
+	unknownIds := map[string]bool{}
+	for id := range ids {
+		graph, err := a.graphOfNode(id)
+		if err != nil {
+			unknownIds[id] = true
+			continue
+		}
+		if groups[graph] == nil {
+			groups[graph] = map[string]bool{}
+		}
+		groups[graph][id] = true
+	}
+	return groups, unknownIds
+}
+
+// the new node of a transfer stays on the graph of the asset
+func (a *multiGraphClientApi) graphOfTransfer(asset *model_sig_graph.Asset, newId string) (SigGraphClientApi, error) {
+	graphName, err := model_sig_graph.GraphNameOfNodeId(asset.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(newId, graphName+":") {
+		return nil, fmt.Errorf("%w: new id %s is not on the graph of asset %s", utility.ErrInvalidArgument, newId, asset.Id)
+	}
+	return a.graphOfNode(asset.Id)
+}
+
+func (a *multiGraphClientApi) CreateAsset(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientSecretIds []string,
+	secretIds []string,
+	ingredientSignatures []string,
+) (*model_sig_graph.Asset, error) {
+	graph, err := a.graphOfIngredients(ingredients)
+	if err != nil {
+		return nil, err
+	}
+	return graph.CreateAsset(ctx, materialName, unit, quantity, ownerKey, ingredients, ingredientSecretIds, secretIds, ingredientSignatures)
+}
+
+func (a *multiGraphClientApi) CreateAssetAsync(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientSecretIds []string,
+	secretIds []string,
+	ingredientSignatures []string,
+) (*model_sig_graph.Asset, LedgerTransactionI, error) {
+	graph, err := a.graphOfIngredients(ingredients)
+	if err != nil {
+		return nil, nil, err
+	}
+	return graph.CreateAssetAsync(ctx, materialName, unit, quantity, ownerKey, ingredients, ingredientSecretIds, secretIds, ingredientSignatures)
+}
+
+func (a *multiGraphClientApi) ManufactureAsset(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientKeys []*model_sig_graph.UserKeyPair,
+	ingredientSecretIds []string,
+	secretIds []string,
+) (*model_sig_graph.Asset, []model_sig_graph.Asset, error) {
+	graph, err := a.graphOfIngredients(ingredients)
+	if err != nil {
+		return nil, nil, err
+	}
+	return graph.ManufactureAsset(ctx, materialName, unit, quantity, ownerKey, ingredients, ingredientKeys, ingredientSecretIds, secretIds)
+}
+
+func (a *multiGraphClientApi) ManufactureAssetAsync(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientKeys []*model_sig_graph.UserKeyPair,
+	ingredientSecretIds []string,
+	secretIds []string,
+) (*model_sig_graph.Asset, []model_sig_graph.Asset, LedgerTransactionI, error) {
+	graph, err := a.graphOfIngredients(ingredients)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return graph.ManufactureAssetAsync(ctx, materialName, unit, quantity, ownerKey, ingredients, ingredientKeys, ingredientSecretIds, secretIds)
+}
+
+func (a *multiGraphClientApi) GetAssetById(ctx context.Context, id model_server.NodeId) (*model_sig_graph.Asset, error) {
+	graph, err := a.graphOfNode(string(id))
+	if err != nil {
+		return nil, err
+	}
+	return graph.GetAssetById(ctx, id)
+}
+
+// ids of unknown graphs do not exist
+func (a *multiGraphClientApi) DoNodeIdsExists(ctx context.Context, ids map[string]bool) (map[string]bool, error) {
+	groups, unknownIds := a.groupByGraph(ids)
+
+	ret := map[string]bool{}
+	for id := range unknownIds {
+		ret[id] = false
+	}
+	for graph, graphIds := range groups {
+		exist, err := graph.DoNodeIdsExists(ctx, graphIds)
+		if err != nil {
+			return nil, err
+		}
+		for id := range exist {
+			ret[id] = exist[id]
+		}
+	}
+	return ret, nil
+}
+
+func (a *multiGraphClientApi) TransferAsset(
+	ctx context.Context,
+	time_ms uint64,
+	asset *model_sig_graph.Asset,
+	newOwnerKey *model_sig_graph.UserKeyPair,
+	newId string,
+	newSecret string,
+	currentSecret string,
+	currentSignature string,
+	currentSignatureScheme model.ESignatureScheme,
+) (*model_sig_graph.Asset, *model_sig_graph.Asset, error) {
+	graph, err := a.graphOfTransfer(asset, newId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return graph.TransferAsset(ctx, time_ms, asset, newOwnerKey, newId, newSecret, currentSecret, currentSignature, currentSignatureScheme)
+}
+
+func (a *multiGraphClientApi) TransferAssetAsync(
+	ctx context.Context,
+	time_ms uint64,
+	asset *model_sig_graph.Asset,
+	newOwnerKey *model_sig_graph.UserKeyPair,
+	newId string,
+	newSecret string,
+	currentSecret string,
+	currentSignature string,
+	currentSignatureScheme model.ESignatureScheme,
+) (*model_sig_graph.Asset, *model_sig_graph.Asset, LedgerTransactionI, error) {
+	graph, err := a.graphOfTransfer(asset, newId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return graph.TransferAssetAsync(ctx, time_ms, asset, newOwnerKey, newId, newSecret, currentSecret, currentSignature, currentSignatureScheme)
+}
+
+func (a *multiGraphClientApi) SplitAsset(
+	ctx context.Context,
+	asset *model_sig_graph.Asset,
+	ownerKey *model_sig_graph.UserKeyPair,
+	quantities []decimal.Decimal,
+	childrenSecretIds []string,
+	parentSecretIds []string,
+) (*model_sig_graph.Asset, []model_sig_graph.Asset, error) {
+	graph, err := a.graphOfNode(asset.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return graph.SplitAsset(ctx, asset, ownerKey, quantities, childrenSecretIds, parentSecretIds)
+}
+
+func (a *multiGraphClientApi) SplitAssetAsync(
+	ctx context.Context,
+	asset *model_sig_graph.Asset,
+	ownerKey *model_sig_graph.UserKeyPair,
+	quantities []decimal.Decimal,
+	childrenSecretIds []string,
+	parentSecretIds []string,
+) (*model_sig_graph.Asset, []model_sig_graph.Asset, LedgerTransactionI, error) {
+	graph, err := a.graphOfNode(asset.Id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return graph.SplitAssetAsync(ctx, asset, ownerKey, quantities, childrenSecretIds, parentSecretIds)
+}
+
+// name of the default graph
+func (a *multiGraphClientApi) GetGraphName() string {
+	return a.defaultGraph.GetGraphName()
+}
+
+func (a *multiGraphClientApi) GetUnitRegistry() UnitRegistryI {
+	return a.defaultGraph.GetUnitRegistry()
+}
+
+func (a *multiGraphClientApi) GetNodeTypeRegistry() NodeTypeRegistryI {
+	return a.defaultGraph.GetNodeTypeRegistry()
+}
+
+// sum of the stats of the graphs
+func (a *multiGraphClientApi) GetNodeCacheStats() model_sig_graph.NodeCacheStats {
+	ret := model_sig_graph.NodeCacheStats{}
+	for _, graphName := range a.graphNames {
+		stats := a.graphs[graphName].GetNodeCacheStats()
+		ret.Hits += stats.Hits
+		ret.Misses += stats.Misses
+		ret.CollapsedLookups += stats.CollapsedLookups
+		ret.Evictions += stats.Evictions
+		ret.Expirations += stats.Expirations
+		ret.Size += stats.Size
+	}
+	return ret
+}
+
+// return NotFound if any one id is not found or is on an unknown graph
+func (a *multiGraphClientApi) FetchNodesByIds(ctx context.Context, ids map[string]bool) (map[string]any, error) {
+	groups, unknownIds := a.groupByGraph(ids)
+	if len(unknownIds) > 0 {
+		sortedIds := make([]string, 0, len(unknownIds))
+		for id := range unknownIds {
+			sortedIds = append(sortedIds, id)
+		}
+		sort.Strings(sortedIds)
+		return nil, fmt.Errorf("%w: nodes %s are on unknown graphs", utility.ErrNotFound, strings.Join(sortedIds, ", "))
+	}
+
+	ret := map[string]any{}
+	for graph, graphIds := range groups {
+		nodes, err := graph.FetchNodesByIds(ctx, graphIds)
+		if err != nil {
+			return nil, err
+		}
+		for id := range nodes {
+			ret[id] = nodes[id]
+		}
+	}
+	return ret, nil
+}
+
+func (a *multiGraphClientApi) FetchNodesJsonByIds(ctx context.Context, ids map[string]bool) (map[string]json.RawMessage, error) {
+	groups, unknownIds := a.groupByGraph(ids)
+	if len(unknownIds) > 0 {
+		sortedIds := make([]string, 0, len(unknownIds))
+		for id := range unknownIds {
+			sortedIds = append(sortedIds, id)
+		}
+		sort.Strings(sortedIds)
+		return nil, fmt.Errorf("%w: nodes %s are on unknown graphs", utility.ErrNotFound, strings.Join(sortedIds, ", "))
+	}
+
+	ret := map[string]json.RawMessage{}
+	for graph, graphIds := range groups {
+		nodesJson, err := graph.FetchNodesJsonByIds(ctx, graphIds)
+		if err != nil {
+			return nil, err
+		}
+		for id := range nodesJson {
+			ret[id] = nodesJson[id]
+		}
+	}
+	return ret, nil
+}
+
+func (a *multiGraphClientApi) VerifyNodeSignature(ctx context.Context, publicKey string, node any, signature string) error {
+	return a.defaultGraph.VerifyNodeSignature(ctx, publicKey, node, signature)
+}
+
+// transaction ids do not name their graph, look the transaction up on each
+func (a *multiGraphClientApi) GetLedgerTransactionStatus(ctx context.Context, transactionId string) error {
+	for _, graphName := range a.graphNames {
+		err := a.graphs[graphName].GetLedgerTransactionStatus(ctx, transactionId)
+		if !errors.Is(err, utility.ErrNotFound) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: transaction %s", utility.ErrNotFound, transactionId)
+}
+
+// events of all the graphs, the channel is closed once the streams of all
+// the graphs are closed
+func (a *multiGraphClientApi) SubscribeNodeEvents(ctx context.Context) (<-chan model_sig_graph.NodeEvent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	streams := []<-chan model_sig_graph.NodeEvent{}
+	for _, graphName := range a.graphNames {
+		events, err := a.graphs[graphName].SubscribeNodeEvents(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		streams = append(streams, events)
+	}
+
+	ret := make(chan model_sig_graph.NodeEvent)
+	wg := sync.WaitGroup{}
+	for _, events := range streams {
+		wg.Add(1)
+		go func(events <-chan model_sig_graph.NodeEvent) {
+			defer wg.Done()
+			for event := range events {
+				select {
+				case ret <- event:
+				case <-ctx.Done():
+				}
+			}
+		}(events)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(ret)
+	}()
+	return ret, nil
+}
@@ -0,0 +1,25 @@
+package api_sig_graph
+
+import (
+	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
+	"sig_graph_scp/pkg/model"
+)
+
+type NodeIdValidatorI interface {
+	service_sig_graph.NodeIdValidatorI
+}
+
+// validate ids of nodes on graphNames in the given formats. Ids of any graph
+// are accepted if graphNames is empty, of any format if formats is empty
+func NewNodeIdValidator(graphNames []string, formats []model.ENodeIdFormat) NodeIdValidatorI {
+	return service_sig_graph.NewNodeIdValidator(graphNames, formats)
+}
+
+// validate ids of nodes on the graphs of client, in any format
+func NewNodeIdValidatorOfClient(client SigGraphClientApi) NodeIdValidatorI {
+	graphNames := []string{client.GetGraphName()}
+	if multiGraphClient, ok := client.(MultiGraphClientApi); ok {
+		graphNames = multiGraphClient.GetGraphNames()
+	}
+	return NewNodeIdValidator(graphNames, nil)
+}
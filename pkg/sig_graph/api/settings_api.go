@@ -6,10 +6,27 @@ type SettingsI interface {
 	utility_sig_graph.SettingsI
 }
 
+type ConnectionProfileOptions = utility_sig_graph.ConnectionProfileOptions
+
 func SetGlobalSettings(iSettings SettingsI) {
 	utility_sig_graph.SetGlobalSettings(iSettings)
 }
 
+// return nil if SetGlobalSettings has not been called
 func GetGlobalSettings() SettingsI {
 	return utility_sig_graph.GetGlobalSettings()
 }
+
+func NewSettingsFromEnv() (SettingsI, error) {
+	return utility_sig_graph.NewSettingsFromEnv()
+}
+
+// read settings from a .yaml, .yml or .json file
+func NewSettingsFromFile(path string) (SettingsI, error) {
+	return utility_sig_graph.NewSettingsFromFile(path)
+}
+
+// read settings from a Hyperledger Fabric connection profile
+func NewSettingsFromConnectionProfile(path string, options ConnectionProfileOptions) (SettingsI, error) {
+	return utility_sig_graph.NewSettingsFromConnectionProfile(path, options)
+}
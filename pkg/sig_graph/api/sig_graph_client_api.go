@@ -2,16 +2,74 @@ package api_sig_graph
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	service_sig_graph "sig_graph_scp/internal/sig_graph/service"
+	"sig_graph_scp/pkg/model"
 	model_server "sig_graph_scp/pkg/server/model"
 	model_sig_graph "sig_graph_scp/pkg/sig_graph/model"
 	"sig_graph_scp/pkg/utility"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
 
+type NodeEventSourceI interface {
+	// stream node changes committed on the ledger after the call.
+	// The channel is closed once ctx is done
+	SubscribeNodeEvents(ctx context.Context) (<-chan model_sig_graph.NodeEvent, error)
+}
+
+// transaction sent to the ledger whose commit may still be pending
+type LedgerTransactionI interface {
+	service_sig_graph.LedgerTransactionI
+}
+
+// units of measure of asset quantities
+type UnitRegistryI interface {
+	service_sig_graph.UnitRegistryI
+}
+
+// registry of the common mass (kg), volume (l) and count (pcs) units
+func NewUnitRegistry() UnitRegistryI {
+	return service_sig_graph.NewUnitRegistry()
+}
+
+// kind of node stored on the ledger
+type NodeTypeI interface {
+	service_sig_graph.NodeTypeI
+}
+
+// node types that the client decodes
+type NodeTypeRegistryI interface {
+	service_sig_graph.NodeTypeRegistryI
+}
+
+// registry of the built-in node types
+func NewNodeTypeRegistry() NodeTypeRegistryI {
+	return service_sig_graph.NewNodeTypeRegistry()
+}
+
+// decode assets, registered in NewNodeTypeRegistry
+func NewAssetNodeType() NodeTypeI {
+	return service_sig_graph.NewAssetNodeType()
+}
+
+// verify proof bundles offline
+type ProofBundleVerifierI interface {
+	service_sig_graph.ProofBundleVerifierI
+}
+
+func NewProofBundleVerifier() ProofBundleVerifierI {
+	return service_sig_graph.NewProofBundleVerifier(
+		service_sig_graph.NewNodeVerifyingService(),
+		utility.NewHashedIdGeneratorService(),
+	)
+}
+
 type SigGraphClientApi interface {
+	NodeEventSourceI
+
 	CreateAsset(
 		ctx context.Context,
 		materialName string,
@@ -23,6 +81,46 @@ type SigGraphClientApi interface {
 		secretIds []string,
 		ingredientSignatures []string,
 	) (*model_sig_graph.Asset, error)
+	// same as CreateAsset but return as soon as the transaction is endorsed.
+	// The asset is the endorsed result, it is on the ledger once
+	// the transaction is committed
+	CreateAssetAsync(
+		ctx context.Context,
+		materialName string,
+		unit string,
+		quantity decimal.Decimal,
+		ownerKey *model_sig_graph.UserKeyPair,
+		ingredients []model_sig_graph.Asset,
+		ingredientSecretIds []string,
+		secretIds []string,
+		ingredientSignatures []string,
+	) (*model_sig_graph.Asset, LedgerTransactionI, error)
+	// same as CreateAsset but sign the finalized ingredients with
+	// ingredientKeys instead of taking their signatures. Also return the
+	// ingredients as finalized by the transaction
+	ManufactureAsset(
+		ctx context.Context,
+		materialName string,
+		unit string,
+		quantity decimal.Decimal,
+		ownerKey *model_sig_graph.UserKeyPair,
+		ingredients []model_sig_graph.Asset,
+		ingredientKeys []*model_sig_graph.UserKeyPair,
+		ingredientSecretIds []string,
+		secretIds []string,
+	) (asset *model_sig_graph.Asset, updatedIngredients []model_sig_graph.Asset, err error)
+	// same as ManufactureAsset but return as soon as the transaction is endorsed
+	ManufactureAssetAsync(
+		ctx context.Context,
+		materialName string,
+		unit string,
+		quantity decimal.Decimal,
+		ownerKey *model_sig_graph.UserKeyPair,
+		ingredients []model_sig_graph.Asset,
+		ingredientKeys []*model_sig_graph.UserKeyPair,
+		ingredientSecretIds []string,
+		secretIds []string,
+	) (asset *model_sig_graph.Asset, updatedIngredients []model_sig_graph.Asset, transaction LedgerTransactionI, err error)
 	GetAssetById(ctx context.Context, Id model_server.NodeId) (*model_sig_graph.Asset, error)
 	DoNodeIdsExists(ctx context.Context, ids map[string]bool) (map[string]bool, error)
 	TransferAsset(
@@ -34,37 +132,250 @@ type SigGraphClientApi interface {
 		newSecret string,
 		currentSecret string,
 		currentSignature string,
+		currentSignatureScheme model.ESignatureScheme,
 	) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, err error)
+	// same as TransferAsset but return as soon as the transaction is endorsed
+	TransferAssetAsync(
+		ctx context.Context,
+		time_ms uint64,
+		asset *model_sig_graph.Asset,
+		newOwnerKey *model_sig_graph.UserKeyPair,
+		newId string,
+		newSecret string,
+		currentSecret string,
+		currentSignature string,
+		currentSignatureScheme model.ESignatureScheme,
+	) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, transaction LedgerTransactionI, err error)
+	// finalize asset and create one child per quantity, owned by ownerKey
+	// as well. The quantities must add up to the quantity of asset.
+	// childrenSecretIds hide the children in asset and parentSecretIds
+	// hide asset in each child, an empty secret makes a public edge
+	SplitAsset(
+		ctx context.Context,
+		asset *model_sig_graph.Asset,
+		ownerKey *model_sig_graph.UserKeyPair,
+		quantities []decimal.Decimal,
+		childrenSecretIds []string,
+		parentSecretIds []string,
+	) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, err error)
+	// same as SplitAsset but return as soon as the transaction is endorsed
+	SplitAssetAsync(
+		ctx context.Context,
+		asset *model_sig_graph.Asset,
+		ownerKey *model_sig_graph.UserKeyPair,
+		quantities []decimal.Decimal,
+		childrenSecretIds []string,
+		parentSecretIds []string,
+	) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, transaction LedgerTransactionI, err error)
 	GetGraphName() string
+	// units that assets are validated against
+	GetUnitRegistry() UnitRegistryI
+	// node types that FetchNodesByIds decodes
+	GetNodeTypeRegistry() NodeTypeRegistryI
+	// zero if Options.NodeCache is not set
+	GetNodeCacheStats() model_sig_graph.NodeCacheStats
 
 	// return NotFound if any one id is not found
 	FetchNodesByIds(ctx context.Context, ids map[string]bool) (map[string]any, error)
+	// ledger json of the nodes byte for byte, as signed by their owners. The
+	// signatures are not verified. Return NotFound if any one id is not found
+	FetchNodesJsonByIds(ctx context.Context, ids map[string]bool) (map[string]json.RawMessage, error)
+
+	// return ErrInvalidSignature if signature is not a signature of node by publicKey
+	VerifyNodeSignature(ctx context.Context, publicKey string, node any, signature string) error
+
+	// nil if the transaction with the id of LedgerTransactionI.Id is
+	// committed, ErrTransactionFailed if the ledger marked it as invalid and
+	// ErrNotFound if it is not on the ledger (yet)
+	GetLedgerTransactionStatus(ctx context.Context, transactionId string) error
+}
+
+type Options struct {
+	// settings of the Hyperledger network, default to the global settings
+	// or, if they are not set, to NewSettingsFromEnv
+	Settings SettingsI
+
+	// verify the signature of every node returned by the ledger,
+	// nodes with an invalid signature are rejected with ErrInvalidSignature
+	VerifyNodeSignatures bool
+
+	// units that assets are created, split and transferred with, default
+	// to NewUnitRegistry. Units are stored with their symbol
+	UnitRegistry UnitRegistryI
+	// keep unknown units as they are instead of rejecting them with
+	// ErrInvalidArgument, for assets created before the unit registry
+	AllowUnknownUnits bool
+
+	// node types that FetchNodesByIds decodes, default to NewNodeTypeRegistry
+	NodeTypes NodeTypeRegistryI
+
+	// cache the nodes read by FetchNodesByIds and GetAssetById, no cache if nil
+	NodeCache *NodeCacheOptions
+
+	// retry smart contract calls failing with ErrTransient, no retry if nil
+	Retry *RetryOptions
+
+	// format of the ids of created nodes, default to uuid v4
+	IdFormat model.ENodeIdFormat
+}
+
+type NodeCacheOptions struct {
+	// maximum number of cached nodes, default to 10000
+	Capacity int
+	// how long nodes that are not finalized stay cached, default to 5s.
+	// Finalized nodes never change and stay until they are evicted
+	Ttl time.Duration
+}
+
+// transactions creating nodes are resubmitted only if their nodes are not on
+// the ledger with our signatures, a committed transaction whose response was
+// lost succeeds. Other transactions are not retried
+type RetryOptions struct {
+	// attempts including the first one, default to 3
+	MaxAttempts int
+	// backoff after the first attempt, doubled after every attempt. Default to 200ms
+	InitialBackoff time.Duration
+	// default to 5s
+	MaxBackoff time.Duration
 }
 
 type sigGraphClientApi struct {
-	assetService service_sig_graph.AssetServiceI
-	nodeService  service_sig_graph.NodeServiceI
-	graphName    string
+	assetService     service_sig_graph.AssetServiceI
+	nodeService      service_sig_graph.NodeServiceI
+	verifyingService service_sig_graph.NodeVerifyingServiceI
+	eventSource      service_sig_graph.NodeEventSourceI
+	statusService    service_sig_graph.LedgerTransactionStatusServiceI
+	unitRegistry     UnitRegistryI
+	nodeTypes        NodeTypeRegistryI
+	// nil if nodes are not cached
+	nodeCache            service_sig_graph.NodeCacheI
+	verifyNodeSignatures bool
+	graphName            string
 }
 
-func NewAssetClientApi(graphName string) (SigGraphClientApi, error) {
-	assetSmartContractService, err := service_sig_graph.NewAssetSmartContractServiceHyperledger()
+func NewAssetClientApi(graphName string, options *Options) (SigGraphClientApi, error) {
+	var settings SettingsI
+	if options != nil {
+		settings = options.Settings
+	}
+	if settings == nil {
+		settings = GetGlobalSettings()
+	}
+	if settings == nil {
+		var err error
+		settings, err = NewSettingsFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load settings: %w", err)
+		}
+	}
+
+	peerPool, err := service_sig_graph.NewFabricPeerPoolFromSettings(settings)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize asset smart contract service: %w", err)
+		return nil, fmt.Errorf("failed to connect to hyperledger peers: %w", err)
 	}
 
-	nodeSmartContractService, err := service_sig_graph.NewNodeSmartContractServiceHyperledger()
+	assetSmartContractService := service_sig_graph.NewAssetSmartContractServiceHyperledger(peerPool, settings)
+	nodeSmartContractService := service_sig_graph.NewNodeSmartContractServiceHyperledger(peerPool, settings)
+	eventSource := service_sig_graph.NewNodeEventSourceHyperledger(peerPool, settings)
+	statusService := service_sig_graph.NewLedgerTransactionStatusServiceHyperledger(peerPool, settings)
+
+	api, err := newSigGraphClientApi(graphName, options, assetSmartContractService, nodeSmartContractService, eventSource, statusService)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize node smart contract service: %w", err)
+		peerPool.Close()
+		return nil, err
 	}
+	return api, nil
+}
+
+// same as NewAssetClientApi but backed by an in-memory ledger instead of
+// a Hyperledger network. Each call creates a new, empty ledger.
+func NewAssetClientApiMemory(graphName string, options *Options) (SigGraphClientApi, error) {
+	smartContractService := service_sig_graph.NewSmartContractServiceMemory(utility.NewHashedIdGeneratorService())
+	return newSigGraphClientApi(graphName, options, smartContractService, smartContractService, smartContractService, smartContractService)
+}
 
+func newSigGraphClientApi(
+	graphName string,
+	options *Options,
+	assetSmartContractService service_sig_graph.SmartContractServiceI,
+	nodeSmartContractService service_sig_graph.SmartContractServiceI,
+	eventSource service_sig_graph.NodeEventSourceI,
+	statusService service_sig_graph.LedgerTransactionStatusServiceI,
+) (*sigGraphClientApi, error) {
 	nodeSigningService := service_sig_graph.NewNodeSigningService()
-	idGeneratorService := service_sig_graph.NewIdGenerateServiceUuid(graphName)
 	clockWall := utility.NewClockWall()
 	hashGenerator := utility.NewHashedIdGeneratorService()
 	cloner := utility.NewCloner()
 
-	nodeSigGraphService := service_sig_graph.NewNodeService(nodeSmartContractService)
+	var unitRegistry UnitRegistryI
+	var nodeTypes NodeTypeRegistryI
+	var nodeCacheOptions *NodeCacheOptions
+	var retryOptions *RetryOptions
+	verifyNodeSignatures := false
+	allowUnknownUnits := false
+	idFormat := model.ENodeIdFormatUuidV4
+	if options != nil {
+		unitRegistry = options.UnitRegistry
+		nodeTypes = options.NodeTypes
+		nodeCacheOptions = options.NodeCache
+		retryOptions = options.Retry
+		verifyNodeSignatures = options.VerifyNodeSignatures
+		allowUnknownUnits = options.AllowUnknownUnits
+		if options.IdFormat != "" {
+			idFormat = options.IdFormat
+		}
+	}
+	idGeneratorService, err := service_sig_graph.NewIdGenerateService(idFormat, graphName, clockWall)
+	if err != nil {
+		return nil, err
+	}
+	if unitRegistry == nil {
+		unitRegistry = NewUnitRegistry()
+	}
+	if nodeTypes == nil {
+		nodeTypes = NewNodeTypeRegistry()
+	}
+
+	if retryOptions != nil {
+		policy := service_sig_graph.RetryPolicy{
+			MaxAttempts:    retryOptions.MaxAttempts,
+			InitialBackoff: retryOptions.InitialBackoff,
+			MaxBackoff:     retryOptions.MaxBackoff,
+		}
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = 3
+		}
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = 200 * time.Millisecond
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = 5 * time.Second
+		}
+
+		assetSmartContractService = service_sig_graph.NewSmartContractServiceRetry(assetSmartContractService, policy)
+		nodeSmartContractService = service_sig_graph.NewSmartContractServiceRetry(nodeSmartContractService, policy)
+	}
+
+	nodeSigGraphService := service_sig_graph.NewNodeService(nodeSmartContractService, nodeTypes)
+	var nodeCache service_sig_graph.NodeCacheI
+	if nodeCacheOptions != nil {
+		capacity := nodeCacheOptions.Capacity
+		if capacity <= 0 {
+			capacity = 10000
+		}
+		ttl := nodeCacheOptions.Ttl
+		if ttl <= 0 {
+			ttl = 5 * time.Second
+		}
+
+		nodeCache = service_sig_graph.NewNodeCacheLru(clockWall, capacity, ttl)
+		nodeSigGraphService = service_sig_graph.NewNodeServiceCached(
+			nodeSigGraphService,
+			nodeCache,
+			nodeTypes,
+			utility.NewSingleFlight(),
+		)
+	}
 	assetSigGraphService := service_sig_graph.NewAssetService(
 		assetSmartContractService,
 		clockWall,
@@ -72,11 +383,20 @@ func NewAssetClientApi(graphName string) (SigGraphClientApi, error) {
 		nodeSigningService,
 		hashGenerator,
 		cloner,
+		unitRegistry,
+		allowUnknownUnits,
 	)
 	return &sigGraphClientApi{
-		assetService: assetSigGraphService,
-		nodeService:  nodeSigGraphService,
-		graphName:    graphName,
+		assetService:         assetSigGraphService,
+		nodeService:          nodeSigGraphService,
+		verifyingService:     service_sig_graph.NewNodeVerifyingService(),
+		eventSource:          eventSource,
+		statusService:        statusService,
+		unitRegistry:         unitRegistry,
+		nodeTypes:            nodeTypes,
+		nodeCache:            nodeCache,
+		verifyNodeSignatures: verifyNodeSignatures,
+		graphName:            graphName,
 	}, nil
 }
 
@@ -84,12 +404,127 @@ func (a *sigGraphClientApi) GetGraphName() string {
 	return a.graphName
 }
 
+func (a *sigGraphClientApi) GetUnitRegistry() UnitRegistryI {
+	return a.unitRegistry
+}
+
+func (a *sigGraphClientApi) GetNodeTypeRegistry() NodeTypeRegistryI {
+	return a.nodeTypes
+}
+
+func (a *sigGraphClientApi) GetNodeCacheStats() model_sig_graph.NodeCacheStats {
+	if a.nodeCache == nil {
+		return model_sig_graph.NodeCacheStats{}
+	}
+	return a.nodeCache.Stats()
+}
+
+// drop nodes changed by a transaction of this client. Other clients'
+// changes are seen once the ttl expires
+func (a *sigGraphClientApi) invalidateCachedNodes(ids ...string) {
+	if a.nodeCache == nil {
+		return
+	}
+	for _, id := range ids {
+		a.nodeCache.Invalidate(id)
+	}
+}
+
+// drop the nodes once transaction is committed, a lookup before the commit
+// would cache them again as they were
+func (a *sigGraphClientApi) invalidateCachedNodesOnCommit(transaction LedgerTransactionI, ids ...string) LedgerTransactionI {
+	if a.nodeCache == nil {
+		return transaction
+	}
+	return &cacheInvalidatingTransaction{
+		LedgerTransactionI: transaction,
+		invalidate: func() {
+			a.invalidateCachedNodes(ids...)
+		},
+	}
+}
+
+type cacheInvalidatingTransaction struct {
+	LedgerTransactionI
+	invalidate func()
+}
+
+func (t *cacheInvalidatingTransaction) WaitForCommit(ctx context.Context) error {
+	err := t.LedgerTransactionI.WaitForCommit(ctx)
+	if err == nil {
+		t.invalidate()
+	}
+	return err
+}
+
+func ingredientIds(ingredients []model_sig_graph.Asset) []string {
+	ids := make([]string, 0, len(ingredients))
+	for i := range ingredients {
+		ids = append(ids, ingredients[i].Id)
+	}
+	return ids
+}
+
 func (a *sigGraphClientApi) DoNodeIdsExists(ctx context.Context, ids map[string]bool) (map[string]bool, error) {
 	return a.nodeService.DoNodeIdsExists(ctx, ids)
 }
 
 func (a *sigGraphClientApi) FetchNodesByIds(ctx context.Context, ids map[string]bool) (map[string]any, error) {
-	return a.nodeService.FetchNodesByIds(ctx, ids)
+	nodes, err := a.nodeService.FetchNodesByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for id := range nodes {
+		err = a.verifyNode(ctx, nodes[id])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+func (a *sigGraphClientApi) FetchNodesJsonByIds(ctx context.Context, ids map[string]bool) (map[string]json.RawMessage, error) {
+	return a.nodeService.FetchNodesJsonByIds(ctx, ids)
+}
+
+func (a *sigGraphClientApi) SubscribeNodeEvents(ctx context.Context) (<-chan model_sig_graph.NodeEvent, error) {
+	events, err := a.eventSource.Subscribe(ctx)
+	if err != nil || a.nodeCache == nil {
+		return events, err
+	}
+
+	// changed nodes are refetched by the subscriber, drop them first
+	invalidatedEvents := make(chan model_sig_graph.NodeEvent)
+	go func() {
+		defer close(invalidatedEvents)
+		for event := range events {
+			a.invalidateCachedNodes(event.NodeId)
+			select {
+			case invalidatedEvents <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return invalidatedEvents, nil
+}
+
+func (a *sigGraphClientApi) VerifyNodeSignature(ctx context.Context, publicKey string, node any, signature string) error {
+	return a.verifyingService.Verify(ctx, publicKey, node, signature)
+}
+
+func (a *sigGraphClientApi) GetLedgerTransactionStatus(ctx context.Context, transactionId string) error {
+	return a.statusService.GetTransactionStatus(ctx, transactionId)
+}
+
+func (a *sigGraphClientApi) verifyNode(ctx context.Context, node any) error {
+	if !a.verifyNodeSignatures {
+		return nil
+	}
+
+	return a.verifyingService.VerifyNode(ctx, node)
 }
 
 func (a *sigGraphClientApi) CreateAsset(
@@ -103,7 +538,42 @@ func (a *sigGraphClientApi) CreateAsset(
 	secretIds []string,
 	ingredientSignatures []string,
 ) (*model_sig_graph.Asset, error) {
-	return a.assetService.CreateAsset(
+	asset, err := a.assetService.CreateAsset(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		ownerKey,
+		ingredients,
+		ingredientSecretIds,
+		secretIds,
+		ingredientSignatures,
+	)
+	if err != nil {
+		return nil, err
+	}
+	a.invalidateCachedNodes(ingredientIds(ingredients)...)
+
+	err = a.verifyNode(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+func (a *sigGraphClientApi) CreateAssetAsync(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientSecretIds []string,
+	secretIds []string,
+	ingredientSignatures []string,
+) (*model_sig_graph.Asset, LedgerTransactionI, error) {
+	asset, transaction, err := a.assetService.CreateAssetAsync(
 		ctx,
 		materialName,
 		unit,
@@ -114,10 +584,118 @@ func (a *sigGraphClientApi) CreateAsset(
 		secretIds,
 		ingredientSignatures,
 	)
+	if err != nil {
+		return nil, nil, err
+	}
+	transaction = a.invalidateCachedNodesOnCommit(transaction, ingredientIds(ingredients)...)
+
+	err = a.verifyNode(ctx, asset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return asset, transaction, nil
+}
+
+func (a *sigGraphClientApi) ManufactureAsset(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientKeys []*model_sig_graph.UserKeyPair,
+	ingredientSecretIds []string,
+	secretIds []string,
+) (asset *model_sig_graph.Asset, updatedIngredients []model_sig_graph.Asset, err error) {
+	asset, updatedIngredients, err = a.assetService.ManufactureAsset(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		ownerKey,
+		ingredients,
+		ingredientKeys,
+		ingredientSecretIds,
+		secretIds,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.invalidateCachedNodes(ingredientIds(ingredients)...)
+
+	err = a.verifyNode(ctx, asset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return asset, updatedIngredients, nil
+}
+
+func (a *sigGraphClientApi) ManufactureAssetAsync(
+	ctx context.Context,
+	materialName string,
+	unit string,
+	quantity decimal.Decimal,
+	ownerKey *model_sig_graph.UserKeyPair,
+	ingredients []model_sig_graph.Asset,
+	ingredientKeys []*model_sig_graph.UserKeyPair,
+	ingredientSecretIds []string,
+	secretIds []string,
+) (asset *model_sig_graph.Asset, updatedIngredients []model_sig_graph.Asset, transaction LedgerTransactionI, err error) {
+	asset, updatedIngredients, transaction, err = a.assetService.ManufactureAssetAsync(
+		ctx,
+		materialName,
+		unit,
+		quantity,
+		ownerKey,
+		ingredients,
+		ingredientKeys,
+		ingredientSecretIds,
+		secretIds,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	transaction = a.invalidateCachedNodesOnCommit(transaction, ingredientIds(ingredients)...)
+
+	err = a.verifyNode(ctx, asset)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return asset, updatedIngredients, transaction, nil
 }
 
 func (a *sigGraphClientApi) GetAssetById(ctx context.Context, Id model_server.NodeId) (*model_sig_graph.Asset, error) {
-	return a.assetService.GetAssetById(ctx, string(Id))
+	asset, err := a.getAssetById(ctx, string(Id))
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.verifyNode(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+func (a *sigGraphClientApi) getAssetById(ctx context.Context, id string) (*model_sig_graph.Asset, error) {
+	if a.nodeCache == nil {
+		return a.assetService.GetAssetById(ctx, id)
+	}
+
+	nodes, err := a.nodeService.FetchNodesByIds(ctx, map[string]bool{id: true})
+	if err != nil {
+		return nil, err
+	}
+
+	asset, ok := nodes[id].(model_sig_graph.Asset)
+	if !ok {
+		return nil, fmt.Errorf("%w: node %s is not an asset", utility.ErrNotFound, id)
+	}
+	return &asset, nil
 }
 
 func (a *sigGraphClientApi) TransferAsset(
@@ -129,8 +707,9 @@ func (a *sigGraphClientApi) TransferAsset(
 	newSecret string,
 	currentSecret string,
 	currentSignature string,
+	currentSignatureScheme model.ESignatureScheme,
 ) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, err error) {
-	return a.assetService.TransferAsset(
+	updatedCurrentAsset, newAsset, err = a.assetService.TransferAsset(
 		ctx,
 		time_ms,
 		asset,
@@ -139,5 +718,114 @@ func (a *sigGraphClientApi) TransferAsset(
 		newSecret,
 		currentSecret,
 		currentSignature,
+		currentSignatureScheme,
 	)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.invalidateCachedNodes(asset.Id)
+
+	err = a.verifyNode(ctx, newAsset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updatedCurrentAsset, newAsset, nil
+}
+
+func (a *sigGraphClientApi) TransferAssetAsync(
+	ctx context.Context,
+	time_ms uint64,
+	asset *model_sig_graph.Asset,
+	newOwnerKey *model_sig_graph.UserKeyPair,
+	newId string,
+	newSecret string,
+	currentSecret string,
+	currentSignature string,
+	currentSignatureScheme model.ESignatureScheme,
+) (updatedCurrentAsset *model_sig_graph.Asset, newAsset *model_sig_graph.Asset, transaction LedgerTransactionI, err error) {
+	updatedCurrentAsset, newAsset, transaction, err = a.assetService.TransferAssetAsync(
+		ctx,
+		time_ms,
+		asset,
+		newOwnerKey,
+		newId,
+		newSecret,
+		currentSecret,
+		currentSignature,
+		currentSignatureScheme,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	transaction = a.invalidateCachedNodesOnCommit(transaction, asset.Id)
+
+	err = a.verifyNode(ctx, newAsset)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return updatedCurrentAsset, newAsset, transaction, nil
+}
+
+func (a *sigGraphClientApi) SplitAsset(
+	ctx context.Context,
+	asset *model_sig_graph.Asset,
+	ownerKey *model_sig_graph.UserKeyPair,
+	quantities []decimal.Decimal,
+	childrenSecretIds []string,
+	parentSecretIds []string,
+) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, err error) {
+	updatedAsset, children, err = a.assetService.SplitAsset(
+		ctx,
+		asset,
+		ownerKey,
+		quantities,
+		childrenSecretIds,
+		parentSecretIds,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.invalidateCachedNodes(asset.Id)
+
+	for i := range children {
+		err = a.verifyNode(ctx, children[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return updatedAsset, children, nil
+}
+
+func (a *sigGraphClientApi) SplitAssetAsync(
+	ctx context.Context,
+	asset *model_sig_graph.Asset,
+	ownerKey *model_sig_graph.UserKeyPair,
+	quantities []decimal.Decimal,
+	childrenSecretIds []string,
+	parentSecretIds []string,
+) (updatedAsset *model_sig_graph.Asset, children []model_sig_graph.Asset, transaction LedgerTransactionI, err error) {
+	updatedAsset, children, transaction, err = a.assetService.SplitAssetAsync(
+		ctx,
+		asset,
+		ownerKey,
+		quantities,
+		childrenSecretIds,
+		parentSecretIds,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	transaction = a.invalidateCachedNodesOnCommit(transaction, asset.Id)
+
+	for i := range children {
+		err = a.verifyNode(ctx, children[i])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return updatedAsset, children, transaction, nil
 }
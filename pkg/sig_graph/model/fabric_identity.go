@@ -0,0 +1,11 @@
+package model_sig_graph
+
+// Fabric client identity signing the chaincode calls of a user or an
+// organization instead of the identity of the settings
+type FabricIdentity struct {
+	MspId string `json:"msp_id"`
+	// pem encoded x509 certificate
+	Certificate string `json:"certificate"`
+	// pem encoded private key of the certificate
+	PrivateKey string `json:"private_key"`
+}
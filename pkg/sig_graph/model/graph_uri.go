@@ -0,0 +1,78 @@
+package model_sig_graph
+
+import (
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	"sig_graph_scp/pkg/utility"
+	"strings"
+)
+
+const GraphUriScheme = "sgp"
+
+// name of a graph, sgp://<backend>:[<endpoint>,...]:<channel>, e.g.
+// sgp://hyper:[http://localhost:7051,http://localhost:9051]:public.
+// Node ids are <graph uri>:<id in the graph>
+type GraphUri struct {
+	Backend model.EGraphBackend
+	// peers serving the graph, may be empty
+	Endpoints []string
+	// channel of the ledger, also tells the visibility of the graph
+	Channel string
+}
+
+func ParseGraphUri(uri string) (GraphUri, error) {
+	ret := GraphUri{}
+
+	if !strings.HasPrefix(uri, GraphUriScheme+"://") {
+		return ret, fmt.Errorf("%w: graph uri %s does not start with %s://", utility.ErrInvalidArgument, uri, GraphUriScheme)
+	}
+
+	backend, rest, ok := strings.Cut(strings.TrimPrefix(uri, GraphUriScheme+"://"), ":")
+	if !ok {
+		return ret, fmt.Errorf("%w: graph uri %s has no endpoints", utility.ErrInvalidArgument, uri)
+	}
+	switch backend {
+	case model.EGraphBackendHyperledger, model.EGraphBackendMemory:
+		ret.Backend = backend
+	default:
+		return ret, fmt.Errorf("%w: graph uri %s has unknown backend %s", utility.ErrInvalidArgument, uri, backend)
+	}
+
+	if !strings.HasPrefix(rest, "[") {
+		return ret, fmt.Errorf("%w: graph uri %s has no endpoints", utility.ErrInvalidArgument, uri)
+	}
+	endpoints, rest, ok := strings.Cut(rest[1:], "]")
+	if !ok {
+		return ret, fmt.Errorf("%w: graph uri %s has unterminated endpoints", utility.ErrInvalidArgument, uri)
+	}
+	ret.Endpoints = []string{}
+	if endpoints != "" {
+		for _, endpoint := range strings.Split(endpoints, ",") {
+			if endpoint == "" {
+				return ret, fmt.Errorf("%w: graph uri %s has an empty endpoint", utility.ErrInvalidArgument, uri)
+			}
+			ret.Endpoints = append(ret.Endpoints, endpoint)
+		}
+	}
+
+	channel := strings.TrimPrefix(rest, ":")
+	if channel == rest || channel == "" || strings.ContainsAny(channel, ":[]") {
+		return ret, fmt.Errorf("%w: graph uri %s has no valid channel", utility.ErrInvalidArgument, uri)
+	}
+	ret.Channel = channel
+
+	return ret, nil
+}
+
+func (u GraphUri) String() string {
+	return fmt.Sprintf("%s://%s:[%s]:%s", GraphUriScheme, u.Backend, strings.Join(u.Endpoints, ","), u.Channel)
+}
+
+// graph uri prefixing a node id, the id in the graph has no colon
+func GraphNameOfNodeId(id string) (string, error) {
+	idx := strings.LastIndex(id, ":")
+	if idx <= 0 || idx == len(id)-1 {
+		return "", fmt.Errorf("%w: node id %s has no graph", utility.ErrInvalidArgument, id)
+	}
+	return id[:idx], nil
+}
@@ -13,9 +13,19 @@ type Node struct {
 	CreatedTime              uint64          `json:"created_time" mapstructure:"created_time"`
 	UpdatedTime              uint64          `json:"updated_time" mapstructure:"updated_time"`
 	Signature                string          `json:"signature" mapstructure:"signature"`
+	SignatureScheme          string          `json:"signature_scheme,omitempty" mapstructure:"signature_scheme"`
 	OwnerPublicKey           string          `json:"owner_public_key" mapstructure:"owner_public_key"`
 }
 
+// implemented by Node and by every node type embedding it
+type NodeI interface {
+	BaseNode() Node
+}
+
+func (n Node) BaseNode() Node {
+	return n
+}
+
 func (n *Node) ClearEdges() {
 	n.PublicParentsIds = map[string]bool{}
 	n.PublicChildrenIds = map[string]bool{}
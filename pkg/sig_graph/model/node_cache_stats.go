@@ -0,0 +1,14 @@
+package model_sig_graph
+
+type NodeCacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// ledger queries saved by waiting for the same query of another caller
+	CollapsedLookups uint64 `json:"collapsed_lookups"`
+	// removed to stay within the capacity
+	Evictions uint64 `json:"evictions"`
+	// removed because their ttl expired
+	Expirations uint64 `json:"expirations"`
+	// number of cached nodes
+	Size int `json:"size"`
+}
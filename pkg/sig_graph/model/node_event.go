@@ -0,0 +1,11 @@
+package model_sig_graph
+
+import "sig_graph_scp/pkg/model"
+
+type NodeEvent struct {
+	EventType model.ENodeEventType `json:"event_type"`
+	NodeId    string               `json:"node_id"`
+	// ledger transaction that changed the node
+	TransactionId string `json:"transaction_id"`
+	BlockNumber   uint64 `json:"block_number"`
+}
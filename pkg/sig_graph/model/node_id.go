@@ -0,0 +1,132 @@
+package model_sig_graph
+
+import (
+	"fmt"
+	"sig_graph_scp/pkg/model"
+	"sig_graph_scp/pkg/utility"
+)
+
+// node id split into its graph and its id in the graph
+type NodeId struct {
+	GraphName string
+	LocalId   string
+	Format    model.ENodeIdFormat
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// parse <graph uri>:<id in the graph>. Ids in the graph are only accepted in
+// their canonical form, lowercase uuids and uppercase ulids, so that a node
+// has a single id
+func ParseNodeId(id string) (NodeId, error) {
+	graphName, err := GraphNameOfNodeId(id)
+	if err != nil {
+		return NodeId{}, err
+	}
+	localId := id[len(graphName)+1:]
+
+	format, reason := nodeIdFormatOf(localId)
+	if reason != "" {
+		return NodeId{}, fmt.Errorf("%w: node id %s: %s", utility.ErrInvalidArgument, id, reason)
+	}
+
+	return NodeId{
+		GraphName: graphName,
+		LocalId:   localId,
+		Format:    format,
+	}, nil
+}
+
+func (i NodeId) String() string {
+	return fmt.Sprintf("%s:%s", i.GraphName, i.LocalId)
+}
+
+// format of an id in a graph, ErrInvalidArgument if it has none
+func NodeIdFormatOf(localId string) (model.ENodeIdFormat, error) {
+	format, reason := nodeIdFormatOf(localId)
+	if reason != "" {
+		return "", fmt.Errorf("%w: %s", utility.ErrInvalidArgument, reason)
+	}
+	return format, nil
+}
+
+// the reason why the id has no format if it is not empty
+func nodeIdFormatOf(localId string) (model.ENodeIdFormat, string) {
+	switch len(localId) {
+	case 36:
+		return uuidFormatOf(localId)
+	case 26:
+		return ulidFormatOf(localId)
+	}
+	return "", fmt.Sprintf("%s is neither a uuid nor an ulid", localId)
+}
+
+func uuidFormatOf(localId string) (model.ENodeIdFormat, string) {
+	for i := 0; i < len(localId); i++ {
+		c := localId[i]
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return "", fmt.Sprintf("%s is not a canonical uuid", localId)
+			}
+		case ('0' <= c && c <= '9') || ('a' <= c && c <= 'f'):
+		default:
+			return "", fmt.Sprintf("%s is not a canonical uuid", localId)
+		}
+	}
+
+	// rfc 4122 variant
+	switch localId[19] {
+	case '8', '9', 'a', 'b':
+	default:
+		return "", fmt.Sprintf("%s has an unknown uuid variant", localId)
+	}
+
+	switch localId[14] {
+	case '4':
+		return model.ENodeIdFormatUuidV4, ""
+	case '7':
+		return model.ENodeIdFormatUuidV7, ""
+	}
+	return "", fmt.Sprintf("%s has an unsupported uuid version", localId)
+}
+
+func ulidFormatOf(localId string) (model.ENodeIdFormat, string) {
+	// 26 characters hold 130 bits, the timestamp leaves the top 2 unset
+	if localId[0] > '7' {
+		return "", fmt.Sprintf("%s overflows an ulid", localId)
+	}
+	for i := 0; i < len(localId); i++ {
+		isDigit := false
+		for j := 0; j < len(crockfordBase32); j++ {
+			if localId[i] == crockfordBase32[j] {
+				isDigit = true
+				break
+			}
+		}
+		if !isDigit {
+			return "", fmt.Sprintf("%s is not a canonical ulid", localId)
+		}
+	}
+	return model.ENodeIdFormatUlid, ""
+}
+
+// encode 16 bytes as an ulid
+func EncodeUlid(id [16]byte) string {
+	// 128 bits padded to 130 bits on the left, 5 bits per character
+	encoded := make([]byte, 26)
+	bitOffset := -2
+	for i := range encoded {
+		value := 0
+		for bit := 0; bit < 5; bit++ {
+			value <<= 1
+			position := bitOffset + bit
+			if position >= 0 && id[position/8]&(0x80>>(position%8)) != 0 {
+				value |= 1
+			}
+		}
+		encoded[i] = crockfordBase32[value]
+		bitOffset += 5
+	}
+	return string(encoded)
+}
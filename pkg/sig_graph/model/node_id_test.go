@@ -0,0 +1,87 @@
+package model_sig_graph
+
+import (
+	"errors"
+	"sig_graph_scp/pkg/model"
+	"sig_graph_scp/pkg/utility"
+	"testing"
+)
+
+const nodeIdTestGraphName = "sgp://memory:[]:public"
+
+func TestEncodeUlid(t *testing.T) {
+	cases := []struct {
+		id       [16]byte
+		expected string
+	}{
+		{[16]byte{}, "00000000000000000000000000"},
+		{[16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, "01081G81860W40J2GB1G6GW3RG"},
+		{
+			[16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			"7ZZZZZZZZZZZZZZZZZZZZZZZZZ",
+		},
+	}
+
+	for _, c := range cases {
+		encoded := EncodeUlid(c.id)
+		if encoded != c.expected {
+			t.Fatalf("expected %s for %x, got %s", c.expected, c.id, encoded)
+		}
+		format, err := NodeIdFormatOf(encoded)
+		if err != nil || format != model.ENodeIdFormatUlid {
+			t.Fatalf("expected %s to be an ulid, got %s, %v", encoded, format, err)
+		}
+	}
+}
+
+func TestParseNodeId(t *testing.T) {
+	cases := []struct {
+		localId string
+		format  model.ENodeIdFormat
+	}{
+		{"0f8fad5b-d9cb-469f-a165-70867728950e", model.ENodeIdFormatUuidV4},
+		{"018bcfe5-6800-7abc-8def-0123456789ab", model.ENodeIdFormatUuidV7},
+		{"01HF7YAT00ABCDEFGHJKMNPQRS", model.ENodeIdFormatUlid},
+	}
+
+	for _, c := range cases {
+		nodeId, err := ParseNodeId(nodeIdTestGraphName + ":" + c.localId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := NodeId{GraphName: nodeIdTestGraphName, LocalId: c.localId, Format: c.format}
+		if nodeId != expected {
+			t.Fatalf("expected %+v, got %+v", expected, nodeId)
+		}
+		if nodeId.String() != nodeIdTestGraphName+":"+c.localId {
+			t.Fatalf("expected the id back, got %s", nodeId.String())
+		}
+	}
+}
+
+func TestParseNodeIdRejectsNonCanonical(t *testing.T) {
+	cases := []struct {
+		name    string
+		localId string
+	}{
+		{"uppercase uuid", "0F8FAD5B-D9CB-469F-A165-70867728950E"},
+		{"mixed case uuid", "0f8fad5b-d9cb-469f-A165-70867728950e"},
+		{"uuid without dashes", "0f8fad5bd9cb469fa16570867728950e"},
+		{"braced uuid", "{0f8fad5b-d9cb-469f-a165-70867728950}"},
+		{"uuid of another variant", "0f8fad5b-d9cb-469f-c165-70867728950e"},
+		{"uuid v1", "0f8fad5b-d9cb-169f-a165-70867728950e"},
+		{"lowercase ulid", "01hf7yat00abcdefghjkmnpqrs"},
+		{"ulid with excluded letter", "01HF7YAT00ABCDEFGHIKMNPQRS"},
+		{"overflowing ulid", "81HF7YAT00ABCDEFGHJKMNPQRS"},
+		{"other length", "node"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseNodeId(nodeIdTestGraphName + ":" + c.localId)
+			if !errors.Is(err, utility.ErrInvalidArgument) {
+				t.Fatalf("expected ErrInvalidArgument, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package model_sig_graph
+
+import "encoding/json"
+
+const ProofBundleVersionV1 = "sgp-proof-bundle-v1"
+
+// secret of a node hiding it behind a private edge. The edge is opened by
+// the hash of the node id and the secret
+type ProofBundleSecret struct {
+	NodeId string `json:"node_id"`
+	Secret string `json:"secret"`
+}
+
+// self-contained proof of the provenance of a node, verifiable offline
+type ProofBundle struct {
+	Version   string `json:"version"`
+	GraphName string `json:"graph_name"`
+	RootId    string `json:"root_id"`
+	// ledger json of the nodes, as signed by their owners
+	Nodes []json.RawMessage `json:"nodes"`
+	// secrets opening the private edges between the nodes
+	Secrets []ProofBundleSecret `json:"secrets"`
+	// pem encoded public keys of the owners of the nodes
+	PublicKeys []string `json:"public_keys"`
+}
+
+type ProofBundleEdge struct {
+	ParentId  string `json:"parent_id"`
+	ChildId   string `json:"child_id"`
+	IsPrivate bool   `json:"is_private"`
+}
+
+// result of a successful verification
+type ProofBundleReport struct {
+	RootId string `json:"root_id"`
+	// sorted
+	NodeIds []string `json:"node_ids"`
+	// edges between the nodes, confirmed on both sides when public
+	Edges []ProofBundleEdge `json:"edges"`
+}
@@ -0,0 +1,34 @@
+package utility
+
+import (
+	"sync"
+	"time"
+)
+
+// clock moved only by Set and Advance
+type ClockFake struct {
+	mtx sync.Mutex
+	now time.Time
+}
+
+func NewClockFake(now time.Time) *ClockFake {
+	return &ClockFake{now: now}
+}
+
+func (c *ClockFake) Now() time.Time {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.now
+}
+
+func (c *ClockFake) Set(now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.now = now
+}
+
+func (c *ClockFake) Advance(duration time.Duration) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.now = c.now.Add(duration)
+}
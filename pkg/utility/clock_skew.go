@@ -0,0 +1,26 @@
+package utility
+
+import (
+	"fmt"
+	"time"
+)
+
+// how far a timestamp set by a peer may be from our clock
+type ClockSkewLimits struct {
+	// how old the timestamp may be
+	MaxPast time.Duration
+	// how far ahead of our clock the timestamp may be
+	MaxFuture time.Duration
+}
+
+// return ErrClockSkew if t is outside the limits around the time of clock
+func CheckClockSkew(clock ClockI, limits *ClockSkewLimits, t time.Time) error {
+	now := clock.Now()
+	if age := now.Sub(t); age > limits.MaxPast {
+		return fmt.Errorf("%w: time %d ms is %s in the past, at most %s allowed", ErrClockSkew, t.UnixMilli(), age, limits.MaxPast)
+	}
+	if ahead := t.Sub(now); ahead > limits.MaxFuture {
+		return fmt.Errorf("%w: time %d ms is %s in the future, at most %s allowed", ErrClockSkew, t.UnixMilli(), ahead, limits.MaxFuture)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package utility
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	clock := NewClockFake(now)
+	limits := &ClockSkewLimits{MaxPast: 5 * time.Minute, MaxFuture: time.Minute}
+
+	cases := []struct {
+		name    string
+		time    time.Time
+		isValid bool
+	}{
+		{"now", now, true},
+		{"oldest", now.Add(-5 * time.Minute), true},
+		{"too old", now.Add(-5*time.Minute - time.Millisecond), false},
+		{"furthest ahead", now.Add(time.Minute), true},
+		{"too far ahead", now.Add(time.Minute + time.Millisecond), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := CheckClockSkew(clock, limits, c.time)
+			if c.isValid {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrClockSkew) || !errors.Is(err, ErrInvalidArgument) {
+				t.Fatalf("expected ErrClockSkew, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckClockSkewFollowsClock(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	clock := NewClockFake(now)
+	limits := &ClockSkewLimits{MaxPast: 5 * time.Minute, MaxFuture: time.Minute}
+
+	err := CheckClockSkew(clock, limits, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(5*time.Minute + time.Millisecond)
+	err = CheckClockSkew(clock, limits, now)
+	if !errors.Is(err, ErrClockSkew) {
+		t.Fatalf("expected ErrClockSkew once the clock moved on, got %v", err)
+	}
+
+	clock.Set(now.Add(-time.Minute - time.Millisecond))
+	err = CheckClockSkew(clock, limits, now)
+	if !errors.Is(err, ErrClockSkew) {
+		t.Fatalf("expected ErrClockSkew with a clock behind, got %v", err)
+	}
+}
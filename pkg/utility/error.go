@@ -1,6 +1,9 @@
 package utility
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var ErrNotFound = errors.New("not found")
 var ErrInvalidArgument = errors.New("invalid argument")
@@ -9,3 +12,13 @@ var ErrAlreadyExists = errors.New("already exists")
 var ErrInvalidState = errors.New("invalid state")
 var ErrSmartContractError = errors.New("smart contract error")
 var ErrDatabase = errors.New("database error")
+var ErrInvalidSignature = errors.New("invalid signature")
+var ErrTransactionFailed = errors.New("ledger transaction failed")
+var ErrPermissionDenied = errors.New("permission denied")
+var ErrTransient = errors.New("transient error")
+
+// also matches ErrInvalidState
+var ErrAlreadyFinalized = fmt.Errorf("%w: already finalized", ErrInvalidState)
+
+// a timestamp too far from our clock, also matches ErrInvalidArgument
+var ErrClockSkew = fmt.Errorf("%w: clock skew", ErrInvalidArgument)
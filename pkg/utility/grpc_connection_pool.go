@@ -4,12 +4,16 @@ import (
 	"context"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 type grpcConnectionPool struct {
+	// by url, and public key with TLS
 	connections map[string][]*grpc.ClientConn
 	mtx         MutexI
+	// plaintext if nil
+	tlsOptions *GrpcTlsOptions
 }
 
 func NewGrpcConnectionPool() *grpcConnectionPool {
@@ -19,30 +23,49 @@ func NewGrpcConnectionPool() *grpcConnectionPool {
 	}
 }
 
+// connections authenticated with mutual TLS
+func NewGrpcConnectionPoolTls(tlsOptions *GrpcTlsOptions) *grpcConnectionPool {
+	pool := NewGrpcConnectionPool()
+	pool.tlsOptions = tlsOptions
+	return pool
+}
+
 func (p *grpcConnectionPool) NewConnection(
 	ctx context.Context,
 	url string,
+	publicKey string,
+	privateKey string,
 ) (*grpc.ClientConn, error) {
 	if !p.mtx.Lock(ctx) {
 		return nil, ErrTimedOut
 	}
 
 	defer p.mtx.Unlock(ctx)
-	if availableConnections, ok := p.connections[url]; ok {
+	key := p.key(url, publicKey)
+	if availableConnections, ok := p.connections[key]; ok {
 		if len(availableConnections) > 0 {
 			connection := availableConnections[len(availableConnections)-1]
-			availableConnections = availableConnections[0 : len(availableConnections)-1]
+			p.connections[key] = availableConnections[0 : len(availableConnections)-1]
 			return connection, nil
 		}
 	} else {
-		p.connections[url] = []*grpc.ClientConn{}
+		p.connections[key] = []*grpc.ClientConn{}
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if p.tlsOptions != nil {
+		tlsConfig, err := p.tlsOptions.ClientConfig(publicKey, privateKey)
+		if err != nil {
+			return nil, err
+		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
 	}
 
 	clientConn, err := grpc.DialContext(
 		ctx,
 		url,
 		grpc.WithBlock(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials),
 	)
 	if err != nil {
 		return nil, err
@@ -51,17 +74,26 @@ func (p *grpcConnectionPool) NewConnection(
 	return clientConn, nil
 }
 
-func (p *grpcConnectionPool) ReturnConnection(ctx context.Context, url string, conn *grpc.ClientConn) error {
+func (p *grpcConnectionPool) ReturnConnection(ctx context.Context, url string, publicKey string, conn *grpc.ClientConn) error {
 	if !p.mtx.Lock(ctx) {
 		return ErrTimedOut
 	}
 
 	defer p.mtx.Unlock(ctx)
 
-	if _, ok := p.connections[url]; !ok {
-		p.connections[url] = []*grpc.ClientConn{}
+	key := p.key(url, publicKey)
+	if _, ok := p.connections[key]; !ok {
+		p.connections[key] = []*grpc.ClientConn{}
 	}
 
-	p.connections[url] = append(p.connections[url], conn)
+	p.connections[key] = append(p.connections[key], conn)
 	return nil
 }
+
+// connections of different users present different certificates
+func (p *grpcConnectionPool) key(url string, publicKey string) string {
+	if p.tlsOptions == nil {
+		return url
+	}
+	return url + "\n" + publicKey
+}
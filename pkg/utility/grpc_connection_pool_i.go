@@ -7,6 +7,9 @@ import (
 )
 
 type GrpcConnectionPoolI interface {
-	NewConnection(ctx context.Context, url string) (*grpc.ClientConn, error)
-	ReturnConnection(ctx context.Context, url string, conn *grpc.ClientConn) error
+	// connection of the user of the pem key pair. The public key selects the
+	// TLS client certificate with mutual TLS, the private key is only used to
+	// issue one if there is none
+	NewConnection(ctx context.Context, url string, publicKey string, privateKey string) (*grpc.ClientConn, error)
+	ReturnConnection(ctx context.Context, url string, publicKey string, conn *grpc.ClientConn) error
 }
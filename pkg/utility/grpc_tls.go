@@ -0,0 +1,216 @@
+package utility
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// mutual TLS between participants. A participant authenticates as the user
+// sending a call with a certificate of the key pair of the user
+type GrpcTlsOptions struct {
+	// CAs of the certificates of the other participants
+	CaCertificates *x509.CertPool
+	// certificate of our server, it must name the host that peers dial
+	ServerCertificate tls.Certificate
+	// certificates of our users, the one of the public key of the user sending
+	// a call is presented
+	ClientCertificates []tls.Certificate
+	// issues a certificate for users without one in ClientCertificates, e.g.
+	// users created at runtime. Their calls fail if nil
+	ClientCertificateIssuer *GrpcClientCertificateIssuer
+}
+
+// CA of the client certificates of our users, the other participants must
+// trust it in their CaCertificates
+type GrpcClientCertificateIssuer struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer
+	// of the issued certificates, default to a day. A certificate is issued
+	// again for the handshakes past half of its validity, e.g. when a pooled
+	// connection reconnects
+	Validity time.Duration
+	// default to the wall clock
+	Clock ClockI
+}
+
+func (i *GrpcClientCertificateIssuer) validity() time.Duration {
+	if i.Validity <= 0 {
+		return 24 * time.Hour
+	}
+	return i.Validity
+}
+
+func (i *GrpcClientCertificateIssuer) now() time.Time {
+	if i.Clock == nil {
+		return time.Now()
+	}
+	return i.Clock.Now()
+}
+
+// certificate of the key pair of a user, signed by the issuer
+func (i *GrpcClientCertificateIssuer) Issue(pemPublicKey string, pemPrivateKey string) (tls.Certificate, error) {
+	_, err := ValidatePemKeyPair(pemPublicKey, pemPrivateKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	privateKey, _, err := ParsePemPrivateKey(pemPrivateKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := i.now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "sgp user"},
+		// tolerate clocks of the peers behind ours
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(i.validity()),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, i.Certificate, privateKey.Public(), i.Key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not issue TLS client certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der, i.Certificate.Raw},
+		PrivateKey:  privateKey,
+		Leaf:        leaf,
+	}, nil
+}
+
+// certificate presented by each handshake of a client, issued on the first
+// handshake and again once half of its validity is over
+type grpcIssuedClientCertificate struct {
+	issuer        *GrpcClientCertificateIssuer
+	pemPublicKey  string
+	pemPrivateKey string
+
+	mtx         sync.Mutex
+	certificate *tls.Certificate
+}
+
+func (c *grpcIssuedClientCertificate) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.certificate != nil {
+		renewAt := c.certificate.Leaf.NotAfter.Add(-c.issuer.validity() / 2)
+		if c.issuer.now().Before(renewAt) {
+			return c.certificate, nil
+		}
+	}
+
+	certificate, err := c.issuer.Issue(c.pemPublicKey, c.pemPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	c.certificate = &certificate
+	return c.certificate, nil
+}
+
+func (o *GrpcTlsOptions) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{o.ServerCertificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    o.CaCertificates,
+	}
+}
+
+// config of a client calling as the user of pemPublicKey, ErrPermissionDenied
+// if there is no certificate of this key. A certificate is issued with
+// pemPrivateKey by the handshakes if ClientCertificateIssuer is set
+func (o *GrpcTlsOptions) ClientConfig(pemPublicKey string, pemPrivateKey string) (*tls.Config, error) {
+	publicKey, err := PkixOfPemPublicKey(pemPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range o.ClientCertificates {
+		certificate := o.ClientCertificates[i]
+		if len(certificate.Certificate) == 0 {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidArgument, err.Error())
+		}
+		leafPublicKey, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidArgument, err.Error())
+		}
+		if string(leafPublicKey) == string(publicKey) {
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{certificate},
+				RootCAs:      o.CaCertificates,
+			}, nil
+		}
+	}
+
+	if o.ClientCertificateIssuer == nil || pemPrivateKey == "" {
+		return nil, fmt.Errorf("%w: no TLS client certificate of the key of the user", ErrPermissionDenied)
+	}
+	_, err = ValidatePemKeyPair(pemPublicKey, pemPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	issuedCertificate := &grpcIssuedClientCertificate{
+		issuer:        o.ClientCertificateIssuer,
+		pemPublicKey:  pemPublicKey,
+		pemPrivateKey: pemPrivateKey,
+	}
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		GetClientCertificate: issuedCertificate.get,
+		RootCAs:              o.CaCertificates,
+	}, nil
+}
+
+// return ErrPermissionDenied unless the call of ctx is authenticated by a TLS
+// client certificate of pemPublicKey
+func VerifyGrpcPeerPublicKey(ctx context.Context, pemPublicKey string) error {
+	grpcPeer, ok := peer.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: unknown peer", ErrPermissionDenied)
+	}
+	tlsInfo, ok := grpcPeer.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return fmt.Errorf("%w: peer has no TLS client certificate", ErrPermissionDenied)
+	}
+
+	publicKey, err := PkixOfPemPublicKey(pemPublicKey)
+	if err != nil {
+		return err
+	}
+	certificatePublicKey, err := x509.MarshalPKIXPublicKey(tlsInfo.State.PeerCertificates[0].PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, err.Error())
+	}
+	if string(certificatePublicKey) != string(publicKey) {
+		return fmt.Errorf("%w: TLS client certificate is not of the claimed public key", ErrPermissionDenied)
+	}
+	return nil
+}
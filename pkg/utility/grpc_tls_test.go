@@ -0,0 +1,259 @@
+package utility
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var grpcTlsTestNow = time.UnixMilli(1700000000000)
+
+// pem public and private key
+func newGrpcTlsTestKeyPair(t *testing.T) (string, string) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDer}))
+}
+
+// CA issuing the client certificates and the certificate of a server on
+// 127.0.0.1
+func newGrpcTlsTestOptions(t *testing.T, clock ClockI) *GrpcTlsOptions {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sgp test ca"},
+		NotBefore:             grpcTlsTestNow.Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCertificate, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "sgp test server"},
+		NotBefore:    grpcTlsTestNow.Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	serverDer, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCertificate, &serverKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCertificates := x509.NewCertPool()
+	caCertificates.AddCert(caCertificate)
+	return &GrpcTlsOptions{
+		CaCertificates: caCertificates,
+		ServerCertificate: tls.Certificate{
+			Certificate: [][]byte{serverDer, caDer},
+			PrivateKey:  serverKey,
+		},
+		ClientCertificateIssuer: &GrpcClientCertificateIssuer{
+			Certificate: caCertificate,
+			Key:         caKey,
+			Validity:    time.Hour,
+			Clock:       clock,
+		},
+	}
+}
+
+// address of a gRPC server returning the error of VerifyGrpcPeerPublicKey
+// for pemPublicKey. Client certificates are optional so that their absence
+// reaches the check
+func startGrpcTlsTestServer(t *testing.T, options *GrpcTlsOptions, pemPublicKey string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := options.ServerConfig()
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(config)),
+		grpc.UnaryInterceptor(func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			err := VerifyGrpcPeerPublicKey(ctx, pemPublicKey)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, request)
+		}),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func callGrpcTlsTestServer(t *testing.T, address string, config *tls.Config) error {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, address, grpc.WithBlock(), grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestVerifyGrpcPeerPublicKey(t *testing.T) {
+	options := newGrpcTlsTestOptions(t, NewClockWall())
+	userPublicKey, userPrivateKey := newGrpcTlsTestKeyPair(t)
+	otherPublicKey, otherPrivateKey := newGrpcTlsTestKeyPair(t)
+	address := startGrpcTlsTestServer(t, options, userPublicKey)
+
+	t.Run("certificate of the key", func(t *testing.T) {
+		config, err := options.ClientConfig(userPublicKey, userPrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = callGrpcTlsTestServer(t, address, config)
+		if err != nil {
+			t.Fatalf("expected the call to be authenticated, got %v", err)
+		}
+	})
+
+	t.Run("certificate of another key", func(t *testing.T) {
+		config, err := options.ClientConfig(otherPublicKey, otherPrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = callGrpcTlsTestServer(t, address, config)
+		if err == nil || !strings.Contains(err.Error(), ErrPermissionDenied.Error()) {
+			t.Fatalf("expected the call to be denied, got %v", err)
+		}
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		err := callGrpcTlsTestServer(t, address, &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    options.CaCertificates,
+		})
+		if err == nil || !strings.Contains(err.Error(), ErrPermissionDenied.Error()) {
+			t.Fatalf("expected the call to be denied, got %v", err)
+		}
+	})
+}
+
+func TestVerifyGrpcPeerPublicKeyWithoutTls(t *testing.T) {
+	publicKey, _ := newGrpcTlsTestKeyPair(t)
+
+	err := VerifyGrpcPeerPublicKey(context.Background(), publicKey)
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("expected ErrPermissionDenied, got %v", err)
+	}
+}
+
+func TestClientConfigWithoutCertificate(t *testing.T) {
+	options := newGrpcTlsTestOptions(t, NewClockWall())
+	options.ClientCertificateIssuer = nil
+	publicKey, privateKey := newGrpcTlsTestKeyPair(t)
+
+	_, err := options.ClientConfig(publicKey, privateKey)
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("expected ErrPermissionDenied, got %v", err)
+	}
+}
+
+func TestClientConfigRejectsKeyOfAnotherUser(t *testing.T) {
+	options := newGrpcTlsTestOptions(t, NewClockWall())
+	publicKey, _ := newGrpcTlsTestKeyPair(t)
+	_, otherPrivateKey := newGrpcTlsTestKeyPair(t)
+
+	_, err := options.ClientConfig(publicKey, otherPrivateKey)
+	if err == nil {
+		t.Fatal("expected the key pair to be rejected")
+	}
+}
+
+func TestClientConfigIssuesCertificateAgain(t *testing.T) {
+	clock := NewClockFake(grpcTlsTestNow)
+	options := newGrpcTlsTestOptions(t, clock)
+	publicKey, privateKey := newGrpcTlsTestKeyPair(t)
+
+	config, err := options.ClientConfig(publicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := config.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Leaf.NotAfter.Equal(grpcTlsTestNow.Add(time.Hour).Truncate(time.Second)) {
+		t.Fatalf("expected the certificate to expire an hour after the clock, got %s", first.Leaf.NotAfter)
+	}
+
+	clock.Advance(29 * time.Minute)
+	second, err := config.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Fatal("expected the certificate to be reused before half of its validity")
+	}
+
+	clock.Advance(2 * time.Minute)
+	third, err := config.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first || !third.Leaf.NotAfter.After(first.Leaf.NotAfter) {
+		t.Fatal("expected a new certificate past half of the validity")
+	}
+}
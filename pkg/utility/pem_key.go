@@ -0,0 +1,102 @@
+package utility
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+type EKeyAlgorithm = string
+
+const (
+	EKeyAlgorithmRsa     EKeyAlgorithm = "rsa"
+	EKeyAlgorithmEcdsa   EKeyAlgorithm = "ecdsa"
+	EKeyAlgorithmEd25519 EKeyAlgorithm = "ed25519"
+)
+
+// parse a PKCS #8 "PRIVATE KEY" pem block holding an RSA, ECDSA or Ed25519 key
+func ParsePemPrivateKey(pemKey string) (crypto.Signer, EKeyAlgorithm, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, "", fmt.Errorf("%w: private key must be a PKCS #8 pem block", ErrInvalidArgument)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidArgument, err.Error())
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, EKeyAlgorithmRsa, nil
+	case *ecdsa.PrivateKey:
+		return key, EKeyAlgorithmEcdsa, nil
+	case ed25519.PrivateKey:
+		return key, EKeyAlgorithmEd25519, nil
+	default:
+		return nil, "", fmt.Errorf("%w: unsupported private key type %T", ErrInvalidArgument, key)
+	}
+}
+
+// parse a PKIX "PUBLIC KEY" pem block holding an RSA, ECDSA or Ed25519 key
+func ParsePemPublicKey(pemKey string) (crypto.PublicKey, EKeyAlgorithm, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, "", fmt.Errorf("%w: public key must be a PKIX pem block", ErrInvalidArgument)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidArgument, err.Error())
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return key, EKeyAlgorithmRsa, nil
+	case *ecdsa.PublicKey:
+		return key, EKeyAlgorithmEcdsa, nil
+	case ed25519.PublicKey:
+		return key, EKeyAlgorithmEd25519, nil
+	default:
+		return nil, "", fmt.Errorf("%w: unsupported public key type %T", ErrInvalidArgument, key)
+	}
+}
+
+// der of the key, the same for every pem encoding of the key
+func PkixOfPemPublicKey(pemPublicKey string) ([]byte, error) {
+	publicKey, _, err := ParsePemPublicKey(pemPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArgument, err.Error())
+	}
+	return der, nil
+}
+
+// return ErrInvalidArgument if the keys can not be parsed or do not belong together
+func ValidatePemKeyPair(publicKey string, privateKey string) (EKeyAlgorithm, error) {
+	parsedPrivateKey, algorithm, err := ParsePemPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	parsedPublicKey, _, err := ParsePemPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	type equaler interface {
+		Equal(x crypto.PublicKey) bool
+	}
+	if !parsedPrivateKey.Public().(equaler).Equal(parsedPublicKey) {
+		return "", fmt.Errorf("%w: public key does not match private key", ErrInvalidArgument)
+	}
+
+	return algorithm, nil
+}
@@ -1,4 +1,4 @@
-package service_asset_transfer
+package utility
 
 import (
 	"context"
@@ -16,14 +16,14 @@ func NewSecretIdGeneratorCrypto(length uint32) *secretIdGeneratorCrypto {
 	}
 }
 
-var letters = []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
-var max = big.NewInt(int64(len(letters)))
+var secretIdLetters = []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+var numberOfSecretIdLetters = big.NewInt(int64(len(secretIdLetters)))
 
 func (s *secretIdGeneratorCrypto) NewSecretId(ctx context.Context) (string, error) {
 	ret := make([]byte, s.length)
 	for i := 0; i < int(s.length); i++ {
-		randomIdx, _ := rand.Int(rand.Reader, max)
-		ret[i] = letters[randomIdx.Int64()]
+		randomIdx, _ := rand.Int(rand.Reader, numberOfSecretIdLetters)
+		ret[i] = secretIdLetters[randomIdx.Int64()]
 	}
 
 	return string(ret), nil
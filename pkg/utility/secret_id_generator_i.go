@@ -1,4 +1,4 @@
-package service_asset_transfer
+package utility
 
 import "context"
 
@@ -0,0 +1,47 @@
+package utility
+
+import "sync"
+
+type singleFlightCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+type singleFlight struct {
+	mtx   sync.Mutex
+	calls map[string]*singleFlightCall
+}
+
+func NewSingleFlight() *singleFlight {
+	return &singleFlight{
+		calls: map[string]*singleFlightCall{},
+	}
+}
+
+func (s *singleFlight) Do(key string, fn func() (any, error)) (any, bool, error) {
+	s.mtx.Lock()
+	if call, ok := s.calls[key]; ok {
+		s.mtx.Unlock()
+
+		<-call.done
+		return call.value, true, call.err
+	}
+
+	call := &singleFlightCall{
+		done: make(chan struct{}),
+	}
+	s.calls[key] = call
+	s.mtx.Unlock()
+
+	// later callers start a new call
+	defer func() {
+		s.mtx.Lock()
+		delete(s.calls, key)
+		s.mtx.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn()
+	return call.value, false, call.err
+}
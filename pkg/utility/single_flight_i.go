@@ -0,0 +1,8 @@
+package utility
+
+// collapse concurrent calls with the same key into one
+type SingleFlightI interface {
+	// run fn unless a call with the same key is running, in which case wait
+	// for it and return its result with waited set
+	Do(key string, fn func() (any, error)) (value any, waited bool, err error)
+}